@@ -0,0 +1,23 @@
+package opentelemetryexportermonitoring
+
+// toSet convierte una lista de claves en un conjunto; nil si está vacía
+func toSet(keys []string) map[string]bool {
+	if len(keys) == 0 {
+		return nil
+	}
+	set := make(map[string]bool, len(keys))
+	for _, k := range keys {
+		set[k] = true
+	}
+	return set
+}
+
+// attrAllowed indica si la clave (original, sin sanear) se serializa según
+// attribute_allowlist y attribute_denylist. Si hay allowlist, la denylist
+// se ignora
+func (m *monitoringExporter) attrAllowed(key string) bool {
+	if m.attrAllowlist != nil {
+		return m.attrAllowlist[key]
+	}
+	return !m.attrDenylist[key]
+}
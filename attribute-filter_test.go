@@ -0,0 +1,64 @@
+package opentelemetryexportermonitoring
+
+import (
+	"sort"
+	"strings"
+	"testing"
+)
+
+func TestAttributeFilterPrecedence(t *testing.T) {
+	tests := []struct {
+		name      string
+		allowlist []string
+		denylist  []string
+		want      string
+	}{
+		{"sin listas", nil, nil, "resource_env,service_name,user_email,user_id"},
+		{"listas vacías", []string{}, []string{}, "resource_env,service_name,user_email,user_id"},
+		{"denylist", nil, []string{"user.email", "resource.env"}, "service_name,user_id"},
+		{"allowlist", []string{"user.id"}, nil, "user_id"},
+		{"gana la allowlist", []string{"user.id", "user.email"}, []string{"user.email"}, "user_email,user_id"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ld := logsWithResources(1, "svc")
+			ld.ResourceLogs().At(0).Resource().Attributes().PutStr("resource.env", "prod")
+			attrs := ld.ResourceLogs().At(0).ScopeLogs().At(0).LogRecords().At(0).Attributes()
+			attrs.PutStr("user.id", "u1")
+			attrs.PutStr("user.email", "a@b.c")
+
+			cfg := testConfig(t, "http://localhost")
+			cfg.AttributeAllowlist = tt.allowlist
+			cfg.AttributeDenylist = tt.denylist
+			logs := transformLogRecords(t, newTestExporter(t, cfg), ld)
+
+			var keys []string
+			for k := range logs[0]["properties"].(map[string]interface{}) {
+				keys = append(keys, k)
+			}
+			sort.Strings(keys)
+			if got := strings.Join(keys, ","); got != tt.want {
+				t.Errorf("properties = %s, se esperaba %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestHoistLogSourceFilteredAndRedacted(t *testing.T) {
+	ld := logsWithResources(1, "svc")
+	attrs := ld.ResourceLogs().At(0).ScopeLogs().At(0).LogRecords().At(0).Attributes()
+	attrs.PutStr("code.file.path", "/home/alice/main.go")
+	attrs.PutInt("code.line.number", 42)
+	attrs.PutStr("code.function.name", "main.run")
+
+	cfg := testConfig(t, "http://localhost")
+	cfg.HoistLogSource = true
+	cfg.AttributeDenylist = []string{"code.function.name"}
+	cfg.RedactAttributes = []string{"code.file.path"}
+	logs := transformLogRecords(t, newTestExporter(t, cfg), ld)
+
+	source, _ := logs[0]["source"].(map[string]interface{})
+	if len(source) != 2 || source["file"] != "REDACTED" || source["line"] != float64(42) {
+		t.Errorf("source = %v, se esperaba file redactado, line y sin function", source)
+	}
+}
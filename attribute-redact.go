@@ -0,0 +1,35 @@
+package opentelemetryexportermonitoring
+
+// redactValue sustituye por redaction_placeholder el valor de las claves de
+// redact_attributes. Se recorren los mapas y listas anidados, y una clave
+// anidada coincide por su nombre o por su ruta con puntos ("user.email"
+// dentro de {"user": {"email": ...}}). Los mapas se copian, nunca se
+// modifican los del pdata
+func (m *monitoringExporter) redactValue(path, key string, v interface{}) interface{} {
+	if m.redactKeys[key] || m.redactKeys[path] {
+		return m.redactionPlaceholder
+	}
+	switch t := v.(type) {
+	case map[string]interface{}:
+		out := make(map[string]interface{}, len(t))
+		for k, child := range t {
+			out[k] = m.redactValue(path+"."+k, k, child)
+		}
+		return out
+	case []interface{}:
+		out := make([]interface{}, len(t))
+		for i, child := range t {
+			out[i] = m.redactValue(path, "", child)
+		}
+		return out
+	}
+	return v
+}
+
+// redactString es redactValue para los formatos que solo admiten texto
+func (m *monitoringExporter) redactString(key, v string) string {
+	if m.redactKeys[key] {
+		return m.redactionPlaceholder
+	}
+	return v
+}
@@ -0,0 +1,64 @@
+package opentelemetryexportermonitoring
+
+import "testing"
+
+func TestRedactNestedAndMissingKeys(t *testing.T) {
+	ld := logsWithResources(1, "svc")
+	attrs := ld.ResourceLogs().At(0).ScopeLogs().At(0).LogRecords().At(0).Attributes()
+	user := attrs.PutEmptyMap("user")
+	user.PutStr("email", "a@b.c")
+	user.PutStr("name", "alice")
+	session := user.PutEmptyMap("session")
+	session.PutStr("token", "t0k3n")
+	session.PutInt("age", 3)
+	tags := attrs.PutEmptySlice("tags")
+	tags.AppendEmpty().SetEmptyMap().PutStr("token", "t1")
+	attrs.PutStr("plain", "ok")
+
+	cfg := testConfig(t, "http://localhost")
+	// Por ruta con puntos, por nombre en cualquier nivel y una clave que no existe
+	cfg.RedactAttributes = []string{"user.email", "token", "user.phone"}
+	cfg.RedactionPlaceholder = "***"
+	logs := transformLogRecords(t, newTestExporter(t, cfg), ld)
+
+	props := logs[0]["properties"].(map[string]interface{})
+	gotUser := props["user"].(map[string]interface{})
+	if gotUser["email"] != "***" || gotUser["name"] != "alice" {
+		t.Errorf("user = %v, se esperaba solo email redactado", gotUser)
+	}
+	if _, ok := gotUser["phone"]; ok {
+		t.Errorf("una clave que no existe no debe añadirse: %v", gotUser)
+	}
+	gotSession := gotUser["session"].(map[string]interface{})
+	if gotSession["token"] != "***" || gotSession["age"] != float64(3) {
+		t.Errorf("session = %v, se esperaba token redactado", gotSession)
+	}
+	if tag := props["tags"].([]interface{})[0].(map[string]interface{}); tag["token"] != "***" {
+		t.Errorf("tags = %v, se esperaba token redactado dentro de la lista", props["tags"])
+	}
+	if props["plain"] != "ok" {
+		t.Errorf("plain = %v, no debía cambiar", props["plain"])
+	}
+	// El pdata no se modifica
+	if v, _ := user.Get("email"); v.Str() != "a@b.c" {
+		t.Errorf("se modificó el atributo original: %s", v.Str())
+	}
+}
+
+func TestRedactValueKey(t *testing.T) {
+	md := gaugeMetrics("logins")
+	attrs := md.ResourceMetrics().At(0).ScopeMetrics().At(0).Metrics().At(0).Gauge().DataPoints().At(0).Attributes()
+	attrs.PutStr("user.email", "a@b.c")
+	attrs.PutStr("method", "sso")
+
+	cfg := testConfig(t, "http://localhost")
+	cfg.KeyDataPointsByAttributes = true
+	cfg.RedactAttributes = []string{"user.email"}
+	records, _, err := newTestExporter(t, cfg).transformMetrics(md)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if keys := valueKeys(records); len(keys) != 1 || keys[0] != "logins{method=sso,user_email=REDACTED}" {
+		t.Errorf("claves de values = %v, el valor del atributo debía redactarse", keys)
+	}
+}
@@ -0,0 +1,47 @@
+package opentelemetryexportermonitoring
+
+import (
+	"encoding/base64"
+	"fmt"
+
+	"go.uber.org/zap"
+)
+
+// bytesDataPrefix es el prefijo de los valores bytes con bytes_data_prefix
+const bytesDataPrefix = "data:application/octet-stream;base64,"
+
+// attrValue normaliza un valor de AsRaw para el JSON propio: los bytes se
+// emiten siempre como string base64 (con bytes_data_prefix, como data URI)
+// en lugar de depender de cómo serialice json.Marshal el slice, y los valores
+// vacíos (ValueTypeEmpty, que AsRaw devuelve como nil) como
+// empty_attribute_value para distinguirlos de una clave ausente. Recorre los
+// mapas y listas anidados copiándolos
+func (m *monitoringExporter) attrValue(v interface{}) interface{} {
+	switch t := v.(type) {
+	case nil:
+		return m.emptyAttributeValue
+	case string, bool, int64, float64:
+		return v
+	case []byte:
+		encoded := base64.StdEncoding.EncodeToString(t)
+		if m.bytesDataPrefix {
+			return bytesDataPrefix + encoded
+		}
+		return encoded
+	case map[string]interface{}:
+		out := make(map[string]interface{}, len(t))
+		for k, child := range t {
+			out[k] = m.attrValue(child)
+		}
+		return out
+	case []interface{}:
+		out := make([]interface{}, len(t))
+		for i, child := range t {
+			out[i] = m.attrValue(child)
+		}
+		return out
+	default:
+		m.logger.Debug("Tipo de valor de atributo no soportado, se emite null", zap.String("type", fmt.Sprintf("%T", v)))
+		return nil
+	}
+}
@@ -0,0 +1,66 @@
+package opentelemetryexportermonitoring
+
+import "testing"
+
+func TestBytesAttributeBase64(t *testing.T) {
+	for _, tt := range []struct {
+		prefix bool
+		want   string
+	}{
+		{false, "3q2+7w=="},
+		{true, "data:application/octet-stream;base64,3q2+7w=="},
+	} {
+		ld := logsWithResources(1, "svc")
+		attrs := ld.ResourceLogs().At(0).ScopeLogs().At(0).LogRecords().At(0).Attributes()
+		attrs.PutEmptyBytes("payload").FromRaw([]byte{0xde, 0xad, 0xbe, 0xef})
+		attrs.PutEmptyMap("nested").PutEmptyBytes("id").FromRaw([]byte("hi"))
+
+		cfg := testConfig(t, "http://localhost")
+		cfg.BytesDataPrefix = tt.prefix
+		props := transformLogRecords(t, newTestExporter(t, cfg), ld)[0]["properties"].(map[string]interface{})
+		if props["payload"] != tt.want {
+			t.Errorf("bytes_data_prefix %v: payload = %v, se esperaba %s", tt.prefix, props["payload"], tt.want)
+		}
+		wantNested := "aGk="
+		if tt.prefix {
+			wantNested = bytesDataPrefix + wantNested
+		}
+		if got := props["nested"].(map[string]interface{})["id"]; got != wantNested {
+			t.Errorf("bytes_data_prefix %v: nested.id = %v, se esperaba %s", tt.prefix, got, wantNested)
+		}
+	}
+}
+
+func TestEmptyAttributeValue(t *testing.T) {
+	for _, tt := range []struct {
+		name  string
+		value *string
+		want  interface{}
+	}{
+		{"por defecto", nil, ""},
+		{"configurado", strPtr("<empty>"), "<empty>"},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			ld := logsWithResources(1, "svc")
+			attrs := ld.ResourceLogs().At(0).ScopeLogs().At(0).LogRecords().At(0).Attributes()
+			attrs.PutEmpty("flag")
+			attrs.PutEmptyMap("nested").PutEmpty("inner")
+
+			cfg := testConfig(t, "http://localhost")
+			if tt.value != nil {
+				cfg.EmptyAttributeValue = *tt.value
+			}
+			props := transformLogRecords(t, newTestExporter(t, cfg), ld)[0]["properties"].(map[string]interface{})
+			got, ok := props["flag"]
+			if !ok || got != tt.want {
+				t.Errorf("flag = %v (presente: %v), se esperaba %q", got, ok, tt.want)
+			}
+			if got := props["nested"].(map[string]interface{})["inner"]; got != tt.want {
+				t.Errorf("nested.inner = %v, se esperaba %q", got, tt.want)
+			}
+			if _, ok := props["absent"]; ok {
+				t.Error("una clave ausente no debe aparecer")
+			}
+		})
+	}
+}
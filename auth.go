@@ -0,0 +1,73 @@
+package opentelemetryexportermonitoring
+
+import (
+	"encoding/base64"
+	"errors"
+	"net/http"
+
+	"go.opentelemetry.io/collector/config/configopaque"
+	"go.uber.org/zap"
+)
+
+// AuthConfig añade la cabecera Authorization a todas las peticiones. El token
+// y la contraseña pueden venir de variables de entorno con ${env:NOMBRE} y
+// nunca se registran
+type AuthConfig struct {
+	BearerToken configopaque.String `mapstructure:"bearer_token"`
+	BasicAuth   *BasicAuthConfig    `mapstructure:"basic_auth"`
+}
+
+// BasicAuthConfig son las credenciales de HTTP Basic
+type BasicAuthConfig struct {
+	Username string              `mapstructure:"username"`
+	Password configopaque.String `mapstructure:"password"`
+}
+
+// validate comprueba el bloque auth; los errores no incluyen las credenciales
+func (a AuthConfig) validate() error {
+	if a.BasicAuth == nil {
+		return nil
+	}
+	if a.BasicAuth.Username == "" {
+		return errors.New("auth.basic_auth.username es obligatorio")
+	}
+	if a.BearerToken != "" {
+		return errors.New("auth: bearer_token y basic_auth son incompatibles")
+	}
+	return nil
+}
+
+// authorization devuelve el valor de Authorization, o "" sin auth configurada
+func (a AuthConfig) authorization() string {
+	switch {
+	case a.BearerToken != "":
+		return "Bearer " + string(a.BearerToken)
+	case a.BasicAuth != nil:
+		credentials := a.BasicAuth.Username + ":" + string(a.BasicAuth.Password)
+		return "Basic " + base64.StdEncoding.EncodeToString([]byte(credentials))
+	}
+	return ""
+}
+
+// authHeaders devuelve las cabeceras configuradas con Authorization añadida
+// según el bloque auth. Si headers ya trae un Authorization explícito, ese
+// gana y se avisa. No modifica cfg.Headers
+func authHeaders(cfg *Config, logger *zap.Logger) map[string]string {
+	authorization := cfg.Auth.authorization()
+	if authorization == "" {
+		return cfg.Headers
+	}
+	for k := range cfg.Headers {
+		if http.CanonicalHeaderKey(k) == "Authorization" {
+			logger.Warn("auth ignorado: headers ya define Authorization")
+			return cfg.Headers
+		}
+	}
+
+	headers := make(map[string]string, len(cfg.Headers)+1)
+	for k, v := range cfg.Headers {
+		headers[k] = v
+	}
+	headers["Authorization"] = authorization
+	return headers
+}
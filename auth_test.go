@@ -0,0 +1,78 @@
+package opentelemetryexportermonitoring
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+
+	"go.opentelemetry.io/collector/config/configopaque"
+)
+
+func TestSecretsRedacted(t *testing.T) {
+	cfg := testConfig(t, "http://localhost")
+	cfg.Auth = AuthConfig{BasicAuth: &BasicAuthConfig{Username: "ana", Password: "pass-secreta"}}
+	cfg.Signing.Secret = "hmac-secreto"
+	cfg.Encryption.Key = configopaque.String(testEncryptionKey)
+
+	for _, secret := range []string{"pass-secreta", "hmac-secreto", testEncryptionKey} {
+		for format, out := range map[string]string{
+			"%v":  fmt.Sprintf("%v %v %v", *cfg.Auth.BasicAuth, cfg.Signing, cfg.Encryption),
+			"%#v": fmt.Sprintf("%#v %#v %#v", *cfg.Auth.BasicAuth, cfg.Signing, cfg.Encryption),
+		} {
+			if strings.Contains(out, secret) {
+				t.Errorf("%s muestra un secreto: %s", format, out)
+			}
+		}
+		data, err := json.Marshal(cfg)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if strings.Contains(string(data), secret) {
+			t.Errorf("la config serializada muestra un secreto: %s", data)
+		}
+	}
+	if got := cfg.Auth.authorization(); got != "Basic YW5hOnBhc3Mtc2VjcmV0YQ==" {
+		t.Errorf("Authorization = %s; el valor real debe seguir usándose", got)
+	}
+}
+
+func TestBasicAuthHeader(t *testing.T) {
+	srv := newCaptureServer(t)
+	cfg := testConfig(t, srv.URL)
+	cfg.Auth.BasicAuth = &BasicAuthConfig{Username: "legacy", Password: "p@ss:wörd"}
+	if err := newTestExporter(t, cfg).pushMetrics(context.Background(), gaugeMetrics("m")); err != nil {
+		t.Fatal(err)
+	}
+	reqs := srv.received()
+	if len(reqs) != 1 {
+		t.Fatalf("peticiones = %d, se esperaba 1", len(reqs))
+	}
+	// base64("legacy:p@ss:wörd") calculado aparte; los ':' de la contraseña no se escapan
+	if got := reqs[0].Header.Get("Authorization"); got != "Basic bGVnYWN5OnBAc3M6d8O2cmQ=" {
+		t.Errorf("Authorization = %s", got)
+	}
+	if user, pass, ok := (&http.Request{Header: reqs[0].Header}).BasicAuth(); !ok || user != "legacy" || pass != "p@ss:wörd" {
+		t.Errorf("credenciales decodificadas = %q, %q", user, pass)
+	}
+}
+
+func TestBasicAuthValidate(t *testing.T) {
+	cfg := testConfig(t, "http://localhost")
+	cfg.Auth.BasicAuth = &BasicAuthConfig{Password: "pass-secreta"}
+	err := cfg.Validate()
+	if err == nil || !strings.Contains(err.Error(), "auth.basic_auth.username") {
+		t.Fatalf("err = %v, se esperaba el error de username", err)
+	}
+	if strings.Contains(err.Error(), "pass-secreta") {
+		t.Errorf("el error muestra la contraseña: %v", err)
+	}
+
+	cfg.Auth = AuthConfig{BearerToken: "token-secreto", BasicAuth: &BasicAuthConfig{Username: "u", Password: "pass-secreta"}}
+	err = cfg.Validate()
+	if err == nil || strings.Contains(err.Error(), "token-secreto") || strings.Contains(err.Error(), "pass-secreta") {
+		t.Errorf("err = %v, se esperaba el error de incompatibilidad sin credenciales", err)
+	}
+}
@@ -0,0 +1,66 @@
+package opentelemetryexportermonitoring
+
+import (
+	"bytes"
+	"encoding/json"
+	"strconv"
+	"strings"
+)
+
+// canonicalizeJSON reescribe body en forma canónica: claves ordenadas en
+// todos los niveles, sin espacios, sin escapar HTML y con los números no
+// enteros en el formato de encoding/json, así que la misma entrada produce
+// siempre los mismos bytes. Los enteros se mantienen tal cual para no perder
+// precisión en los int64
+func canonicalizeJSON(body []byte) ([]byte, error) {
+	v, err := decodeJSONNumbers(body)
+	if err != nil {
+		return nil, err
+	}
+	return marshalCanonical(v)
+}
+
+// decodeJSONNumbers decodifica body conservando los números como json.Number
+func decodeJSONNumbers(body []byte) (interface{}, error) {
+	dec := json.NewDecoder(bytes.NewReader(body))
+	dec.UseNumber()
+	var v interface{}
+	if err := dec.Decode(&v); err != nil {
+		return nil, err
+	}
+	return v, nil
+}
+
+// marshalCanonical serializa un valor de decodeJSONNumbers en forma canónica.
+// Es la única serialización canónica: la usan el modo canonical y el digest
+// de content_digest_header
+func marshalCanonical(v interface{}) ([]byte, error) {
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	if err := enc.Encode(canonicalNumbers(v)); err != nil {
+		return nil, err
+	}
+	return bytes.TrimSuffix(buf.Bytes(), []byte("\n")), nil
+}
+
+// canonicalNumbers convierte a float64 los números con decimales o exponente
+func canonicalNumbers(v interface{}) interface{} {
+	switch t := v.(type) {
+	case map[string]interface{}:
+		for k, child := range t {
+			t[k] = canonicalNumbers(child)
+		}
+	case []interface{}:
+		for i, child := range t {
+			t[i] = canonicalNumbers(child)
+		}
+	case json.Number:
+		if strings.ContainsAny(string(t), ".eE") {
+			if f, err := strconv.ParseFloat(string(t), 64); err == nil {
+				return f
+			}
+		}
+	}
+	return v
+}
@@ -0,0 +1,54 @@
+package opentelemetryexportermonitoring
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	"go.opentelemetry.io/collector/pdata/pmetric"
+)
+
+func TestCanonicalizeJSON(t *testing.T) {
+	a := []byte(`{"b":{"y":1.50,"x":[2,1e2]},"a":"<tag>","n":9007199254740993}`)
+	b := []byte(` { "n": 9007199254740993, "a": "<tag>", "b": { "x": [ 2, 100.0 ], "y": 1.5 } }`)
+	want := `{"a":"<tag>","b":{"x":[2,100],"y":1.5},"n":9007199254740993}`
+	for _, in := range [][]byte{a, b} {
+		got, err := canonicalizeJSON(in)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(got) != want {
+			t.Errorf("canonicalizeJSON(%s) =\n%s\nse esperaba\n%s", in, got, want)
+		}
+	}
+}
+
+func TestCanonicalPayloadByteIdentical(t *testing.T) {
+	srv := newCaptureServer(t)
+	cfg := testConfig(t, srv.URL)
+	cfg.Canonical = true
+	exp := newTestExporter(t, cfg)
+
+	// Muchos atributos para que el orden de los mapas cambie entre envíos
+	md := gaugeMetrics("a", "b")
+	dp := md.ResourceMetrics().At(0).ScopeMetrics().At(0).Metrics().At(0).Gauge().DataPoints().At(0)
+	for _, k := range []string{"k1", "k2", "k3", "k4", "k5", "k6", "k7", "k8"} {
+		dp.Attributes().PutDouble(k, 0.25)
+	}
+	for i := 0; i < 5; i++ {
+		batch := pmetric.NewMetrics()
+		md.CopyTo(batch)
+		if err := exp.pushMetrics(context.Background(), batch); err != nil {
+			t.Fatal(err)
+		}
+	}
+	reqs := srv.received()
+	for i, req := range reqs[1:] {
+		if !bytes.Equal(req.Body, reqs[0].Body) {
+			t.Errorf("envío %d distinto del primero:\n%s\n%s", i+2, req.Body, reqs[0].Body)
+		}
+	}
+	if canonical, _ := canonicalizeJSON(reqs[0].Body); !bytes.Equal(canonical, reqs[0].Body) {
+		t.Errorf("el payload no está en forma canónica:\n%s", reqs[0].Body)
+	}
+}
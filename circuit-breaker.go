@@ -0,0 +1,109 @@
+package opentelemetryexportermonitoring
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"go.opentelemetry.io/collector/consumer/consumererror"
+	"go.opentelemetry.io/collector/exporter/exporterhelper"
+	"go.uber.org/zap"
+)
+
+// CircuitBreakerConfig deja de enviar al backend tras FailureThreshold fallos
+// seguidos dentro de Window. Durante Cooldown los envíos fallan al momento
+// con un error reintentable; después se deja pasar una única petición de
+// prueba que cierra el circuito si va bien o lo vuelve a abrir si falla.
+// Se activa con FailureThreshold > 0
+type CircuitBreakerConfig struct {
+	FailureThreshold int           `mapstructure:"failure_threshold"`
+	Window           time.Duration `mapstructure:"window"`
+	Cooldown         time.Duration `mapstructure:"cooldown"`
+}
+
+// circuitBreaker lleva el estado del circuito de un exporter
+type circuitBreaker struct {
+	threshold int
+	window    time.Duration
+	cooldown  time.Duration
+	logger    *zap.Logger
+
+	mu           sync.Mutex
+	failures     int
+	firstFailure time.Time
+	openUntil    time.Time // cero con el circuito cerrado
+	probing      bool
+}
+
+// newCircuitBreaker devuelve nil si el circuito no está configurado
+func newCircuitBreaker(cfg CircuitBreakerConfig, logger *zap.Logger) *circuitBreaker {
+	if cfg.FailureThreshold <= 0 {
+		return nil
+	}
+	return &circuitBreaker{
+		threshold: cfg.FailureThreshold,
+		window:    cfg.Window,
+		cooldown:  cfg.Cooldown,
+		logger:    logger,
+	}
+}
+
+// allow indica si la petición puede salir. Con el circuito abierto devuelve
+// un error de reintento que espera lo que queda de cooldown. probe es true si
+// la petición admitida es la de prueba; hay que pasarlo tal cual a record
+func (b *circuitBreaker) allow(now time.Time, url string) (probe bool, err error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.openUntil.IsZero() {
+		return false, nil
+	}
+	if now.Before(b.openUntil) || b.probing {
+		wait := b.openUntil.Sub(now)
+		if wait <= 0 {
+			wait = b.cooldown
+		}
+		return false, exporterhelper.NewThrottleRetry(fmt.Errorf("monitoring exporter: circuito abierto, no se envía a %s", url), wait)
+	}
+	b.probing = true
+	return true, nil
+}
+
+// record anota el resultado de una petición permitida por allow. Los errores
+// permanentes (4xx) no cuentan: el backend responde. Con el circuito abierto
+// solo decide la petición de prueba; las que salieron antes de abrirlo y
+// terminan ahora se ignoran
+func (b *circuitBreaker) record(now time.Time, probe bool, err error) {
+	failed := err != nil && !consumererror.IsPermanent(err)
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if probe {
+		b.probing = false
+		if failed {
+			b.openUntil = now.Add(b.cooldown)
+			b.logger.Warn("circuito reabierto: la petición de prueba ha fallado", zap.Duration("cooldown", b.cooldown), zap.Error(err))
+			return
+		}
+		b.openUntil = time.Time{}
+		b.failures = 0
+		b.logger.Info("circuito cerrado: el backend vuelve a responder")
+		return
+	}
+	if !b.openUntil.IsZero() {
+		return
+	}
+	if !failed {
+		b.failures = 0
+		return
+	}
+	if b.failures == 0 || (b.window > 0 && now.Sub(b.firstFailure) > b.window) {
+		b.failures = 0
+		b.firstFailure = now
+	}
+	b.failures++
+	if b.failures >= b.threshold {
+		b.openUntil = now.Add(b.cooldown)
+		b.failures = 0
+		b.logger.Warn("circuito abierto tras fallos consecutivos", zap.Int("failures", b.threshold), zap.Duration("cooldown", b.cooldown), zap.Error(err))
+	}
+}
@@ -0,0 +1,96 @@
+package opentelemetryexportermonitoring
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"go.opentelemetry.io/collector/consumer/consumererror"
+	"go.uber.org/zap"
+)
+
+func TestCircuitBreakerTripsAndResets(t *testing.T) {
+	b := newCircuitBreaker(CircuitBreakerConfig{FailureThreshold: 3, Window: time.Minute, Cooldown: 10 * time.Second}, zap.NewNop())
+	now := time.Unix(1000, 0)
+	fail := errors.New("connection refused")
+
+	for i := 0; i < 3; i++ {
+		probe, err := b.allow(now, "http://x")
+		if err != nil || probe {
+			t.Fatalf("fallo %d: circuito cerrado, allow = %v, %v", i, probe, err)
+		}
+		b.record(now, probe, fail)
+	}
+
+	// Abierto: falla al momento con un error reintentable
+	_, err := b.allow(now.Add(time.Second), "http://x")
+	if err == nil || consumererror.IsPermanent(err) {
+		t.Fatalf("err = %v, se esperaba un error reintentable con el circuito abierto", err)
+	}
+
+	// Tras el cooldown pasa una única petición de prueba
+	now = now.Add(11 * time.Second)
+	probe, err := b.allow(now, "http://x")
+	if err != nil || !probe {
+		t.Fatalf("allow tras el cooldown = %v, %v; se esperaba la petición de prueba", probe, err)
+	}
+	if _, err := b.allow(now, "http://x"); err == nil {
+		t.Fatal("con la prueba en vuelo no debe salir otra petición")
+	}
+	// La prueba fallida reabre el circuito
+	b.record(now, probe, fail)
+	if _, err := b.allow(now.Add(time.Second), "http://x"); err == nil {
+		t.Fatal("la prueba ha fallado y el circuito debía reabrirse")
+	}
+
+	now = now.Add(11 * time.Second)
+	probe, _ = b.allow(now, "http://x")
+	b.record(now, probe, nil)
+	if probe, err := b.allow(now, "http://x"); err != nil || probe {
+		t.Fatalf("la prueba fue bien y el circuito debía cerrarse: %v, %v", probe, err)
+	}
+}
+
+func TestCircuitBreakerIgnoresStaleResults(t *testing.T) {
+	b := newCircuitBreaker(CircuitBreakerConfig{FailureThreshold: 1, Cooldown: 10 * time.Second}, zap.NewNop())
+	now := time.Unix(1000, 0)
+
+	// Dos peticiones salen con el circuito cerrado; la primera lo abre
+	inFlight, _ := b.allow(now, "http://x")
+	first, _ := b.allow(now, "http://x")
+	b.record(now, first, errors.New("timeout"))
+
+	now = now.Add(11 * time.Second)
+	probe, err := b.allow(now, "http://x")
+	if err != nil || !probe {
+		t.Fatalf("allow tras el cooldown = %v, %v", probe, err)
+	}
+	// La que salió antes de abrirlo termina bien: no es la prueba y no cierra
+	b.record(now, inFlight, nil)
+	if _, err := b.allow(now, "http://x"); err == nil {
+		t.Fatal("un resultado ajeno a la prueba ha cerrado el circuito")
+	}
+	b.record(now, probe, errors.New("timeout"))
+	if _, err := b.allow(now.Add(time.Second), "http://x"); err == nil {
+		t.Fatal("la prueba ha fallado y el circuito debía seguir abierto")
+	}
+}
+
+func TestCircuitBreakerFailsFastWithoutPosting(t *testing.T) {
+	srv := newCaptureServer(t)
+	srv.respond(func(w http.ResponseWriter, r *http.Request) { w.WriteHeader(http.StatusServiceUnavailable) })
+	cfg := testConfig(t, srv.URL)
+	cfg.CircuitBreaker = CircuitBreakerConfig{FailureThreshold: 2, Cooldown: time.Hour}
+	exp := newTestExporter(t, cfg)
+
+	for i := 0; i < 4; i++ {
+		if err := exp.pushMetrics(context.Background(), gaugeMetrics("m")); err == nil || consumererror.IsPermanent(err) {
+			t.Fatalf("envío %d: err = %v, se esperaba un error reintentable", i, err)
+		}
+	}
+	if got := len(srv.received()); got != 2 {
+		t.Errorf("peticiones = %d, con el circuito abierto no deben llegar más de 2", got)
+	}
+}
@@ -0,0 +1,170 @@
+package opentelemetryexportermonitoring
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"sync"
+	"time"
+)
+
+// endpointCoalescer junta los POST JSON que llegan a una misma URL durante
+// coalesce_window en una única petición. Solo se juntan los bodies que son
+// arrays JSON (traces y logs con la codificación propia por defecto); el
+// resto se envía tal cual. Cada llamada espera al envío conjunto y recibe su
+// error, así que los reintentos del exporterhelper siguen funcionando. Una
+// llamada cancelada antes del envío retira sus registros
+type endpointCoalescer struct {
+	window   time.Duration
+	maxBytes int
+
+	mu      sync.Mutex
+	pending map[string]*coalescedRequest
+}
+
+// coalescedRequest es el envío pendiente de una URL
+type coalescedRequest struct {
+	ctx   context.Context
+	send  func(context.Context, string, []byte) error
+	parts []*coalescedPart
+	size  int
+	timer *time.Timer
+}
+
+// coalescedPart son los registros de una llamada a post y su resultado
+type coalescedPart struct {
+	items []json.RawMessage
+	size  int
+	done  chan error
+}
+
+// newEndpointCoalescer junta envíos de hasta maxBytes; con max_payload_bytes
+// el límite es el menor de los dos, para no rehacer un body que luego habría
+// que partir
+func newEndpointCoalescer(window time.Duration, maxBytes, maxPayloadBytes int) *endpointCoalescer {
+	if maxPayloadBytes > 0 && (maxBytes <= 0 || maxPayloadBytes < maxBytes) {
+		maxBytes = maxPayloadBytes
+	}
+	return &endpointCoalescer{
+		window:   window,
+		maxBytes: maxBytes,
+		pending:  make(map[string]*coalescedRequest),
+	}
+}
+
+// post añade body al envío pendiente de url y espera a que se haga. Si el
+// envío supera coalesce_max_bytes se hace sin esperar al final de la ventana
+func (c *endpointCoalescer) post(ctx context.Context, url string, body []byte, send func(context.Context, string, []byte) error) error {
+	var items []json.RawMessage
+	if !bytes.HasPrefix(bytes.TrimSpace(body), []byte("[")) || json.Unmarshal(body, &items) != nil {
+		return send(ctx, url, body)
+	}
+
+	part := &coalescedPart{items: items, size: len(body), done: make(chan error, 1)}
+	var full []*coalescedRequest
+
+	c.mu.Lock()
+	req := c.pending[url]
+	if req != nil && c.maxBytes > 0 && req.size+len(body) > c.maxBytes {
+		full = append(full, c.takeLocked(url))
+		req = nil
+	}
+	if req == nil {
+		// El envío conjunto no depende de la cancelación de la primera llamada
+		req = &coalescedRequest{ctx: context.WithoutCancel(ctx), send: send}
+		c.pending[url] = req
+		req.timer = time.AfterFunc(c.window, func() { c.flushRequest(url, req) })
+	}
+	req.parts = append(req.parts, part)
+	req.size += part.size
+	if c.maxBytes > 0 && req.size >= c.maxBytes {
+		full = append(full, c.takeLocked(url))
+	}
+	c.mu.Unlock()
+
+	for _, r := range full {
+		go c.send(url, r)
+	}
+
+	select {
+	case err := <-part.done:
+		return err
+	case <-ctx.Done():
+	}
+	if c.withdraw(url, req, part) {
+		return ctx.Err()
+	}
+	// El envío ya ha salido con estos registros: su resultado es el de la llamada
+	return <-part.done
+}
+
+// withdraw quita part de req si req sigue pendiente; devuelve false si ya se
+// está enviando
+func (c *endpointCoalescer) withdraw(url string, req *coalescedRequest, part *coalescedPart) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.pending[url] != req {
+		return false
+	}
+	for i, p := range req.parts {
+		if p == part {
+			req.parts = append(req.parts[:i], req.parts[i+1:]...)
+			req.size -= part.size
+			break
+		}
+	}
+	if len(req.parts) == 0 {
+		c.takeLocked(url)
+	}
+	return true
+}
+
+// takeLocked saca de pending el envío de url; requiere c.mu
+func (c *endpointCoalescer) takeLocked(url string) *coalescedRequest {
+	req := c.pending[url]
+	delete(c.pending, url)
+	req.timer.Stop()
+	return req
+}
+
+// flushRequest envía req si sigue pendiente (puede haberse vaciado ya por tamaño)
+func (c *endpointCoalescer) flushRequest(url string, req *coalescedRequest) {
+	c.mu.Lock()
+	if c.pending[url] != req {
+		c.mu.Unlock()
+		return
+	}
+	c.takeLocked(url)
+	c.mu.Unlock()
+	c.send(url, req)
+}
+
+// send hace el POST conjunto y entrega el resultado a todas las llamadas
+func (c *endpointCoalescer) send(url string, req *coalescedRequest) {
+	var items []json.RawMessage
+	for _, part := range req.parts {
+		items = append(items, part.items...)
+	}
+	body, err := json.Marshal(items)
+	if err == nil {
+		err = req.send(req.ctx, url, body)
+	}
+	for _, part := range req.parts {
+		part.done <- err
+	}
+}
+
+// flushAll envía todo lo pendiente; se usa al parar el exporter
+func (c *endpointCoalescer) flushAll() {
+	c.mu.Lock()
+	var reqs []*coalescedRequest
+	var urls []string
+	for url := range c.pending {
+		urls = append(urls, url)
+		reqs = append(reqs, c.takeLocked(url))
+	}
+	c.mu.Unlock()
+	for i, req := range reqs {
+		c.send(urls[i], req)
+	}
+}
@@ -0,0 +1,128 @@
+package opentelemetryexportermonitoring
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+)
+
+// coalescedSends registra los bodies que el coalescer envía
+type coalescedSends struct {
+	mu     sync.Mutex
+	bodies []string
+}
+
+func (s *coalescedSends) send(_ context.Context, _ string, body []byte) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.bodies = append(s.bodies, string(body))
+	return nil
+}
+
+func (s *coalescedSends) sent() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]string(nil), s.bodies...)
+}
+
+// postAll hace los posts en paralelo y devuelve sus errores en orden
+func postAll(c *endpointCoalescer, sends *coalescedSends, ctxs []context.Context, bodies ...string) []error {
+	errs := make([]error, len(bodies))
+	var wg sync.WaitGroup
+	for i, body := range bodies {
+		wg.Add(1)
+		go func(i int, body string) {
+			defer wg.Done()
+			errs[i] = c.post(ctxs[i], "http://sink/x", []byte(body), sends.send)
+		}(i, body)
+	}
+	wg.Wait()
+	return errs
+}
+
+func background(n int) []context.Context {
+	ctxs := make([]context.Context, n)
+	for i := range ctxs {
+		ctxs[i] = context.Background()
+	}
+	return ctxs
+}
+
+func TestCoalescerMergesWithinWindow(t *testing.T) {
+	sends := &coalescedSends{}
+	c := newEndpointCoalescer(50*time.Millisecond, 1<<20, 0)
+	for _, err := range postAll(c, sends, background(2), `[{"a":1}]`, `[{"a":2},{"a":3}]`) {
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	bodies := sends.sent()
+	if len(bodies) != 1 {
+		t.Fatalf("envíos = %v, se esperaba uno conjunto", bodies)
+	}
+	var items []map[string]int
+	decodeJSON(t, []byte(bodies[0]), &items)
+	if len(items) != 3 {
+		t.Errorf("body conjunto = %s, se esperaban los 3 registros", bodies[0])
+	}
+
+	// Lo que no es un array JSON se envía tal cual y sin esperar
+	if err := c.post(context.Background(), "http://sink/x", []byte(`{"metrics":[]}`), sends.send); err != nil {
+		t.Fatal(err)
+	}
+	if got := sends.sent(); len(got) != 2 || got[1] != `{"metrics":[]}` {
+		t.Errorf("envíos = %v", got)
+	}
+}
+
+func TestCoalescerCapsAtMaxPayloadBytes(t *testing.T) {
+	sends := &coalescedSends{}
+	// coalesce_max_bytes es holgado; manda max_payload_bytes
+	c := newEndpointCoalescer(20*time.Millisecond, 1<<20, 25)
+	for _, err := range postAll(c, sends, background(2), `[{"n":"xxxxxxxxx"}]`, `[{"n":"yyyyyyyyy"}]`) {
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	bodies := sends.sent()
+	if len(bodies) != 2 {
+		t.Fatalf("envíos = %v, juntar los dos superaría max_payload_bytes", bodies)
+	}
+	for _, body := range bodies {
+		if len(body) > 25 {
+			t.Errorf("body de %d bytes supera max_payload_bytes: %s", len(body), body)
+		}
+	}
+}
+
+func TestCoalescerCancelledCallerWithdraws(t *testing.T) {
+	sends := &coalescedSends{}
+	c := newEndpointCoalescer(100*time.Millisecond, 1<<20, 0)
+	cancelled, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	errs := postAll(c, sends, []context.Context{cancelled, context.Background()}, `[{"a":"cancelado"}]`, `[{"a":"vivo"}]`)
+
+	if !errors.Is(errs[0], context.DeadlineExceeded) {
+		t.Errorf("err de la llamada cancelada = %v", errs[0])
+	}
+	if errs[1] != nil {
+		t.Errorf("err = %v", errs[1])
+	}
+	if got := sends.sent(); len(got) != 1 || got[0] != `[{"a":"vivo"}]` {
+		t.Errorf("envíos = %v, los registros de la llamada cancelada no deben enviarse", got)
+	}
+
+	// Si todas las llamadas se cancelan no se envía nada
+	sends = &coalescedSends{}
+	cancelled, cancel = context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if err := c.post(cancelled, "http://sink/x", []byte(`[{"a":1}]`), sends.send); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("err = %v", err)
+	}
+	time.Sleep(150 * time.Millisecond)
+	if got := sends.sent(); len(got) != 0 {
+		t.Errorf("envíos = %v, no quedaba nada que enviar", got)
+	}
+}
@@ -0,0 +1,272 @@
+package opentelemetryexportermonitoring
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/consumer/consumererror"
+	"go.opentelemetry.io/collector/pdata/plog"
+	"go.opentelemetry.io/collector/pdata/pmetric"
+	"go.opentelemetry.io/collector/pdata/ptrace"
+	"go.uber.org/zap"
+)
+
+// combinedBuffer acumula las tres señales de una misma instancia del
+// exporter (mismo component.ID) y las envía juntas a combined_endpoint como
+// {"traces": [...], "metrics": [...], "logs": [...]}. Se vacía al acabar
+// combined_window o al llegar a combined_max_items registros. Igual que en la
+// ventana de batch, un payload que falla con un error no permanente se
+// conserva y se reenvía en los vaciados siguientes, hasta windowFlushAttempts
+// envíos y como mucho maxFailedWindows payloads
+type combinedBuffer struct {
+	id       component.ID
+	exp      *monitoringExporter
+	url      string
+	window   time.Duration
+	maxItems int
+
+	mu      sync.Mutex
+	traces  []outSpan
+	metrics []transformedMetric
+	logs    []transformedLog
+	failed  []failedCombined
+
+	running int
+	stopCh  chan struct{}
+	wg      sync.WaitGroup
+}
+
+// failedCombined es un payload combinado pendiente de reenvío; conserva su
+// batchId para que el sink pueda deduplicarlo
+type failedCombined struct {
+	body     []byte
+	batchID  string
+	attempts int
+}
+
+var (
+	combinedMu      sync.Mutex
+	combinedBuffers = map[component.ID]*combinedBuffer{}
+)
+
+// acquireCombinedBuffer devuelve el buffer compartido por los exporters de
+// traces, metrics y logs de id; el primero en llegar lo crea con su exporter.
+// El buffer se retira del registro cuando se apaga el último de ellos
+func acquireCombinedBuffer(id component.ID, exp *monitoringExporter, c *Config) *combinedBuffer {
+	combinedMu.Lock()
+	defer combinedMu.Unlock()
+	if b, ok := combinedBuffers[id]; ok {
+		return b
+	}
+	b := &combinedBuffer{
+		id:       id,
+		exp:      exp,
+		url:      c.CombinedEndpoint,
+		window:   c.CombinedWindow,
+		maxItems: c.CombinedMaxItems,
+	}
+	combinedBuffers[id] = b
+	return b
+}
+
+// add ejecuta fn con el buffer bloqueado y lo vacía si se supera maxItems. Un
+// fallo del vaciado no se devuelve: los datos quedan pendientes de reenvío y
+// el reintento del exporterhelper los duplicaría
+func (b *combinedBuffer) add(ctx context.Context, fn func()) {
+	b.mu.Lock()
+	fn()
+	full := b.maxItems > 0 && len(b.traces)+len(b.metrics)+len(b.logs) >= b.maxItems
+	b.mu.Unlock()
+	if full {
+		_ = b.flush(ctx)
+	}
+}
+
+// pushTraces, pushMetrics y pushLogs respetan traces, metrics y logs: una
+// señal desactivada no entra en el payload combinado
+func (b *combinedBuffer) pushTraces(ctx context.Context, td ptrace.Traces) error {
+	if !b.exp.traces {
+		b.exp.logger.Sugar().Warnln("El envío de traces está deshabilitado, no se realizará el POST.")
+		return nil
+	}
+	out, _, err := b.exp.transformTraces(td, transformCfg{UserNamespace: b.exp.ns})
+	if err != nil {
+		return err
+	}
+	var spans []outSpan
+	if err := json.Unmarshal(out, &spans); err != nil {
+		return fmt.Errorf("error unmarshaling transformed traces: %w", err)
+	}
+	b.add(ctx, func() { b.traces = append(b.traces, spans...) })
+	return nil
+}
+
+func (b *combinedBuffer) pushMetrics(ctx context.Context, md pmetric.Metrics) error {
+	if !b.exp.metrics {
+		b.exp.logger.Sugar().Warnln("El envío de métricas está deshabilitado, no se realizará el POST.")
+		return nil
+	}
+	records, _, err := b.exp.transformMetrics(md)
+	if err != nil {
+		return err
+	}
+	b.add(ctx, func() { b.metrics = append(b.metrics, records...) })
+	// El buffer ya tiene los registros y el lote no se reintentará
+	if b.exp.staleness != nil {
+		b.exp.staleness.commit(records)
+	}
+	return nil
+}
+
+func (b *combinedBuffer) pushLogs(ctx context.Context, ld plog.Logs) error {
+	if !b.exp.logs {
+		b.exp.logger.Sugar().Warnln("El envío de logs está deshabilitado, no se realizará el POST.")
+		return nil
+	}
+	out, _, err := b.exp.transformLogs(ld, transformCfg{UserNamespace: b.exp.mrid})
+	if err != nil {
+		return err
+	}
+	var logs []transformedLog
+	if err := json.Unmarshal(out, &logs); err != nil {
+		return fmt.Errorf("error unmarshaling transformed logs: %w", err)
+	}
+	b.add(ctx, func() { b.logs = append(b.logs, logs...) })
+	return nil
+}
+
+// flush reenvía los payloads fallidos y envía lo acumulado en un único POST
+func (b *combinedBuffer) flush(ctx context.Context) error {
+	b.mu.Lock()
+	traces, metrics, logs := b.traces, b.metrics, b.logs
+	b.traces, b.metrics, b.logs = nil, nil, nil
+	failed := b.failed
+	b.failed = nil
+	b.mu.Unlock()
+
+	var errs []error
+	for _, f := range failed {
+		errs = append(errs, b.send(ctx, f))
+	}
+	if len(traces)+len(metrics)+len(logs) == 0 {
+		return errors.Join(errs...)
+	}
+
+	ctx, batchID := b.exp.withBatchID(ctx)
+	payload := map[string]interface{}{
+		"traces":  nonNil(traces),
+		"metrics": nonNil(metrics),
+		"logs":    nonNil(logs),
+	}
+	if batchID != "" {
+		payload["batchId"] = batchID
+	}
+	if b.exp.collectorHost != "" {
+		payload["collector_host"] = b.exp.collectorHost
+	}
+	body, err := b.exp.marshalRecords(payload)
+	if err != nil {
+		b.exp.logger.Error("error al serializar el payload combinado", zap.Error(err))
+		return errors.Join(append(errs, err)...)
+	}
+	errs = append(errs, b.send(ctx, failedCombined{body: body, batchID: batchID}))
+	return errors.Join(errs...)
+}
+
+// send envía un payload y lo conserva para reenviarlo si falla y le quedan
+// intentos
+func (b *combinedBuffer) send(ctx context.Context, p failedCombined) error {
+	if p.batchID != "" {
+		ctx = context.WithValue(ctx, batchIDKey{}, p.batchID)
+	}
+	err := b.exp.postJSON(ctx, b.url, p.body)
+	if err == nil {
+		return nil
+	}
+	p.attempts++
+	if consumererror.IsPermanent(err) || p.attempts >= windowFlushAttempts {
+		b.exp.logger.Error("error al enviar el payload combinado, se descarta",
+			zap.Int("attempts", p.attempts), zap.Error(err))
+		return err
+	}
+	b.exp.logger.Warn("error al enviar el payload combinado, se reenviará",
+		zap.Int("attempts", p.attempts), zap.Error(err))
+	b.mu.Lock()
+	if len(b.failed) >= maxFailedWindows {
+		b.exp.logger.Error("demasiados payloads combinados pendientes de reenvío, se descarta el más antiguo")
+		b.failed = b.failed[1:]
+	}
+	b.failed = append(b.failed, p)
+	b.mu.Unlock()
+	return err
+}
+
+// nonNil serializa las señales vacías como [] en lugar de null
+func nonNil[T any](s []T) []T {
+	if s == nil {
+		return []T{}
+	}
+	return s
+}
+
+// start arranca el temporizador con el primer exporter de la instancia
+func (b *combinedBuffer) start(_ context.Context, _ component.Host) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.running++
+	if b.running > 1 {
+		return nil
+	}
+	b.stopCh = make(chan struct{})
+	b.wg.Add(1)
+	go func(stop chan struct{}) {
+		defer b.wg.Done()
+		ticker := time.NewTicker(b.window)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				_ = b.flush(context.Background())
+			case <-stop:
+				return
+			}
+		}
+	}(b.stopCh)
+	return nil
+}
+
+// shutdown para el temporizador con el último exporter, lo retira del
+// registro y envía lo pendiente; devuelve el error de ese último envío
+func (b *combinedBuffer) shutdown(ctx context.Context) error {
+	b.mu.Lock()
+	b.running--
+	if b.running > 0 {
+		b.mu.Unlock()
+		return nil
+	}
+	stop := b.stopCh
+	b.stopCh = nil
+	b.mu.Unlock()
+
+	combinedMu.Lock()
+	if combinedBuffers[b.id] == b {
+		delete(combinedBuffers, b.id)
+	}
+	combinedMu.Unlock()
+	if stop == nil {
+		return nil
+	}
+
+	close(stop)
+	b.wg.Wait()
+	err := b.flush(ctx)
+	b.mu.Lock()
+	b.failed = nil
+	b.mu.Unlock()
+	return err
+}
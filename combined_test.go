@@ -0,0 +1,161 @@
+package opentelemetryexportermonitoring
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"go.opentelemetry.io/collector/component"
+)
+
+// combinedPayload es el body que recibe combined_endpoint
+type combinedPayload struct {
+	Traces  []map[string]interface{} `json:"traces"`
+	Metrics []map[string]interface{} `json:"metrics"`
+	Logs    []map[string]interface{} `json:"logs"`
+}
+
+// combinedConfig envía en modo combinado a serverURL/combined con una ventana
+// que no vence durante el test
+func combinedConfig(t *testing.T, serverURL string) *Config {
+	cfg := testConfig(t, serverURL)
+	cfg.CombinedEndpoint = serverURL + "/combined"
+	cfg.CombinedWindow = time.Hour
+	cfg.CombinedMaxItems = 0
+	return cfg
+}
+
+// consumeAll envía un lote de cada señal y apaga los tres exporters
+func consumeAll(t *testing.T, cfg *Config) {
+	t.Helper()
+	traces, metrics, logs := newFactoryExporters(t, cfg)
+	ctx := context.Background()
+	if err := traces.ConsumeTraces(ctx, testTraces("svc", "a", "b")); err != nil {
+		t.Fatal(err)
+	}
+	if err := metrics.ConsumeMetrics(ctx, gaugeMetrics("m")); err != nil {
+		t.Fatal(err)
+	}
+	if err := logs.ConsumeLogs(ctx, logsWithResources(3, "svc")); err != nil {
+		t.Fatal(err)
+	}
+	for _, c := range []component.Component{traces, metrics, logs} {
+		if err := c.Shutdown(ctx); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestCombinedPayloadShape(t *testing.T) {
+	srv := newCaptureServer(t)
+	cfg := combinedConfig(t, srv.URL)
+	consumeAll(t, cfg)
+
+	reqs := srv.received()
+	if len(reqs) != 1 || reqs[0].Path != "/combined" {
+		t.Fatalf("peticiones = %d, se esperaba un único POST combinado", len(reqs))
+	}
+	var payload combinedPayload
+	decodeJSON(t, reqs[0].Body, &payload)
+	if len(payload.Traces) != 2 || len(payload.Metrics) != 1 || len(payload.Logs) != 3 {
+		t.Errorf("payload con %d spans, %d métricas y %d logs, se esperaban 2, 1 y 3",
+			len(payload.Traces), len(payload.Metrics), len(payload.Logs))
+	}
+	if payload.Traces[0]["name"] != "a" || payload.Metrics[0]["values"].(map[string]interface{})["m"] == nil {
+		t.Errorf("payload = %s", reqs[0].Body)
+	}
+
+	combinedMu.Lock()
+	defer combinedMu.Unlock()
+	if len(combinedBuffers) != 0 {
+		t.Errorf("quedan %d buffers combinados registrados tras el shutdown", len(combinedBuffers))
+	}
+}
+
+func TestCombinedRespectsSignalFlags(t *testing.T) {
+	srv := newCaptureServer(t)
+	cfg := combinedConfig(t, srv.URL)
+	cfg.Logs = false
+	consumeAll(t, cfg)
+
+	reqs := srv.received()
+	if len(reqs) != 1 {
+		t.Fatalf("peticiones = %d, se esperaba 1", len(reqs))
+	}
+	var payload combinedPayload
+	decodeJSON(t, reqs[0].Body, &payload)
+	if payload.Logs == nil || len(payload.Logs) != 0 {
+		t.Errorf("con logs desactivado se enviaron logs: %v", payload.Logs)
+	}
+	if len(payload.Traces) != 2 || len(payload.Metrics) != 1 {
+		t.Errorf("payload = %s", reqs[0].Body)
+	}
+}
+
+func TestCombinedReflushesFailedPayload(t *testing.T) {
+	srv := newCaptureServer(t)
+	srv.respond(failFirst(1, "/combined"))
+	cfg := combinedConfig(t, srv.URL)
+	cfg.CombinedMaxItems = 1
+	_, metrics, logs := newFactoryExporters(t, cfg)
+
+	// Con combined_max_items 1 cada lote se envía al llegar; el primero falla
+	ctx := context.Background()
+	if err := metrics.ConsumeMetrics(ctx, gaugeMetrics("m")); err != nil {
+		t.Fatal(err)
+	}
+	if err := logs.ConsumeLogs(ctx, logsWithResources(1, "svc")); err != nil {
+		t.Fatal(err)
+	}
+	reqs := srv.received()
+	if len(reqs) != 3 {
+		t.Fatalf("peticiones = %d, se esperaba el fallo, su reenvío y el lote de logs", len(reqs))
+	}
+	if !bytes.Equal(reqs[0].Body, reqs[1].Body) {
+		t.Error("el reenvío no lleva el mismo payload")
+	}
+	var payload combinedPayload
+	decodeJSON(t, reqs[2].Body, &payload)
+	if len(payload.Logs) != 1 || len(payload.Metrics) != 0 {
+		t.Errorf("tercer payload = %s", reqs[2].Body)
+	}
+}
+
+func TestCombinedReflushesLargePayloadUnchanged(t *testing.T) {
+	srv := newCaptureServer(t)
+	srv.respond(failFirst(1, "/combined"))
+	cfg := combinedConfig(t, srv.URL)
+	cfg.CombinedMaxItems = 1
+	cfg.MaxBodyBytes = 256
+	_, metrics, logs := newFactoryExporters(t, cfg)
+
+	// El payload fallido supera max_body_bytes y se trunca en el log local
+	names := make([]string, 50)
+	for i := range names {
+		names[i] = fmt.Sprintf("metric.name.%02d", i)
+	}
+	ctx := context.Background()
+	if err := metrics.ConsumeMetrics(ctx, gaugeMetrics(names...)); err != nil {
+		t.Fatal(err)
+	}
+	if err := logs.ConsumeLogs(ctx, logsWithResources(1, "svc")); err != nil {
+		t.Fatal(err)
+	}
+	reqs := srv.received()
+	if len(reqs) < 2 {
+		t.Fatalf("peticiones = %d, se esperaba el fallo y su reenvío", len(reqs))
+	}
+	if len(reqs[0].Body) <= cfg.MaxBodyBytes {
+		t.Fatalf("el payload mide %d bytes, debe superar max_body_bytes", len(reqs[0].Body))
+	}
+	if !bytes.Equal(reqs[0].Body, reqs[1].Body) {
+		t.Errorf("el reenvío no es idéntico al payload fallido:\n%s\n%s", reqs[0].Body, reqs[1].Body)
+	}
+	var payload combinedPayload
+	decodeJSON(t, reqs[1].Body, &payload)
+	if len(payload.Metrics) != len(names) {
+		t.Errorf("métricas reenviadas = %d, se esperaban %d", len(payload.Metrics), len(names))
+	}
+}
@@ -0,0 +1,37 @@
+package opentelemetryexportermonitoring
+
+import (
+	"bytes"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"sync"
+)
+
+// Compresiones de body soportadas
+const (
+	compressionNone = "none"
+	compressionGzip = "gzip"
+)
+
+// gzipWriters reutiliza los gzip.Writer entre peticiones: cada uno reserva
+// varios cientos de KB de estado interno
+var gzipWriters = sync.Pool{
+	New: func() interface{} { return gzip.NewWriter(io.Discard) },
+}
+
+// gzipBody comprime body con un writer del pool; el writer se cierra antes
+// de devolver el resultado para que el stream quede completo
+func gzipBody(body []byte) ([]byte, error) {
+	var buf bytes.Buffer
+	zw := gzipWriters.Get().(*gzip.Writer)
+	defer gzipWriters.Put(zw)
+	zw.Reset(&buf)
+	if _, err := zw.Write(body); err != nil {
+		return nil, fmt.Errorf("error al comprimir el body: %w", err)
+	}
+	if err := zw.Close(); err != nil {
+		return nil, fmt.Errorf("error al comprimir el body: %w", err)
+	}
+	return buf.Bytes(), nil
+}
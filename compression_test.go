@@ -0,0 +1,127 @@
+package opentelemetryexportermonitoring
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"sync"
+	"testing"
+)
+
+func TestGzipBodyConcurrent(t *testing.T) {
+	const workers, rounds = 16, 50
+	var wg sync.WaitGroup
+	errs := make(chan error, workers)
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func(w int) {
+			defer wg.Done()
+			for r := 0; r < rounds; r++ {
+				// Cada body es distinto: un writer compartido mezclaría streams
+				body := bytes.Repeat([]byte(fmt.Sprintf(`{"worker":%d,"round":%d}`, w, r)), 10+w)
+				compressed, err := gzipBody(body)
+				if err != nil {
+					errs <- err
+					return
+				}
+				zr, err := gzip.NewReader(bytes.NewReader(compressed))
+				if err != nil {
+					errs <- err
+					return
+				}
+				out, err := io.ReadAll(zr)
+				if err != nil {
+					errs <- err
+					return
+				}
+				if !bytes.Equal(out, body) {
+					errs <- fmt.Errorf("worker %d, ronda %d: el body descomprimido no coincide", w, r)
+					return
+				}
+			}
+		}(w)
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		t.Error(err)
+	}
+}
+
+// benchmarkBody es un payload JSON típico de unos 16 KB
+var benchmarkBody = bytes.Repeat([]byte(`{"name":"http.server.duration","values":{"count":12},"properties":{"service_name":"svc"}},`), 180)
+
+func BenchmarkGzipBodyPooled(b *testing.B) {
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		if _, err := gzipBody(benchmarkBody); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+// BenchmarkGzipBodyUnpooled es la referencia: un gzip.Writer nuevo por body
+func BenchmarkGzipBodyUnpooled(b *testing.B) {
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		var buf bytes.Buffer
+		zw := gzip.NewWriter(&buf)
+		if _, err := zw.Write(benchmarkBody); err != nil {
+			b.Fatal(err)
+		}
+		if err := zw.Close(); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+// gunzip descomprime un body gzip y falla el test si no lo es
+func gunzip(t *testing.T, data []byte) []byte {
+	t.Helper()
+	zr, err := gzip.NewReader(bytes.NewReader(data))
+	if err != nil {
+		t.Fatalf("el body no es gzip: %v", err)
+	}
+	out, err := io.ReadAll(zr)
+	if err != nil {
+		t.Fatalf("gzip inválido: %v", err)
+	}
+	return out
+}
+
+func TestGzipCompressionRoundTrip(t *testing.T) {
+	post := func(compression string) (capturedRequest, int64) {
+		srv := newCaptureServer(t)
+		var contentLength int64
+		srv.respond(func(w http.ResponseWriter, r *http.Request) { contentLength = r.ContentLength })
+		cfg := testConfig(t, srv.URL)
+		cfg.Compression = compression
+		if err := newTestExporter(t, cfg).pushMetrics(context.Background(), gaugeMetrics("m")); err != nil {
+			t.Fatal(err)
+		}
+		reqs := srv.received()
+		if len(reqs) != 1 {
+			t.Fatalf("peticiones = %d, se esperaba 1", len(reqs))
+		}
+		return reqs[0], contentLength
+	}
+
+	plain, _ := post(compressionNone)
+	if got := plain.Header.Get("Content-Encoding"); got != "" {
+		t.Errorf("sin compresión Content-Encoding = %q", got)
+	}
+
+	compressed, contentLength := post(compressionGzip)
+	if got := compressed.Header.Get("Content-Encoding"); got != "gzip" {
+		t.Errorf("Content-Encoding = %q, se esperaba gzip", got)
+	}
+	if contentLength != int64(len(compressed.Body)) {
+		t.Errorf("Content-Length = %d, el body comprimido mide %d", contentLength, len(compressed.Body))
+	}
+	if got := gunzip(t, compressed.Body); !bytes.Equal(got, plain.Body) {
+		t.Errorf("el body descomprimido no coincide:\n%s\n%s", got, plain.Body)
+	}
+}
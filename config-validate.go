@@ -0,0 +1,304 @@
+package opentelemetryexportermonitoring
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+	"time"
+
+	"go.opentelemetry.io/collector/confmap/xconfmap"
+)
+
+var _ xconfmap.Validator = (*Config)(nil)
+
+// Validate comprueba la configuración al cargarla, antes de crear el exporter
+func (c *Config) Validate() error {
+	templates := []struct{ field, tmpl string }{
+		{"traces_url_template", c.TracesURLTemplate},
+		{"metrics_url_template", c.MetricsURLTemplate},
+		{"logs_url_template", c.LogsURLTemplate},
+	}
+
+	// Hace falta algún destino: las URLs por defecto (region + ns) o una explícita
+	hasTemplate := false
+	for _, t := range templates {
+		hasTemplate = hasTemplate || t.tmpl != ""
+	}
+	if (c.Region == "" || c.NS == "") && !hasTemplate && c.CombinedEndpoint == "" && len(c.Sinks) == 0 {
+		return errors.New("se necesita region y ns, alguna *_url_template, combined_endpoint o sinks")
+	}
+
+	if c.EndpointFromAttribute != "" && !hasTemplate {
+		return errors.New("endpoint_from_attribute necesita alguna *_url_template")
+	}
+	if c.EndpointFromAttribute != "" && (c.Region == "" || c.NS == "") {
+		return errors.New("endpoint_from_attribute necesita region y ns para la URL por defecto")
+	}
+
+	for _, t := range templates {
+		if t.tmpl == "" {
+			continue
+		}
+		// Los marcadores se sustituyen para validar el resto de la URL
+		if err := validateHTTPURL(urlPlaceholder.ReplaceAllString(t.tmpl, "x")); err != nil {
+			return fmt.Errorf("%s: %w", t.field, err)
+		}
+	}
+	if c.CombinedEndpoint != "" {
+		if err := validateHTTPURL(c.CombinedEndpoint); err != nil {
+			return fmt.Errorf("combined_endpoint: %w", err)
+		}
+	}
+	for i, sink := range c.Sinks {
+		if err := validateHTTPURL(urlPlaceholder.ReplaceAllString(sink.Endpoint, "x")); err != nil {
+			return fmt.Errorf("sinks[%d].endpoint: %w", i, err)
+		}
+		if sink.Encoding != "" && !validEncoding("traces", sink.Encoding) && !validEncoding("metrics", sink.Encoding) && !validEncoding("logs", sink.Encoding) {
+			return fmt.Errorf("sinks[%d].encoding: inválido: %q", i, sink.Encoding)
+		}
+	}
+	if c.MetadataEndpoint != "" {
+		if err := validateHTTPURL(c.MetadataEndpoint); err != nil {
+			return fmt.Errorf("metadata_endpoint: %w", err)
+		}
+	}
+	if c.DependencyEndpoint != "" {
+		if err := validateHTTPURL(c.DependencyEndpoint); err != nil {
+			return fmt.Errorf("dependency_endpoint: %w", err)
+		}
+	}
+	for name, endpoint := range c.MetricEndpointOverrides {
+		if err := validateHTTPURL(endpoint); err != nil {
+			return fmt.Errorf("metric_endpoint_overrides[%s]: %w", name, err)
+		}
+	}
+	for i, route := range c.LogSeverityRoutes {
+		if err := validateHTTPURL(route.Endpoint); err != nil {
+			return fmt.Errorf("log_severity_routes[%d].endpoint: %w", i, err)
+		}
+	}
+
+	methods := []struct{ field, method string }{
+		{"method", c.Method},
+		{"traces_method", c.TracesMethod},
+		{"metrics_method", c.MetricsMethod},
+		{"logs_method", c.LogsMethod},
+	}
+	for _, m := range methods {
+		switch strings.ToUpper(m.method) {
+		case "", http.MethodPost, http.MethodPut, http.MethodPatch:
+		default:
+			return fmt.Errorf("%s: método no soportado: %q (POST, PUT o PATCH)", m.field, m.method)
+		}
+	}
+
+	switch c.TimestampFormat {
+	case "", timestampUnixNano, timestampUnixMillis, timestampRFC3339:
+	default:
+		return fmt.Errorf("timestamp_format: inválido: %q", c.TimestampFormat)
+	}
+	if c.TimestampTimezone != "" {
+		if _, err := time.LoadLocation(c.TimestampTimezone); err != nil {
+			return fmt.Errorf("timestamp_timezone: %w", err)
+		}
+	}
+	if c.SanitizeMetricNames && c.MetricNameAllowedChars != "" {
+		if _, err := regexp.Compile("[^" + c.MetricNameAllowedChars + "]"); err != nil {
+			return fmt.Errorf("metric_name_allowed_chars: %w", err)
+		}
+	}
+	switch c.NonFiniteHandling {
+	case "", "error", "null", "skip", "string":
+	default:
+		return fmt.Errorf("non_finite_handling: inválido: %q", c.NonFiniteHandling)
+	}
+	if len(c.KeyDictionary) > 0 {
+		if c.KeyDictionaryHeader == "" {
+			return errors.New("key_dictionary_header: es obligatorio con key_dictionary")
+		}
+		inverse := make(map[string]string, len(c.KeyDictionary))
+		for k, code := range c.KeyDictionary {
+			if prev, dup := inverse[code]; dup {
+				return fmt.Errorf("key_dictionary: código %q duplicado para %q y %q", code, prev, k)
+			}
+			inverse[code] = k
+		}
+	}
+
+	// Codificaciones por señal
+	switch c.Format {
+	case "", encodingCustom, encodingOTLPJSON, encodingOTLPProto:
+	default:
+		return fmt.Errorf("format: inválido: %q", c.Format)
+	}
+	encodings := []struct{ signal, enc string }{
+		{"traces", c.TracesEncoding},
+		{"metrics", c.MetricsEncoding},
+		{"logs", c.LogsEncoding},
+	}
+	for _, e := range encodings {
+		if !validEncoding(e.signal, e.enc) {
+			return fmt.Errorf("%s_encoding: inválido: %q", e.signal, e.enc)
+		}
+	}
+	if c.FallbackEncoding != "" && !validEncoding("traces", c.FallbackEncoding) &&
+		!validEncoding("metrics", c.FallbackEncoding) && !validEncoding("logs", c.FallbackEncoding) {
+		return fmt.Errorf("fallback_encoding: inválido: %q", c.FallbackEncoding)
+	}
+	switch c.Compression {
+	case "", compressionNone, compressionGzip:
+	default:
+		return fmt.Errorf("compression: inválido: %q", c.Compression)
+	}
+
+	if c.CombinedEndpoint != "" && c.CombinedWindow <= 0 {
+		return errors.New("combined_window: debe ser positivo con combined_endpoint")
+	}
+	if c.AdaptiveBatchTargetLatency > 0 && c.BatchWindow <= 0 {
+		return errors.New("adaptive_batch_target_latency: requiere batch_window")
+	}
+	if c.AdaptiveBatchTargetLatency > 0 && (c.AdaptiveBatchMinBytes <= 0 || c.AdaptiveBatchMaxBytes < c.AdaptiveBatchMinBytes) {
+		return errors.New("adaptive_batch_min_bytes: debe ser positivo y no mayor que adaptive_batch_max_bytes")
+	}
+	if c.CoalesceWindow > 0 && c.CoalesceMaxBytes < 0 {
+		return errors.New("coalesce_max_bytes: no puede ser negativo")
+	}
+	if c.TraceSampleRate < 0 || c.TraceSampleRate > 1 {
+		return fmt.Errorf("trace_sample_rate: fuera de rango [0, 1]: %v", c.TraceSampleRate)
+	}
+	percentiles := []struct {
+		field  string
+		values []float64
+	}{
+		{"span_latency_percentiles", c.SpanLatencyPercentiles},
+		{"histogram_as_percentiles", c.HistogramAsPercentiles},
+	}
+	for _, p := range percentiles {
+		for _, v := range p.values {
+			if v <= 0 || v > 100 {
+				return fmt.Errorf("%s: percentil fuera de rango (0, 100]: %v", p.field, v)
+			}
+		}
+	}
+	for i, rule := range c.RetryClassification {
+		if rule.Path == "" {
+			return fmt.Errorf("retry_classification[%d].path: es obligatorio", i)
+		}
+	}
+	for i, route := range c.LogSeverityRoutes {
+		if route.Endpoint == "" {
+			return fmt.Errorf("log_severity_routes[%d].endpoint: es obligatorio", i)
+		}
+		if route.MaxSeverity > 0 && route.MaxSeverity < route.MinSeverity {
+			return fmt.Errorf("log_severity_routes[%d].max_severity: menor que min_severity", i)
+		}
+	}
+	switch c.QueueFullPolicy {
+	case "", queueFullBlock, queueFullDropNewest:
+	case "drop_oldest":
+		return errors.New("queue_full_policy: drop_oldest no está soportado por la cola del exporterhelper")
+	default:
+		return fmt.Errorf("queue_full_policy: inválido: %q", c.QueueFullPolicy)
+	}
+
+	if metricRecordFields[c.DataPointAttributesKey] {
+		return fmt.Errorf("data_point_attributes_key: %q coincide con un campo del registro de métrica", c.DataPointAttributesKey)
+	}
+	// timestamp_format baja por estos campos; los atributos no deben estar en uno
+	if timestampContainerKeys[c.DataPointAttributesKey] {
+		return fmt.Errorf("data_point_attributes_key: %q está reservado para registros anidados", c.DataPointAttributesKey)
+	}
+
+	if c.TracesToMetrics {
+		switch enc := c.withFormat().TracesEncoding; enc {
+		case encodingOTLPJSON, encodingOTLPProto, encodingZipkin:
+			return fmt.Errorf("traces_to_metrics: incompatible con traces_encoding %s (solo custom)", enc)
+		}
+	}
+	// Estas opciones envuelven los registros en un objeto; también se aplican
+	// a los sinks, que heredan la configuración principal
+	if c.AggregateLogTemplates || c.DedupLogResources || c.DedupLogAttributes {
+		if c.LogsEncoding == encodingNDJSON {
+			return errors.New("logs_encoding ndjson: incompatible con aggregate_log_templates, dedup_log_resources y dedup_log_attributes")
+		}
+		for i, sink := range c.Sinks {
+			if sink.Encoding == encodingNDJSON && sink.accepts("logs") {
+				return fmt.Errorf("sinks[%d].encoding ndjson: incompatible con aggregate_log_templates, dedup_log_resources y dedup_log_attributes", i)
+			}
+		}
+	}
+	if c.BatchWindow > 0 && c.BatchWindowMaxBytes <= 0 {
+		return fmt.Errorf("batch_window_max_bytes: debe ser mayor que 0 con batch_window (%d)", c.BatchWindowMaxBytes)
+	}
+	if c.ProxyURL != "" {
+		u, err := url.Parse(c.ProxyURL)
+		if err != nil {
+			return fmt.Errorf("proxy_url: URL inválida: %w", err)
+		}
+		switch u.Scheme {
+		case "http", "https", "socks5":
+		default:
+			return fmt.Errorf("proxy_url: esquema no soportado en %q (se espera http, https o socks5)", c.ProxyURL)
+		}
+		if u.Host == "" {
+			return fmt.Errorf("proxy_url: falta el host en %q", c.ProxyURL)
+		}
+	}
+	if c.CircuitBreaker.FailureThreshold > 0 && c.CircuitBreaker.Cooldown <= 0 {
+		return errors.New("circuit_breaker.cooldown: debe ser mayor que 0")
+	}
+
+	if c.Timeout < 0 {
+		return fmt.Errorf("timeout: no puede ser negativo (%s)", c.Timeout)
+	}
+	headers := []struct {
+		field string
+		h     map[string]string
+	}{
+		{"headers", c.Headers},
+		{"traces_headers", c.TracesHeaders},
+		{"metrics_headers", c.MetricsHeaders},
+		{"logs_headers", c.LogsHeaders},
+	}
+	for _, h := range headers {
+		for k := range h.h {
+			if k == "" {
+				return fmt.Errorf("%s: clave vacía", h.field)
+			}
+		}
+	}
+	if err := c.Auth.validate(); err != nil {
+		return err
+	}
+	return c.Signing.validate()
+}
+
+// metricRecordFields son las claves JSON de transformedMetric; los atributos
+// del punto emitidos con el mismo nombre duplicarían la clave
+var metricRecordFields = map[string]bool{
+	"timestamp":   true,
+	"properties":  true,
+	"values":      true,
+	"resource":    true,
+	"exemplars":   true,
+	"series_hash": true,
+	"scope":       true,
+}
+
+// validateHTTPURL exige una URL absoluta http o https
+func validateHTTPURL(raw string) error {
+	u, err := url.Parse(raw)
+	if err != nil {
+		return fmt.Errorf("URL inválida: %w", err)
+	}
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return fmt.Errorf("esquema no soportado en %q (se espera http o https)", raw)
+	}
+	if u.Host == "" {
+		return fmt.Errorf("falta el host en %q", raw)
+	}
+	return nil
+}
@@ -0,0 +1,89 @@
+package opentelemetryexportermonitoring
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+// validateError valida cfg y comprueba que el error nombra field
+func validateError(t *testing.T, cfg *Config, field string) {
+	t.Helper()
+	err := cfg.Validate()
+	if err == nil {
+		t.Fatalf("se esperaba un error de %s", field)
+	}
+	if !strings.Contains(err.Error(), field) {
+		t.Errorf("el error %q no nombra el campo %s", err, field)
+	}
+}
+
+func TestValidateDefaultConfig(t *testing.T) {
+	if err := createDefaultConfig().(*Config).Validate(); err != nil {
+		t.Fatalf("la config por defecto no valida: %v", err)
+	}
+}
+
+func TestValidateDataPointAttributesKey(t *testing.T) {
+	for _, key := range []string{"values", "properties", "timestamp", "resource", "events"} {
+		t.Run(key, func(t *testing.T) {
+			cfg := testConfig(t, "http://localhost")
+			cfg.DataPointAttributesKey = key
+			validateError(t, cfg, "data_point_attributes_key")
+		})
+	}
+	for _, key := range []string{"", "attributes", "labels"} {
+		cfg := testConfig(t, "http://localhost")
+		cfg.DataPointAttributesKey = key
+		if err := cfg.Validate(); err != nil {
+			t.Errorf("data_point_attributes_key %q: %v", key, err)
+		}
+	}
+}
+
+func TestValidateNamesField(t *testing.T) {
+	tests := []struct {
+		field  string
+		mutate func(*Config)
+	}{
+		{"timestamp_format", func(c *Config) { c.TimestampFormat = "iso" }},
+		{"metric_name_allowed_chars", func(c *Config) { c.SanitizeMetricNames = true; c.MetricNameAllowedChars = "a-" + "\\" }},
+		{"key_dictionary_header", func(c *Config) { c.KeyDictionary = map[string]string{"a": "1"}; c.KeyDictionaryHeader = "" }},
+		{"key_dictionary", func(c *Config) {
+			c.KeyDictionary = map[string]string{"a": "1", "b": "1"}
+			c.KeyDictionaryHeader = "X-Keys"
+		}},
+		{"format", func(c *Config) { c.Format = "xml" }},
+		{"traces_encoding", func(c *Config) { c.TracesEncoding = encodingLoki }},
+		{"metrics_encoding", func(c *Config) { c.MetricsEncoding = encodingZipkin }},
+		{"logs_encoding", func(c *Config) { c.LogsEncoding = encodingPromRW }},
+		{"fallback_encoding", func(c *Config) { c.FallbackEncoding = "xml" }},
+		{"compression", func(c *Config) { c.Compression = "zstd" }},
+		{"combined_window", func(c *Config) { c.CombinedEndpoint = "http://localhost/all"; c.CombinedWindow = 0 }},
+		{"adaptive_batch_min_bytes", func(c *Config) {
+			c.AdaptiveBatchTargetLatency = 1
+			c.BatchWindow = time.Second
+			c.AdaptiveBatchMinBytes = 10
+			c.AdaptiveBatchMaxBytes = 5
+		}},
+		{"adaptive_batch_target_latency", func(c *Config) { c.AdaptiveBatchTargetLatency = time.Second }},
+		{"coalesce_max_bytes", func(c *Config) { c.CoalesceWindow = 1; c.CoalesceMaxBytes = -1 }},
+		{"trace_sample_rate", func(c *Config) { c.TraceSampleRate = 1.5 }},
+		{"span_latency_percentiles", func(c *Config) { c.SpanLatencyPercentiles = []float64{0} }},
+		{"histogram_as_percentiles", func(c *Config) { c.HistogramAsPercentiles = []float64{101} }},
+		{"retry_classification[0].path", func(c *Config) { c.RetryClassification = []RetryClassificationRule{{}} }},
+		{"log_severity_routes[0].max_severity", func(c *Config) {
+			c.LogSeverityRoutes = []LogSeverityRoute{{MinSeverity: 17, MaxSeverity: 9, Endpoint: "http://localhost/x"}}
+		}},
+		{"timeout", func(c *Config) { c.Timeout = -1 }},
+		{"metrics_method", func(c *Config) { c.MetricsMethod = "GET" }},
+		{"proxy_url", func(c *Config) { c.ProxyURL = "ftp://proxy:21" }},
+	}
+	for _, tt := range tests {
+		t.Run(tt.field, func(t *testing.T) {
+			cfg := testConfig(t, "http://localhost")
+			tt.mutate(cfg)
+			validateError(t, cfg, tt.field)
+		})
+	}
+}
@@ -0,0 +1,44 @@
+package opentelemetryexportermonitoring
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+)
+
+// contentDigest devuelve el SHA-256 (hex) del payload canonicalizado: el JSON
+// se pasa a la forma de canonicalizeJSON sin los batchId, que cambian en cada
+// reintento. Así el digest es el mismo para los mismos datos aunque varíe el
+// orden de los mapas. Los payloads que no son JSON se resumen tal cual
+func contentDigest(body []byte, isJSON bool) string {
+	canonical := body
+	if isJSON {
+		if v, err := decodeJSONNumbers(body); err == nil {
+			if c, err := marshalCanonical(stripBatchID(v)); err == nil {
+				canonical = c
+			}
+		}
+	}
+	sum := sha256.Sum256(canonical)
+	return hex.EncodeToString(sum[:])
+}
+
+// stripBatchID quita "batchId" del objeto raíz y de los objetos de sus
+// arrays (spans, logs o el array "metrics")
+func stripBatchID(v interface{}) interface{} {
+	switch t := v.(type) {
+	case map[string]interface{}:
+		delete(t, "batchId")
+		for _, child := range t {
+			if arr, ok := child.([]interface{}); ok {
+				stripBatchID(arr)
+			}
+		}
+	case []interface{}:
+		for _, item := range t {
+			if obj, ok := item.(map[string]interface{}); ok {
+				delete(obj, "batchId")
+			}
+		}
+	}
+	return v
+}
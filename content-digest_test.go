@@ -0,0 +1,75 @@
+package opentelemetryexportermonitoring
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"testing"
+)
+
+func TestContentDigestStable(t *testing.T) {
+	// Los mismos datos con otro orden de claves, espacios y batchId
+	a := []byte(`{"batchId":"1","metrics":[{"values":{"a":1,"b":2.5},"properties":{"x":"y"},"batchId":"1"}]}`)
+	b := []byte(`{ "metrics": [ {"properties": {"x": "y"}, "batchId": "2", "values": {"b": 2.5, "a": 1}} ], "batchId": "2" }`)
+	if contentDigest(a, true) != contentDigest(b, true) {
+		t.Error("dos codificaciones de los mismos datos tienen digests distintos")
+	}
+	if len(contentDigest(a, true)) != 64 {
+		t.Errorf("digest = %q, se esperaba SHA-256 en hex", contentDigest(a, true))
+	}
+
+	c := []byte(`{"metrics":[{"values":{"a":1,"b":2.6},"properties":{"x":"y"}}]}`)
+	if contentDigest(a, true) == contentDigest(c, true) {
+		t.Error("datos distintos comparten digest")
+	}
+	// Un payload binario se resume tal cual
+	if contentDigest(a, false) == contentDigest(b, false) {
+		t.Error("sin JSON los bytes distintos deben dar digests distintos")
+	}
+}
+
+func TestContentDigestUsesCanonicalForm(t *testing.T) {
+	// Sin batchId el digest es el SHA-256 de la forma de canonicalizeJSON,
+	// también con HTML y decimales que se podrían serializar de otra manera
+	body := []byte(`{"logs":[{"message":"<a href=\"x\">&</a>","v":1.50,"n":9007199254740993}]}`)
+	canonical, err := canonicalizeJSON(body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sum := sha256.Sum256(canonical)
+	if got, want := contentDigest(body, true), hex.EncodeToString(sum[:]); got != want {
+		t.Errorf("digest = %s, se esperaba el de la forma canónica %s (%s)", got, want, canonical)
+	}
+}
+
+func TestContentDigestHeaderStableAcrossRetries(t *testing.T) {
+	srv := newCaptureServer(t)
+	srv.respond(failFirst(1, "/metrics"))
+	cfg := testConfig(t, srv.URL)
+	cfg.ContentDigestHeader = "X-Content-Digest"
+	cfg.BatchIDHeader = "X-Batch-Id"
+	fastRetries(cfg)
+	_, metrics, _ := newFactoryExporters(t, cfg)
+
+	ctx := context.Background()
+	if err := metrics.ConsumeMetrics(ctx, gaugeMetrics("a")); err != nil {
+		t.Fatal(err)
+	}
+	if err := metrics.ConsumeMetrics(ctx, gaugeMetrics("a")); err != nil {
+		t.Fatal(err)
+	}
+	reqs := srv.received()
+	if len(reqs) != 3 {
+		t.Fatalf("peticiones = %d, se esperaban 3 (un reintento)", len(reqs))
+	}
+	digest := reqs[0].Header.Get("X-Content-Digest")
+	if digest == "" {
+		t.Fatal("falta la cabecera del digest")
+	}
+	for i, req := range reqs {
+		// El reintento y el lote duplicado llevan el mismo digest aunque cambie el batchId
+		if got := req.Header.Get("X-Content-Digest"); got != digest {
+			t.Errorf("petición %d: digest %s, se esperaba %s", i, got, digest)
+		}
+	}
+}
@@ -0,0 +1,65 @@
+package opentelemetryexportermonitoring
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"time"
+)
+
+// boundedDialer resuelve los nombres con un máximo de resoluciones DNS
+// concurrentes, para que una caída del DNS no bloquee goroutines (y
+// descriptores) sin límite
+type boundedDialer struct {
+	dialer *net.Dialer
+	lookup func(ctx context.Context, host string) ([]net.IPAddr, error)
+	sem    chan struct{}
+}
+
+func newBoundedDialer(maxLookups int) *boundedDialer {
+	return &boundedDialer{
+		dialer: &net.Dialer{
+			Timeout:   30 * time.Second,
+			KeepAlive: 30 * time.Second,
+		},
+		lookup: net.DefaultResolver.LookupIPAddr,
+		sem:    make(chan struct{}, maxLookups),
+	}
+}
+
+// DialContext resuelve el host respetando el límite y conecta con la primera
+// IP que responda
+func (d *boundedDialer) DialContext(ctx context.Context, network, addr string) (net.Conn, error) {
+	host, port, err := net.SplitHostPort(addr)
+	if err != nil {
+		return nil, err
+	}
+	if net.ParseIP(host) != nil {
+		return d.dialer.DialContext(ctx, network, addr)
+	}
+
+	select {
+	case d.sem <- struct{}{}:
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+	ips, err := d.lookup(ctx, host)
+	<-d.sem
+	if err != nil {
+		return nil, err
+	}
+	if len(ips) == 0 {
+		return nil, fmt.Errorf("sin direcciones para %s", host)
+	}
+
+	var errs []error
+	for _, ip := range ips {
+		conn, err := d.dialer.DialContext(ctx, network, net.JoinHostPort(ip.String(), port))
+		if err == nil {
+			return conn, nil
+		}
+		errs = append(errs, err)
+	}
+	return nil, errors.Join(errs...)
+}
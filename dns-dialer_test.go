@@ -0,0 +1,133 @@
+package opentelemetryexportermonitoring
+
+import (
+	"context"
+	"errors"
+	"net"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// slowResolver es un resolver de prueba que tarda delay en cada consulta y
+// anota el máximo de consultas simultáneas
+type slowResolver struct {
+	delay    time.Duration
+	inFlight atomic.Int32
+	max      atomic.Int32
+	calls    atomic.Int32
+}
+
+func (r *slowResolver) lookup(ctx context.Context, host string) ([]net.IPAddr, error) {
+	r.calls.Add(1)
+	n := r.inFlight.Add(1)
+	defer r.inFlight.Add(-1)
+	for {
+		prev := r.max.Load()
+		if n <= prev || r.max.CompareAndSwap(prev, n) {
+			break
+		}
+	}
+	select {
+	case <-time.After(r.delay):
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+	return []net.IPAddr{{IP: net.IPv4(127, 0, 0, 1)}}, nil
+}
+
+func TestBoundedDialerLimitsLookups(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ln.Close()
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			conn.Close()
+		}
+	}()
+	_, port, _ := net.SplitHostPort(ln.Addr().String())
+
+	resolver := &slowResolver{delay: 20 * time.Millisecond}
+	d := newBoundedDialer(2)
+	d.lookup = resolver.lookup
+
+	var wg sync.WaitGroup
+	errs := make(chan error, 8)
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			conn, err := d.DialContext(context.Background(), "tcp", net.JoinHostPort("slow.test", port))
+			if err != nil {
+				errs <- err
+				return
+			}
+			conn.Close()
+		}()
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		t.Error(err)
+	}
+	if got := resolver.max.Load(); got > 2 {
+		t.Errorf("%d resoluciones simultáneas, el límite es 2", got)
+	}
+	if got := resolver.calls.Load(); got != 8 {
+		t.Errorf("%d resoluciones, se esperaban 8", got)
+	}
+}
+
+func TestBoundedDialerWaitHonoursContext(t *testing.T) {
+	resolver := &slowResolver{delay: time.Second}
+	d := newBoundedDialer(1)
+	d.lookup = resolver.lookup
+
+	// Ocupa el único hueco con una resolución lenta
+	busy, cancelBusy := context.WithCancel(context.Background())
+	defer cancelBusy()
+	go d.DialContext(busy, "tcp", "slow.test:80")
+	for resolver.inFlight.Load() == 0 {
+		time.Sleep(time.Millisecond)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	_, err := d.DialContext(ctx, "tcp", "slow.test:80")
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("err = %v, se esperaba el plazo del contexto", err)
+	}
+	if elapsed := time.Since(start); elapsed > 500*time.Millisecond {
+		t.Errorf("la espera duró %s, no respetó el contexto", elapsed)
+	}
+	if got := resolver.calls.Load(); got != 1 {
+		t.Errorf("%d resoluciones, la segunda no debía llegar al resolver", got)
+	}
+}
+
+func TestBoundedDialerSkipsLookupForIP(t *testing.T) {
+	resolver := &slowResolver{}
+	d := newBoundedDialer(1)
+	d.lookup = resolver.lookup
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ln.Close()
+	conn, err := d.DialContext(context.Background(), "tcp", ln.Addr().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	conn.Close()
+	if resolver.calls.Load() != 0 {
+		t.Error("una IP literal no debe resolverse")
+	}
+}
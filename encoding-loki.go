@@ -0,0 +1,143 @@
+package opentelemetryexportermonitoring
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	"go.opentelemetry.io/collector/pdata/plog"
+)
+
+// lokiStream es un stream del payload de push de Loki
+type lokiStream struct {
+	Stream map[string]string `json:"stream"`
+	Values [][2]string       `json:"values"`
+}
+
+// lokiEntry es una línea de un stream; max_payload_bytes parte por líneas
+type lokiEntry struct {
+	stream *lokiStream
+	value  [2]string
+}
+
+// encodeLokiEntries reagrupa las líneas en sus streams, en orden, y
+// serializa el payload de push
+func encodeLokiEntries(entries []lokiEntry) ([]byte, error) {
+	var streams []*lokiStream
+	index := make(map[*lokiStream]*lokiStream)
+	for _, e := range entries {
+		out, ok := index[e.stream]
+		if !ok {
+			out = &lokiStream{Stream: e.stream.Stream}
+			index[e.stream] = out
+			streams = append(streams, out)
+		}
+		out.Values = append(out.Values, e.value)
+	}
+	return json.Marshal(map[string]interface{}{"streams": streams})
+}
+
+// lokiLabels devuelve las etiquetas del stream: cada clave de loki_labels se
+// busca primero en el registro y después en el resource; "level" usa además
+// el severity text como último recurso. Los atributos pasan por
+// attribute_allowlist/denylist y redact_attributes como en properties
+func (m *monitoringExporter) lokiLabels(record plog.LogRecord, resAttrs pcommon.Map) map[string]string {
+	labels := make(map[string]string, len(m.lokiLabelKeys))
+	for _, key := range m.lokiLabelKeys {
+		var value string
+		if m.attrAllowed(key) {
+			value = getAttrString(record.Attributes(), key)
+			if value == "" {
+				value = getAttrString(resAttrs, key)
+			}
+			if value != "" {
+				value = m.redactString(key, value)
+			}
+		}
+		if value == "" && key == "level" {
+			value = severityText(record)
+		}
+		if value != "" {
+			labels[promLabelName(m.attrKey(key))] = value
+		}
+	}
+	return labels
+}
+
+// transformLogsLoki agrupa los registros por URL y por conjunto de etiquetas
+func (m *monitoringExporter) transformLogsLoki(ld plog.Logs) (map[string][]*lokiStream, []string, error) {
+	groups := make(map[string][]*lokiStream)
+	streamIndex := make(map[string]*lokiStream)
+	var urls []string
+
+	rls := ld.ResourceLogs()
+	for i := 0; i < rls.Len(); i++ {
+		resAttrs := rls.At(i).Resource().Attributes()
+		url := m.logsResourceURL(resAttrs)
+		sls := rls.At(i).ScopeLogs()
+		for j := 0; j < sls.Len(); j++ {
+			records := sls.At(j).LogRecords()
+			for k := 0; k < records.Len(); k++ {
+				record := records.At(k)
+				labels := m.lokiLabels(record, resAttrs)
+				// json.Marshal ordena las claves: misma clave para las mismas etiquetas
+				labelsKey, err := json.Marshal(labels)
+				if err != nil {
+					return nil, nil, err
+				}
+				key := url + "\x00" + string(labelsKey)
+				stream, ok := streamIndex[key]
+				if !ok {
+					if _, seen := groups[url]; !seen {
+						urls = append(urls, url)
+					}
+					stream = &lokiStream{Stream: labels}
+					streamIndex[key] = stream
+					groups[url] = append(groups[url], stream)
+				}
+
+				ts := record.Timestamp()
+				if ts == 0 {
+					ts = record.ObservedTimestamp()
+				}
+				line, _ := m.truncateLogBody(record.Body().AsString())
+				stream.Values = append(stream.Values, [2]string{strconv.FormatInt(int64(ts), 10), line})
+			}
+		}
+	}
+	return groups, urls, nil
+}
+
+// pushLogsLoki envía los logs en el formato de push de Loki
+func (m *monitoringExporter) pushLogsLoki(ctx context.Context, ld plog.Logs) error {
+	if !m.logs {
+		m.logger.Sugar().Warnln("El envío de logs está deshabilitado, no se realizará el POST.")
+		return nil
+	}
+
+	groups, urls, err := m.transformLogsLoki(ld)
+	if err != nil {
+		return err
+	}
+	ctx, _ = m.withBatchID(ctx)
+	for _, url := range urls {
+		var entries []lokiEntry
+		for _, stream := range groups[url] {
+			for _, value := range stream.Values {
+				entries = append(entries, lokiEntry{stream: stream, value: value})
+			}
+		}
+		bodies, err := splitPayload(m, entries, encodeLokiEntries)
+		if err != nil {
+			return fmt.Errorf("error marshaling Loki logs for URL %s: %w", url, err)
+		}
+		for _, body := range bodies {
+			if err := m.postJSON(ctx, url, body); err != nil {
+				return fmt.Errorf("error sending data to URL %s: %w", url, err)
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,121 @@
+package opentelemetryexportermonitoring
+
+import (
+	"context"
+	"testing"
+
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	"go.opentelemetry.io/collector/pdata/plog"
+)
+
+// lokiPayload es el body de push de Loki tal como lo recibe el servidor
+type lokiPayload struct {
+	Streams []struct {
+		Stream map[string]string `json:"stream"`
+		Values [][]string        `json:"values"`
+	} `json:"streams"`
+}
+
+func TestLokiPayloadShape(t *testing.T) {
+	ld := logsWithResources(0, "api", "worker")
+	api := ld.ResourceLogs().At(0).ScopeLogs().At(0).LogRecords()
+	for i, severity := range []plog.SeverityNumber{plog.SeverityNumberInfo, plog.SeverityNumberError, plog.SeverityNumberInfo} {
+		lr := api.AppendEmpty()
+		lr.SetTimestamp(pcommon.Timestamp(i+1) * 1_000_000_000)
+		lr.SetSeverityNumber(severity)
+		lr.Body().SetStr("api log")
+	}
+	worker := ld.ResourceLogs().At(1).ScopeLogs().At(0).LogRecords().AppendEmpty()
+	worker.SetObservedTimestamp(5_000_000_000) // sin timestamp: se usa el observado
+	worker.Attributes().PutStr("level", "debug")
+	worker.Body().SetStr("worker log")
+
+	srv := newCaptureServer(t)
+	cfg := testConfig(t, srv.URL)
+	cfg.LogsEncoding = encodingLoki
+	if err := newTestExporter(t, cfg).pushLogsLoki(context.Background(), ld); err != nil {
+		t.Fatal(err)
+	}
+	reqs := srv.received()
+	if len(reqs) != 1 || reqs[0].Header.Get("Content-Type") != "application/json" {
+		t.Fatalf("peticiones = %d, se esperaba un POST JSON", len(reqs))
+	}
+	var payload lokiPayload
+	decodeJSON(t, reqs[0].Body, &payload)
+
+	// Un stream por conjunto de etiquetas, en orden de aparición
+	want := []struct {
+		labels map[string]string
+		values [][]string
+	}{
+		{map[string]string{"service_name": "api", "level": "INFO"}, [][]string{{"1000000000", "api log"}, {"3000000000", "api log"}}},
+		{map[string]string{"service_name": "api", "level": "ERROR"}, [][]string{{"2000000000", "api log"}}},
+		{map[string]string{"service_name": "worker", "level": "debug"}, [][]string{{"5000000000", "worker log"}}},
+	}
+	if len(payload.Streams) != len(want) {
+		t.Fatalf("%d streams, se esperaban %d: %+v", len(payload.Streams), len(want), payload.Streams)
+	}
+	for i, w := range want {
+		got := payload.Streams[i]
+		if len(got.Stream) != len(w.labels) {
+			t.Errorf("stream %d: etiquetas %v, se esperaba %v", i, got.Stream, w.labels)
+		}
+		for k, v := range w.labels {
+			if got.Stream[k] != v {
+				t.Errorf("stream %d: %s = %q, se esperaba %q", i, k, got.Stream[k], v)
+			}
+		}
+		if len(got.Values) != len(w.values) {
+			t.Fatalf("stream %d: values %v, se esperaba %v", i, got.Values, w.values)
+		}
+		for j := range w.values {
+			if len(got.Values[j]) != 2 || got.Values[j][0] != w.values[j][0] || got.Values[j][1] != w.values[j][1] {
+				t.Errorf("stream %d: value %d = %v, se esperaba %v", i, j, got.Values[j], w.values[j])
+			}
+		}
+	}
+}
+
+func TestLokiLabelsFilteredAndRedacted(t *testing.T) {
+	tests := []struct {
+		name      string
+		allowlist []string
+		denylist  []string
+		redact    []string
+		want      map[string]string
+	}{
+		{"sin listas", nil, nil, nil, map[string]string{"service_name": "api", "env": "prod", "level": "INFO"}},
+		{"denylist", nil, []string{"env"}, nil, map[string]string{"service_name": "api", "level": "INFO"}},
+		{"gana la allowlist", []string{"service.name"}, []string{"service.name", "env"}, nil, map[string]string{"service_name": "api", "level": "INFO"}},
+		{"redactado", nil, nil, []string{"env"}, map[string]string{"service_name": "api", "env": "REDACTED", "level": "INFO"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ld := logsWithResources(1, "api")
+			ld.ResourceLogs().At(0).Resource().Attributes().PutStr("env", "prod")
+			ld.ResourceLogs().At(0).ScopeLogs().At(0).LogRecords().At(0).SetSeverityNumber(plog.SeverityNumberInfo)
+
+			srv := newCaptureServer(t)
+			cfg := testConfig(t, srv.URL)
+			cfg.LogsEncoding = encodingLoki
+			cfg.LokiLabels = []string{"service.name", "env", "level"}
+			cfg.AttributeAllowlist = tt.allowlist
+			cfg.AttributeDenylist = tt.denylist
+			cfg.RedactAttributes = tt.redact
+			if err := newTestExporter(t, cfg).pushLogsLoki(context.Background(), ld); err != nil {
+				t.Fatal(err)
+			}
+			var payload lokiPayload
+			decodeJSON(t, srv.received()[0].Body, &payload)
+			got := payload.Streams[0].Stream
+			if len(got) != len(tt.want) {
+				t.Fatalf("etiquetas = %v, se esperaba %v", got, tt.want)
+			}
+			for k, v := range tt.want {
+				if got[k] != v {
+					t.Errorf("%s = %q, se esperaba %q", k, got[k], v)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,38 @@
+package opentelemetryexportermonitoring
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+
+	"go.opentelemetry.io/collector/pdata/plog"
+)
+
+// ndjsonHeaders son las cabeceras de contenido de los payloads NDJSON
+var ndjsonHeaders = http.Header{"Content-Type": {"application/x-ndjson"}}
+
+// pushLogsNDJSON envía los logs en el formato propio, un registro por línea
+func (m *monitoringExporter) pushLogsNDJSON(ctx context.Context, ld plog.Logs) error {
+	return m.pushLogsWith(ctx, ld, m.encodeLogsNDJSON, m.postNDJSON)
+}
+
+// encodeLogsNDJSON serializa cada registro en su propia línea, terminada en
+// "\n", sin objeto ni array que los envuelva
+func (m *monitoringExporter) encodeLogsNDJSON(logs []transformedLog) ([]byte, error) {
+	var buf bytes.Buffer
+	for i := range logs {
+		line, err := m.marshalRecords(logs[i])
+		if err != nil {
+			return nil, err
+		}
+		buf.Write(line)
+		buf.WriteByte('\n')
+	}
+	return buf.Bytes(), nil
+}
+
+// postNDJSON envía un payload NDJSON; no pasa por el coalescer ni por el
+// modo canónico, que trabajan sobre un único documento JSON
+func (m *monitoringExporter) postNDJSON(ctx context.Context, url string, body []byte) error {
+	return m.postPayload(ctx, url, body, ndjsonHeaders)
+}
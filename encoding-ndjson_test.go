@@ -0,0 +1,64 @@
+package opentelemetryexportermonitoring
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"testing"
+)
+
+func TestNDJSONOneRecordPerLine(t *testing.T) {
+	srv := newCaptureServer(t)
+	cfg := testConfig(t, srv.URL)
+	cfg.LogsEncoding = encodingNDJSON
+	ld := logsWithResources(3, "a", "b")
+	if err := newTestExporter(t, cfg).pushLogsNDJSON(context.Background(), ld); err != nil {
+		t.Fatal(err)
+	}
+	reqs := srv.received()
+	if len(reqs) != 1 {
+		t.Fatalf("peticiones = %d, se esperaba 1", len(reqs))
+	}
+	if got := reqs[0].Header.Get("Content-Type"); got != "application/x-ndjson" {
+		t.Errorf("Content-Type = %q, se esperaba application/x-ndjson", got)
+	}
+	body := reqs[0].Body
+	if !bytes.HasSuffix(body, []byte("\n")) {
+		t.Error("la última línea debe terminar en \\n")
+	}
+	lines := bytes.Split(bytes.TrimSuffix(body, []byte("\n")), []byte("\n"))
+	if want := ld.LogRecordCount(); len(lines) != want {
+		t.Fatalf("líneas = %d, se esperaban %d (una por registro):\n%s", len(lines), want, body)
+	}
+	for i, line := range lines {
+		var record map[string]interface{}
+		if err := json.Unmarshal(line, &record); err != nil {
+			t.Fatalf("línea %d no es JSON: %v: %s", i, err, line)
+		}
+		if record["message"] == nil {
+			t.Errorf("línea %d no es un registro de log: %s", i, line)
+		}
+	}
+}
+
+func TestNDJSONIncompatibleOptions(t *testing.T) {
+	cfg := testConfig(t, "http://localhost")
+	cfg.LogsEncoding = encodingNDJSON
+	cfg.DedupLogResources = true
+	validateError(t, cfg, "logs_encoding")
+
+	// Los sinks heredan aggregate_log_templates de la configuración principal
+	cfg = testConfig(t, "http://localhost")
+	cfg.AggregateLogTemplates = true
+	cfg.Sinks = []SinkConfig{
+		{Endpoint: "http://localhost/a"},
+		{Endpoint: "http://localhost/b", Encoding: encodingNDJSON},
+	}
+	validateError(t, cfg, "sinks[1].encoding")
+
+	// Un sink que no recibe logs no usa ndjson
+	cfg.Sinks[1].Signals = []string{"metrics"}
+	if err := cfg.Validate(); err != nil {
+		t.Errorf("sink ndjson sin logs: %v", err)
+	}
+}
@@ -0,0 +1,293 @@
+package opentelemetryexportermonitoring
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	"go.opentelemetry.io/collector/pdata/plog"
+	"go.opentelemetry.io/collector/pdata/pmetric"
+	"go.opentelemetry.io/collector/pdata/ptrace"
+)
+
+// Codificaciones de payload disponibles por señal
+const (
+	encodingCustom    = "custom"                  // formato Atenea resumido (por defecto)
+	encodingOTLPJSON  = "otlp_json"               // OTLP/JSON estándar de pdata
+	encodingOTLPProto = "otlp_proto"              // OTLP/protobuf binario de pdata
+	encodingPromRW    = "prometheus_remote_write" // solo métricas
+	encodingLoki      = "loki"                    // solo logs
+	encodingNDJSON    = "ndjson"                  // solo logs (formato propio, un registro por línea)
+	encodingZipkin    = "zipkin_json"             // solo trazas (Zipkin JSON v2)
+)
+
+// withFormat aplica format a las señales que usan la codificación propia
+// (valor por defecto de *_encoding); devuelve c si no hay nada que cambiar
+func (c *Config) withFormat() *Config {
+	if c.Format == "" || c.Format == encodingCustom {
+		return c
+	}
+	out := *c
+	for _, enc := range []*string{&out.TracesEncoding, &out.MetricsEncoding, &out.LogsEncoding} {
+		if *enc == "" || *enc == encodingCustom {
+			*enc = c.Format
+		}
+	}
+	return &out
+}
+
+func validEncoding(signal, enc string) bool {
+	switch enc {
+	case "", encodingCustom, encodingOTLPJSON, encodingOTLPProto:
+		return true
+	case encodingPromRW:
+		return signal == "metrics"
+	case encodingLoki, encodingNDJSON:
+		return signal == "logs"
+	case encodingZipkin:
+		return signal == "traces"
+	}
+	return false
+}
+
+// resourceAttr resuelve un atributo del resource con el valor de config como fallback
+func resourceAttr(attrs pcommon.Map, key, def string) string {
+	if v := getAttrString(attrs, key); v != "" {
+		return v
+	}
+	return def
+}
+
+// tracesResourceURL calcula la URL de spans a nivel de resource (OTLP/JSON)
+func (m *monitoringExporter) tracesResourceURL(attrs pcommon.Map) string {
+	region := resourceAttr(attrs, "region", m.region)
+	ns := resourceAttr(attrs, "ns", m.ns)
+	vars := map[string]string{"region": region, "ns": ns, "mrid": resourceAttr(attrs, "mrid", m.mrid)}
+	if m.tracesURLTemplate != "" && m.useURLTemplate(attrs) {
+		return expandURLTemplate(m.tracesURLTemplate, vars, attrs)
+	}
+	return m.defaultURL("rho", "/v1", m.tracesPathSuffix, vars, attrs)
+}
+
+// logsResourceURL calcula la URL de logs a nivel de resource (OTLP/JSON)
+func (m *monitoringExporter) logsResourceURL(attrs pcommon.Map) string {
+	region := resourceAttr(attrs, "region", m.region)
+	ns := resourceAttr(attrs, "ns", m.ns)
+	vars := map[string]string{"region": region, "ns": ns, "mrid": resourceAttr(attrs, "mrid", m.mrid)}
+	if m.logsURLTemplate != "" && m.useURLTemplate(attrs) {
+		return expandURLTemplate(m.logsURLTemplate, vars, attrs)
+	}
+	return m.defaultURL("omega", "/v1", m.logsPathSuffix, vars, attrs)
+}
+
+// pushTracesOTLP envía las trazas en OTLP/JSON agrupando los resources por URL
+func (m *monitoringExporter) pushTracesOTLP(ctx context.Context, td ptrace.Traces) error {
+	return m.pushTracesOTLPWith(ctx, td, &ptrace.JSONMarshaler{}, m.postJSON)
+}
+
+// pushTracesOTLPProto envía lo mismo en OTLP/protobuf
+func (m *monitoringExporter) pushTracesOTLPProto(ctx context.Context, td ptrace.Traces) error {
+	return m.pushTracesOTLPWith(ctx, td, &ptrace.ProtoMarshaler{}, m.postProto)
+}
+
+func (m *monitoringExporter) pushTracesOTLPWith(ctx context.Context, td ptrace.Traces, marshaler ptrace.Marshaler, post func(context.Context, string, []byte) error) error {
+	if !m.traces {
+		m.logger.Sugar().Warnln("El envío de traces está deshabilitado, no se realizará el POST.")
+		return nil
+	}
+	if td = m.sampleTraces(td); td.SpanCount() == 0 {
+		return nil
+	}
+
+	groups := make(map[string][]ptrace.ResourceSpans)
+	var urls []string
+	rss := td.ResourceSpans()
+	for i := 0; i < rss.Len(); i++ {
+		rs := rss.At(i)
+		url := m.tracesResourceURL(rs.Resource().Attributes())
+		if _, ok := groups[url]; !ok {
+			urls = append(urls, url)
+		}
+		groups[url] = append(groups[url], rs)
+	}
+
+	// max_payload_bytes parte el lote por resources
+	encode := func(resources []ptrace.ResourceSpans) ([]byte, error) {
+		group := ptrace.NewTraces()
+		for _, rs := range resources {
+			rs.CopyTo(group.ResourceSpans().AppendEmpty())
+		}
+		return marshaler.MarshalTraces(group)
+	}
+	for _, url := range urls {
+		bodies, err := splitPayload(m, groups[url], encode)
+		if err != nil {
+			return fmt.Errorf("error marshaling OTLP traces for URL %s: %w", url, err)
+		}
+		for _, body := range bodies {
+			if err := post(ctx, url, body); err != nil {
+				return fmt.Errorf("error sending data to URL %s: %w", url, err)
+			}
+		}
+	}
+	return nil
+}
+
+// pushMetricsOTLP envía las métricas en OTLP/JSON agrupando los resources por URL
+func (m *monitoringExporter) pushMetricsOTLP(ctx context.Context, md pmetric.Metrics) error {
+	return m.pushMetricsOTLPWith(ctx, md, &pmetric.JSONMarshaler{}, m.postJSON)
+}
+
+// pushMetricsOTLPProto envía lo mismo en OTLP/protobuf
+func (m *monitoringExporter) pushMetricsOTLPProto(ctx context.Context, md pmetric.Metrics) error {
+	return m.pushMetricsOTLPWith(ctx, md, &pmetric.ProtoMarshaler{}, m.postProto)
+}
+
+func (m *monitoringExporter) pushMetricsOTLPWith(ctx context.Context, md pmetric.Metrics, marshaler pmetric.Marshaler, post func(context.Context, string, []byte) error) error {
+	if !m.metrics {
+		m.logger.Sugar().Warnln("El envío de métricas está deshabilitado, no se realizará el POST.")
+		return nil
+	}
+
+	// max_payload_bytes parte el lote por resources
+	encode := func(resources []pmetric.ResourceMetrics) ([]byte, error) {
+		group := pmetric.NewMetrics()
+		for _, rm := range resources {
+			rm.CopyTo(group.ResourceMetrics().AppendEmpty())
+		}
+		return marshaler.MarshalMetrics(group)
+	}
+	groups, urls := m.groupMetricsOTLP(md)
+	for _, url := range urls {
+		rms := groups[url].ResourceMetrics()
+		resources := make([]pmetric.ResourceMetrics, rms.Len())
+		for i := range resources {
+			resources[i] = rms.At(i)
+		}
+		bodies, err := splitPayload(m, resources, encode)
+		if err != nil {
+			return fmt.Errorf("error marshaling OTLP metrics for URL %s: %w", url, err)
+		}
+		for _, body := range bodies {
+			if err := post(ctx, url, body); err != nil {
+				return fmt.Errorf("error sending data to URL %s: %w", url, err)
+			}
+		}
+	}
+	return nil
+}
+
+// groupMetricsOTLP agrupa los resources por URL. Con
+// metric_endpoint_overrides las métricas con override van a su endpoint, con
+// copia de su resource y scope, y el resto a la URL del resource
+func (m *monitoringExporter) groupMetricsOTLP(md pmetric.Metrics) (map[string]pmetric.Metrics, []string) {
+	groups := make(map[string]pmetric.Metrics)
+	var urls []string
+	group := func(url string) pmetric.Metrics {
+		g, ok := groups[url]
+		if !ok {
+			g = pmetric.NewMetrics()
+			groups[url] = g
+			urls = append(urls, url)
+		}
+		return g
+	}
+
+	rms := md.ResourceMetrics()
+	for i := 0; i < rms.Len(); i++ {
+		rm := rms.At(i)
+		resourceURL := m.metricsURL(rm.Resource().Attributes())
+		if len(m.metricEndpointOverrides) == 0 {
+			rm.CopyTo(group(resourceURL).ResourceMetrics().AppendEmpty())
+			continue
+		}
+
+		// Resource y scope de este rm en cada grupo, creados al primer uso
+		outRMs := make(map[string]pmetric.ResourceMetrics)
+		sms := rm.ScopeMetrics()
+		for j := 0; j < sms.Len(); j++ {
+			sm := sms.At(j)
+			outSMs := make(map[string]pmetric.ScopeMetrics)
+			for k := 0; k < sm.Metrics().Len(); k++ {
+				metric := sm.Metrics().At(k)
+				url := m.metricOverrideURL(metric.Name(), resourceURL)
+				outSM, ok := outSMs[url]
+				if !ok {
+					outRM, ok := outRMs[url]
+					if !ok {
+						outRM = group(url).ResourceMetrics().AppendEmpty()
+						rm.Resource().CopyTo(outRM.Resource())
+						outRM.SetSchemaUrl(rm.SchemaUrl())
+						outRMs[url] = outRM
+					}
+					outSM = outRM.ScopeMetrics().AppendEmpty()
+					sm.Scope().CopyTo(outSM.Scope())
+					outSM.SetSchemaUrl(sm.SchemaUrl())
+					outSMs[url] = outSM
+				}
+				metric.CopyTo(outSM.Metrics().AppendEmpty())
+			}
+		}
+	}
+	return groups, urls
+}
+
+// pushLogsOTLP envía los logs en OTLP/JSON agrupando los resources por URL
+func (m *monitoringExporter) pushLogsOTLP(ctx context.Context, ld plog.Logs) error {
+	return m.pushLogsOTLPWith(ctx, ld, &plog.JSONMarshaler{}, m.postJSON)
+}
+
+// pushLogsOTLPProto envía lo mismo en OTLP/protobuf
+func (m *monitoringExporter) pushLogsOTLPProto(ctx context.Context, ld plog.Logs) error {
+	return m.pushLogsOTLPWith(ctx, ld, &plog.ProtoMarshaler{}, m.postProto)
+}
+
+func (m *monitoringExporter) pushLogsOTLPWith(ctx context.Context, ld plog.Logs, marshaler plog.Marshaler, post func(context.Context, string, []byte) error) error {
+	if !m.logs {
+		m.logger.Sugar().Warnln("El envío de logs está deshabilitado, no se realizará el POST.")
+		return nil
+	}
+
+	groups := make(map[string][]plog.ResourceLogs)
+	var urls []string
+	rls := ld.ResourceLogs()
+	for i := 0; i < rls.Len(); i++ {
+		rl := rls.At(i)
+		url := m.logsResourceURL(rl.Resource().Attributes())
+		if _, ok := groups[url]; !ok {
+			urls = append(urls, url)
+		}
+		groups[url] = append(groups[url], rl)
+	}
+
+	// max_payload_bytes parte el lote por resources
+	encode := func(resources []plog.ResourceLogs) ([]byte, error) {
+		group := plog.NewLogs()
+		for _, rl := range resources {
+			rl.CopyTo(group.ResourceLogs().AppendEmpty())
+		}
+		return marshaler.MarshalLogs(group)
+	}
+	for _, url := range urls {
+		bodies, err := splitPayload(m, groups[url], encode)
+		if err != nil {
+			return fmt.Errorf("error marshaling OTLP logs for URL %s: %w", url, err)
+		}
+		for _, body := range bodies {
+			if err := post(ctx, url, body); err != nil {
+				return fmt.Errorf("error sending data to URL %s: %w", url, err)
+			}
+		}
+	}
+	return nil
+}
+
+// protoHeaders son las cabeceras de contenido de los payloads OTLP/protobuf
+var protoHeaders = http.Header{"Content-Type": {"application/x-protobuf"}}
+
+// postProto envía un payload OTLP/protobuf; la compresión y el cifrado se
+// aplican igual que con JSON
+func (m *monitoringExporter) postProto(ctx context.Context, url string, body []byte) error {
+	return m.postPayload(ctx, url, body, protoHeaders)
+}
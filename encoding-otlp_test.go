@@ -0,0 +1,254 @@
+package opentelemetryexportermonitoring
+
+import (
+	"bytes"
+	"context"
+	"sort"
+	"testing"
+
+	"go.opentelemetry.io/collector/pdata/plog"
+	"go.opentelemetry.io/collector/pdata/pmetric"
+	"go.opentelemetry.io/collector/pdata/ptrace"
+)
+
+func TestPerSignalEncoding(t *testing.T) {
+	srv := newCaptureServer(t)
+	cfg := testConfig(t, srv.URL)
+	cfg.TracesEncoding = encodingOTLPJSON
+	cfg.MetricsEncoding = encodingCustom
+	cfg.LogsEncoding = encodingOTLPProto
+	traces, metrics, logs := newFactoryExporters(t, cfg)
+
+	ctx := context.Background()
+	if err := traces.ConsumeTraces(ctx, testTraces("svc", "op")); err != nil {
+		t.Fatal(err)
+	}
+	if err := metrics.ConsumeMetrics(ctx, gaugeMetrics("requests")); err != nil {
+		t.Fatal(err)
+	}
+	if err := logs.ConsumeLogs(ctx, logsWithResources(1, "svc")); err != nil {
+		t.Fatal(err)
+	}
+
+	reqs := make(map[string]capturedRequest)
+	for _, req := range srv.received() {
+		reqs[req.Path] = req
+	}
+	if len(reqs) != 3 {
+		t.Fatalf("rutas = %v, se esperaba una petición por señal", reqs)
+	}
+
+	var otlpTraces struct {
+		ResourceSpans []interface{} `json:"resourceSpans"`
+	}
+	decodeJSON(t, reqs["/traces"].Body, &otlpTraces)
+	if len(otlpTraces.ResourceSpans) != 1 {
+		t.Errorf("traces: se esperaba OTLP/JSON, llegó %s", reqs["/traces"].Body)
+	}
+
+	var custom struct {
+		Metrics []transformedMetric `json:"metrics"`
+	}
+	decodeJSON(t, reqs["/metrics"].Body, &custom)
+	if len(custom.Metrics) != 1 || custom.Metrics[0].Values["requests"] == nil {
+		t.Errorf("metrics: se esperaba el formato propio, llegó %s", reqs["/metrics"].Body)
+	}
+
+	if ct := reqs["/logs"].Header.Get("Content-Type"); ct != "application/x-protobuf" {
+		t.Errorf("logs: Content-Type = %q", ct)
+	}
+	ld, err := (&plog.ProtoUnmarshaler{}).UnmarshalLogs(reqs["/logs"].Body)
+	if err != nil || ld.LogRecordCount() != 1 {
+		t.Errorf("logs: se esperaba OTLP/protobuf con un registro (%v)", err)
+	}
+}
+
+func TestFormatAppliesToCustomSignals(t *testing.T) {
+	cfg := &Config{Format: encodingOTLPJSON, TracesEncoding: encodingCustom, MetricsEncoding: encodingPromRW}
+	got := cfg.withFormat()
+	if got.TracesEncoding != encodingOTLPJSON || got.LogsEncoding != encodingOTLPJSON {
+		t.Errorf("format no se aplicó a las señales custom: %+v", got)
+	}
+	if got.MetricsEncoding != encodingPromRW {
+		t.Errorf("metrics_encoding explícito sustituido por format: %s", got.MetricsEncoding)
+	}
+	if cfg.TracesEncoding != encodingCustom {
+		t.Error("withFormat modificó la config original")
+	}
+}
+
+func TestMetricEndpointOverrides(t *testing.T) {
+	for _, enc := range []string{encodingCustom, encodingOTLPJSON, encodingOTLPProto} {
+		t.Run(enc, func(t *testing.T) {
+			srv := newCaptureServer(t)
+			cfg := testConfig(t, srv.URL)
+			cfg.MetricsEncoding = enc
+			cfg.MetricEndpointOverrides = map[string]string{"slo.errors": srv.URL + "/priority"}
+			_, metrics, _ := newFactoryExporters(t, cfg)
+			if err := metrics.ConsumeMetrics(context.Background(), gaugeMetrics("cpu", "slo.errors", "mem")); err != nil {
+				t.Fatal(err)
+			}
+
+			got := map[string][]string{}
+			for _, req := range srv.received() {
+				got[req.Path] = append(got[req.Path], receivedMetricNames(t, enc, req.Body)...)
+			}
+			want := map[string][]string{"/metrics": {"cpu", "mem"}, "/priority": {"slo.errors"}}
+			if len(got) != len(want) {
+				t.Fatalf("rutas = %v, se esperaba %v", got, want)
+			}
+			for path, names := range want {
+				sort.Strings(got[path])
+				if len(got[path]) != len(names) || got[path][0] != names[0] || got[path][len(names)-1] != names[len(names)-1] {
+					t.Errorf("%s recibió %v, se esperaba %v", path, got[path], names)
+				}
+			}
+		})
+	}
+}
+
+// receivedMetricNames devuelve los nombres de métrica de un body en enc
+func receivedMetricNames(t *testing.T, enc string, body []byte) []string {
+	t.Helper()
+	if enc == encodingCustom {
+		var payload struct {
+			Metrics []transformedMetric `json:"metrics"`
+		}
+		decodeJSON(t, body, &payload)
+		return valueKeys(payload.Metrics)
+	}
+
+	var unmarshaler pmetric.Unmarshaler = &pmetric.JSONUnmarshaler{}
+	if enc == encodingOTLPProto {
+		unmarshaler = &pmetric.ProtoUnmarshaler{}
+	}
+	md, err := unmarshaler.UnmarshalMetrics(body)
+	if err != nil {
+		t.Fatalf("body OTLP inválido: %v", err)
+	}
+	var names []string
+	for i := 0; i < md.ResourceMetrics().Len(); i++ {
+		rm := md.ResourceMetrics().At(i)
+		if v, _ := rm.Resource().Attributes().Get("service.name"); v.Str() != "svc" {
+			t.Errorf("el grupo perdió el resource: %v", rm.Resource().Attributes().AsRaw())
+		}
+		for j := 0; j < rm.ScopeMetrics().Len(); j++ {
+			metrics := rm.ScopeMetrics().At(j).Metrics()
+			for k := 0; k < metrics.Len(); k++ {
+				names = append(names, metrics.At(k).Name())
+			}
+		}
+	}
+	return names
+}
+
+func TestOTLPJSONMatchesMarshalers(t *testing.T) {
+	srv := newCaptureServer(t)
+	cfg := testConfig(t, srv.URL)
+	cfg.Format = encodingOTLPJSON
+	traces, metrics, logs := newFactoryExporters(t, cfg)
+
+	td, md, ld := testTraces("svc", "op", "child"), gaugeMetrics("requests", "errors"), logsWithResources(2, "svc")
+	want := map[string][]byte{}
+	var err error
+	if want["/traces"], err = (&ptrace.JSONMarshaler{}).MarshalTraces(td); err != nil {
+		t.Fatal(err)
+	}
+	if want["/metrics"], err = (&pmetric.JSONMarshaler{}).MarshalMetrics(md); err != nil {
+		t.Fatal(err)
+	}
+	if want["/logs"], err = (&plog.JSONMarshaler{}).MarshalLogs(ld); err != nil {
+		t.Fatal(err)
+	}
+
+	ctx := context.Background()
+	if err := traces.ConsumeTraces(ctx, td); err != nil {
+		t.Fatal(err)
+	}
+	if err := metrics.ConsumeMetrics(ctx, md); err != nil {
+		t.Fatal(err)
+	}
+	if err := logs.ConsumeLogs(ctx, ld); err != nil {
+		t.Fatal(err)
+	}
+
+	reqs := srv.received()
+	if len(reqs) != len(want) {
+		t.Fatalf("peticiones = %d, se esperaba una por señal", len(reqs))
+	}
+	for _, req := range reqs {
+		if ct := req.Header.Get("Content-Type"); ct != "application/json" {
+			t.Errorf("%s: Content-Type = %q", req.Path, ct)
+		}
+		if !bytes.Equal(req.Body, want[req.Path]) {
+			t.Errorf("%s: el body no coincide con el JSONMarshaler:\n%s\n%s", req.Path, req.Body, want[req.Path])
+		}
+	}
+}
+
+func TestOTLPProtoRoundTrip(t *testing.T) {
+	for _, compression := range []string{compressionNone, compressionGzip} {
+		t.Run(orDefault(compression, "none"), func(t *testing.T) {
+			srv := newCaptureServer(t)
+			cfg := testConfig(t, srv.URL)
+			cfg.Format = encodingOTLPProto
+			cfg.Compression = compression
+			traces, metrics, logs := newFactoryExporters(t, cfg)
+
+			td, md, ld := testTraces("svc", "op", "child"), gaugeMetrics("requests", "errors"), logsWithResources(2, "svc")
+			ctx := context.Background()
+			if err := traces.ConsumeTraces(ctx, td); err != nil {
+				t.Fatal(err)
+			}
+			if err := metrics.ConsumeMetrics(ctx, md); err != nil {
+				t.Fatal(err)
+			}
+			if err := logs.ConsumeLogs(ctx, ld); err != nil {
+				t.Fatal(err)
+			}
+
+			reqs := map[string][]byte{}
+			for _, req := range srv.received() {
+				if ct := req.Header.Get("Content-Type"); ct != "application/x-protobuf" {
+					t.Errorf("%s: Content-Type = %q", req.Path, ct)
+				}
+				body := req.Body
+				if compression == compressionGzip {
+					body = gunzip(t, body)
+				}
+				reqs[req.Path] = body
+			}
+
+			gotTraces, err := (&ptrace.ProtoUnmarshaler{}).UnmarshalTraces(reqs["/traces"])
+			if err != nil {
+				t.Fatalf("traces: %v", err)
+			}
+			gotMetrics, err := (&pmetric.ProtoUnmarshaler{}).UnmarshalMetrics(reqs["/metrics"])
+			if err != nil {
+				t.Fatalf("metrics: %v", err)
+			}
+			gotLogs, err := (&plog.ProtoUnmarshaler{}).UnmarshalLogs(reqs["/logs"])
+			if err != nil {
+				t.Fatalf("logs: %v", err)
+			}
+			// Se comparan en OTLP/JSON, legible en el mensaje de error
+			var tj ptrace.JSONMarshaler
+			var mj pmetric.JSONMarshaler
+			var lj plog.JSONMarshaler
+			compare := func(name string, got, want []byte) {
+				if !bytes.Equal(got, want) {
+					t.Errorf("%s: el body no reproduce los datos enviados:\n%s\n%s", name, got, want)
+				}
+			}
+			got, _ := tj.MarshalTraces(gotTraces)
+			want, _ := tj.MarshalTraces(td)
+			compare("traces", got, want)
+			got, _ = mj.MarshalMetrics(gotMetrics)
+			want, _ = mj.MarshalMetrics(md)
+			compare("metrics", got, want)
+			got, _ = lj.MarshalLogs(gotLogs)
+			want, _ = lj.MarshalLogs(ld)
+			compare("logs", got, want)
+		})
+	}
+}
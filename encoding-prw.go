@@ -0,0 +1,196 @@
+package opentelemetryexportermonitoring
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"net/http"
+	"sort"
+	"strings"
+
+	"github.com/golang/snappy"
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	"go.opentelemetry.io/collector/pdata/pmetric"
+	"google.golang.org/protobuf/encoding/protowire"
+)
+
+// promLabel y promSeries reflejan prompb.Label / prompb.TimeSeries
+type promLabel struct {
+	name  string
+	value string
+}
+
+type promSeries struct {
+	labels    []promLabel
+	value     float64
+	timestamp int64 // milisegundos
+}
+
+// promName adapta un nombre al formato de Prometheus ([a-zA-Z_:][a-zA-Z0-9_:]*)
+func promName(name string) string {
+	var b strings.Builder
+	for i, r := range name {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r == '_', r == ':':
+			b.WriteRune(r)
+		case r >= '0' && r <= '9':
+			if i == 0 {
+				b.WriteByte('_')
+			}
+			b.WriteRune(r)
+		default:
+			b.WriteByte('_')
+		}
+	}
+	return b.String()
+}
+
+// promLabelName es como promName pero sin ':' (reservado a nombres de métrica)
+func promLabelName(name string) string {
+	return strings.ReplaceAll(promName(name), ":", "_")
+}
+
+// promSeriesFromPoints convierte los puntos de una suma o gauge en series;
+// los atributos del punto prevalecen sobre los del resource
+func (m *monitoringExporter) promSeriesFromPoints(name string, resAttrs pcommon.Map, dps pmetric.NumberDataPointSlice) []promSeries {
+	var series []promSeries
+	for i := 0; i < dps.Len(); i++ {
+		dp := dps.At(i)
+		var value float64
+		switch dp.ValueType() {
+		case pmetric.NumberDataPointValueTypeInt:
+			value = float64(dp.IntValue())
+		case pmetric.NumberDataPointValueTypeDouble:
+			value = dp.DoubleValue()
+		default:
+			continue
+		}
+
+		labels := map[string]string{}
+		for _, attrs := range []pcommon.Map{resAttrs, dp.Attributes()} {
+			attrs.Range(func(k string, v pcommon.Value) bool {
+				if m.attrAllowed(k) {
+					labels[promLabelName(m.attrKey(k))] = m.redactString(k, v.AsString())
+				}
+				return true
+			})
+		}
+		labels["__name__"] = name
+
+		s := promSeries{value: value, timestamp: dp.Timestamp().AsTime().UnixMilli()}
+		for k, v := range labels {
+			s.labels = append(s.labels, promLabel{name: k, value: v})
+		}
+		// Remote-write exige las etiquetas ordenadas por nombre
+		sort.Slice(s.labels, func(a, b int) bool { return s.labels[a].name < s.labels[b].name })
+		series = append(series, s)
+	}
+	return series
+}
+
+// transformMetricsPRW convierte sumas y gauges en series de remote-write,
+// agrupadas por URL destino; el resto de tipos se ignora
+func (m *monitoringExporter) transformMetricsPRW(md pmetric.Metrics) (map[string][]promSeries, []string) {
+	groups := make(map[string][]promSeries)
+	var urls []string
+	rms := md.ResourceMetrics()
+	for i := 0; i < rms.Len(); i++ {
+		resAttrs := rms.At(i).Resource().Attributes()
+		resourceURL := m.metricsURL(resAttrs)
+		sms := rms.At(i).ScopeMetrics()
+		for j := 0; j < sms.Len(); j++ {
+			metrics := sms.At(j).Metrics()
+			for k := 0; k < metrics.Len(); k++ {
+				metric := metrics.At(k)
+				name := promName(metric.Name())
+				var series []promSeries
+				switch metric.Type() {
+				case pmetric.MetricTypeGauge:
+					series = m.promSeriesFromPoints(name, resAttrs, metric.Gauge().DataPoints())
+				case pmetric.MetricTypeSum:
+					sum := metric.Sum()
+					if sum.IsMonotonic() && sum.AggregationTemporality() == pmetric.AggregationTemporalityCumulative &&
+						!strings.HasSuffix(name, "_total") {
+						name += "_total"
+					}
+					series = m.promSeriesFromPoints(name, resAttrs, sum.DataPoints())
+				default:
+					continue
+				}
+				if len(series) == 0 {
+					continue
+				}
+				url := m.metricOverrideURL(metric.Name(), resourceURL)
+				if _, ok := groups[url]; !ok {
+					urls = append(urls, url)
+				}
+				groups[url] = append(groups[url], series...)
+			}
+		}
+	}
+	return groups, urls
+}
+
+// encodeWriteRequest serializa un prompb.WriteRequest:
+// WriteRequest{1: TimeSeries}, TimeSeries{1: Label, 2: Sample},
+// Label{1: name, 2: value}, Sample{1: double value, 2: int64 timestamp}
+func encodeWriteRequest(series []promSeries) []byte {
+	var out []byte
+	for _, s := range series {
+		var ts []byte
+		for _, l := range s.labels {
+			var lb []byte
+			lb = protowire.AppendTag(lb, 1, protowire.BytesType)
+			lb = protowire.AppendString(lb, l.name)
+			lb = protowire.AppendTag(lb, 2, protowire.BytesType)
+			lb = protowire.AppendString(lb, l.value)
+			ts = protowire.AppendTag(ts, 1, protowire.BytesType)
+			ts = protowire.AppendBytes(ts, lb)
+		}
+		var sb []byte
+		sb = protowire.AppendTag(sb, 1, protowire.Fixed64Type)
+		sb = protowire.AppendFixed64(sb, math.Float64bits(s.value))
+		sb = protowire.AppendTag(sb, 2, protowire.VarintType)
+		sb = protowire.AppendVarint(sb, uint64(s.timestamp))
+		ts = protowire.AppendTag(ts, 2, protowire.BytesType)
+		ts = protowire.AppendBytes(ts, sb)
+
+		out = protowire.AppendTag(out, 1, protowire.BytesType)
+		out = protowire.AppendBytes(out, ts)
+	}
+	return out
+}
+
+// prwHeaders son las cabeceras que exige el protocolo remote-write 0.1.0
+var prwHeaders = http.Header{
+	"Content-Type":                      {"application/x-protobuf"},
+	"Content-Encoding":                  {"snappy"},
+	"X-Prometheus-Remote-Write-Version": {"0.1.0"},
+}
+
+// pushMetricsPRW envía las métricas como WriteRequest comprimido con snappy
+func (m *monitoringExporter) pushMetricsPRW(ctx context.Context, md pmetric.Metrics) error {
+	if !m.metrics {
+		m.logger.Sugar().Warnln("El envío de métricas está deshabilitado, no se realizará el POST.")
+		return nil
+	}
+
+	ctx, _ = m.withBatchID(ctx)
+	groups, urls := m.transformMetricsPRW(md)
+	// max_payload_bytes se aplica al body comprimido, el que viaja
+	encode := func(series []promSeries) ([]byte, error) {
+		return snappy.Encode(nil, encodeWriteRequest(series)), nil
+	}
+	for _, url := range urls {
+		bodies, err := splitPayload(m, groups[url], encode)
+		if err != nil {
+			return fmt.Errorf("error marshaling remote-write metrics for URL %s: %w", url, err)
+		}
+		for _, body := range bodies {
+			if err := m.postPayload(ctx, url, body, prwHeaders); err != nil {
+				return fmt.Errorf("error sending data to URL %s: %w", url, err)
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,145 @@
+package opentelemetryexportermonitoring
+
+import (
+	"context"
+	"math"
+	"testing"
+
+	"github.com/golang/snappy"
+	"go.opentelemetry.io/collector/pdata/pmetric"
+	"google.golang.org/protobuf/encoding/protowire"
+)
+
+// decodeWriteRequest es el inverso de encodeWriteRequest; falla el test si
+// el body no es un prompb.WriteRequest válido
+func decodeWriteRequest(t *testing.T, data []byte) []promSeries {
+	t.Helper()
+	var series []promSeries
+	forEachField(t, data, func(num protowire.Number, typ protowire.Type, field []byte) {
+		if num != 1 || typ != protowire.BytesType {
+			t.Fatalf("WriteRequest: campo inesperado %d", num)
+		}
+		var s promSeries
+		forEachField(t, field, func(num protowire.Number, _ protowire.Type, field []byte) {
+			switch num {
+			case 1:
+				var l promLabel
+				forEachField(t, field, func(num protowire.Number, _ protowire.Type, field []byte) {
+					if num == 1 {
+						l.name = string(field)
+					} else {
+						l.value = string(field)
+					}
+				})
+				s.labels = append(s.labels, l)
+			case 2:
+				forEachField(t, field, func(num protowire.Number, _ protowire.Type, field []byte) {
+					if num == 1 {
+						bits, _ := protowire.ConsumeFixed64(field)
+						s.value = math.Float64frombits(bits)
+					} else {
+						v, _ := protowire.ConsumeVarint(field)
+						s.timestamp = int64(v)
+					}
+				})
+			}
+		})
+		series = append(series, s)
+	})
+	return series
+}
+
+// forEachField recorre los campos de un mensaje protobuf; en los escalares
+// field contiene el valor sin decodificar
+func forEachField(t *testing.T, data []byte, fn func(protowire.Number, protowire.Type, []byte)) {
+	t.Helper()
+	for len(data) > 0 {
+		num, typ, n := protowire.ConsumeTag(data)
+		if n < 0 {
+			t.Fatalf("tag inválido: %v", protowire.ParseError(n))
+		}
+		data = data[n:]
+		var field []byte
+		switch typ {
+		case protowire.BytesType:
+			v, n := protowire.ConsumeBytes(data)
+			if n < 0 {
+				t.Fatalf("campo %d inválido: %v", num, protowire.ParseError(n))
+			}
+			field, data = v, data[n:]
+		default:
+			n := protowire.ConsumeFieldValue(num, typ, data)
+			if n < 0 {
+				t.Fatalf("campo %d inválido: %v", num, protowire.ParseError(n))
+			}
+			field, data = data[:n], data[n:]
+		}
+		fn(num, typ, field)
+	}
+}
+
+func TestPRWBodyUnmarshals(t *testing.T) {
+	md := gaugeMetrics("cpu.usage")
+	metrics := md.ResourceMetrics().At(0).ScopeMetrics().At(0).Metrics()
+	sum := metrics.AppendEmpty()
+	sum.SetName("http.requests")
+	s := sum.SetEmptySum()
+	s.SetIsMonotonic(true)
+	s.SetAggregationTemporality(pmetric.AggregationTemporalityCumulative)
+	dp := s.DataPoints().AppendEmpty()
+	dp.SetTimestamp(2_500_000_000)
+	dp.SetDoubleValue(42.5)
+	dp.Attributes().PutStr("method", "GET")
+	metrics.AppendEmpty().SetName("ignored") // tipo vacío: se ignora
+
+	srv := newCaptureServer(t)
+	cfg := testConfig(t, srv.URL)
+	cfg.MetricsEncoding = encodingPromRW
+	if err := newTestExporter(t, cfg).pushMetricsPRW(context.Background(), md); err != nil {
+		t.Fatal(err)
+	}
+	reqs := srv.received()
+	if len(reqs) != 1 {
+		t.Fatalf("peticiones = %d, se esperaba 1", len(reqs))
+	}
+	for k, v := range map[string]string{
+		"Content-Type":                      "application/x-protobuf",
+		"Content-Encoding":                  "snappy",
+		"X-Prometheus-Remote-Write-Version": "0.1.0",
+	} {
+		if got := reqs[0].Header.Get(k); got != v {
+			t.Errorf("%s = %q, se esperaba %q", k, got, v)
+		}
+	}
+
+	raw, err := snappy.Decode(nil, reqs[0].Body)
+	if err != nil {
+		t.Fatalf("el body no es snappy: %v", err)
+	}
+	series := decodeWriteRequest(t, raw)
+	if len(series) != 2 {
+		t.Fatalf("%d series, se esperaban 2", len(series))
+	}
+	want := []struct {
+		labels    []promLabel
+		value     float64
+		timestamp int64
+	}{
+		{[]promLabel{{"__name__", "cpu_usage"}, {"service_name", "svc"}}, 1, 1000},
+		{[]promLabel{{"__name__", "http_requests_total"}, {"method", "GET"}, {"service_name", "svc"}}, 42.5, 2500},
+	}
+	for i, w := range want {
+		got := series[i]
+		if len(got.labels) != len(w.labels) {
+			t.Fatalf("serie %d: etiquetas %v, se esperaba %v", i, got.labels, w.labels)
+		}
+		for j := range w.labels {
+			if got.labels[j] != w.labels[j] {
+				t.Errorf("serie %d: etiqueta %d = %v, se esperaba %v", i, j, got.labels[j], w.labels[j])
+			}
+		}
+		if got.value != w.value || got.timestamp != w.timestamp {
+			t.Errorf("serie %d: muestra (%v, %d), se esperaba (%v, %d)", i, got.value, got.timestamp, w.value, w.timestamp)
+		}
+	}
+}
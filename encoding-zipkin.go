@@ -0,0 +1,129 @@
+package opentelemetryexportermonitoring
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	"go.opentelemetry.io/collector/pdata/ptrace"
+)
+
+// zipkinEndpoint y zipkinSpan siguen el modelo JSON v2 de Zipkin
+type zipkinEndpoint struct {
+	ServiceName string `json:"serviceName,omitempty"`
+}
+
+type zipkinSpan struct {
+	TraceID       string            `json:"traceId"`
+	ID            string            `json:"id"`
+	ParentID      string            `json:"parentId,omitempty"`
+	Name          string            `json:"name"`
+	Kind          string            `json:"kind,omitempty"`
+	Timestamp     int64             `json:"timestamp"`
+	Duration      int64             `json:"duration"`
+	LocalEndpoint *zipkinEndpoint   `json:"localEndpoint,omitempty"`
+	Tags          map[string]string `json:"tags,omitempty"`
+}
+
+// zipkinKind traduce el kind de OTel; INTERNAL y UNSPECIFIED no tienen equivalente
+func zipkinKind(kind ptrace.SpanKind) string {
+	switch kind {
+	case ptrace.SpanKindClient:
+		return "CLIENT"
+	case ptrace.SpanKindServer:
+		return "SERVER"
+	case ptrace.SpanKindProducer:
+		return "PRODUCER"
+	case ptrace.SpanKindConsumer:
+		return "CONSUMER"
+	}
+	return ""
+}
+
+// toZipkinSpan convierte un span; los atributos pasan a tags como texto y el
+// estado a otel.status_code / error, como hace el exporter zipkin de contrib
+func (m *monitoringExporter) toZipkinSpan(span ptrace.Span, service string) zipkinSpan {
+	start := span.StartTimestamp().AsTime()
+	z := zipkinSpan{
+		TraceID:   span.TraceID().String(),
+		ID:        span.SpanID().String(),
+		Name:      span.Name(),
+		Kind:      zipkinKind(span.Kind()),
+		Timestamp: start.UnixMicro(),
+		Duration:  span.EndTimestamp().AsTime().Sub(start).Microseconds(),
+	}
+	if !span.ParentSpanID().IsEmpty() {
+		z.ParentID = span.ParentSpanID().String()
+	}
+	if service != "" {
+		z.LocalEndpoint = &zipkinEndpoint{ServiceName: service}
+	}
+
+	tags := make(map[string]string)
+	span.Attributes().Range(func(k string, v pcommon.Value) bool {
+		if m.attrAllowed(k) {
+			tags[m.attrKey(k)] = m.redactString(k, v.AsString())
+		}
+		return true
+	})
+	switch span.Status().Code() {
+	case ptrace.StatusCodeError:
+		tags["otel.status_code"] = "ERROR"
+		tags["error"] = span.Status().Message()
+		if tags["error"] == "" {
+			tags["error"] = "true"
+		}
+	case ptrace.StatusCodeOk:
+		tags["otel.status_code"] = "OK"
+	}
+	if len(tags) > 0 {
+		z.Tags = tags
+	}
+	return z
+}
+
+// pushTracesZipkin envía las trazas en Zipkin JSON v2 agrupando los resources por URL
+func (m *monitoringExporter) pushTracesZipkin(ctx context.Context, td ptrace.Traces) error {
+	if !m.traces {
+		m.logger.Sugar().Warnln("El envío de traces está deshabilitado, no se realizará el POST.")
+		return nil
+	}
+	if td = m.sampleTraces(td); td.SpanCount() == 0 {
+		return nil
+	}
+
+	groups := make(map[string][]zipkinSpan)
+	var urls []string
+	rss := td.ResourceSpans()
+	for i := 0; i < rss.Len(); i++ {
+		resAttrs := rss.At(i).Resource().Attributes()
+		url := m.tracesResourceURL(resAttrs)
+		service := getAttrString(resAttrs, "service.name")
+		sss := rss.At(i).ScopeSpans()
+		for j := 0; j < sss.Len(); j++ {
+			spans := sss.At(j).Spans()
+			for k := 0; k < spans.Len(); k++ {
+				if _, ok := groups[url]; !ok {
+					urls = append(urls, url)
+				}
+				groups[url] = append(groups[url], m.toZipkinSpan(spans.At(k), service))
+			}
+		}
+	}
+
+	ctx, _ = m.withBatchID(ctx)
+	encode := func(spans []zipkinSpan) ([]byte, error) { return json.Marshal(spans) }
+	for _, url := range urls {
+		bodies, err := splitPayload(m, groups[url], encode)
+		if err != nil {
+			return fmt.Errorf("error marshaling Zipkin spans for URL %s: %w", url, err)
+		}
+		for _, body := range bodies {
+			if err := m.postJSON(ctx, url, body); err != nil {
+				return fmt.Errorf("error sending data to URL %s: %w", url, err)
+			}
+		}
+	}
+	return nil
+}
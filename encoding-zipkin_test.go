@@ -0,0 +1,92 @@
+package opentelemetryexportermonitoring
+
+import (
+	"context"
+	"testing"
+
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	"go.opentelemetry.io/collector/pdata/ptrace"
+)
+
+func TestZipkinConversion(t *testing.T) {
+	td := testTraces("svc", "GET /a", "db")
+	spans := td.ResourceSpans().At(0).ScopeSpans().At(0).Spans()
+	root := spans.At(0)
+	root.SetKind(ptrace.SpanKindServer)
+	root.Attributes().PutStr("http.method", "GET")
+	root.Attributes().PutInt("http.status_code", 500)
+	root.Status().SetCode(ptrace.StatusCodeError)
+	root.Status().SetMessage("boom")
+	child := spans.At(1)
+	child.SetKind(ptrace.SpanKindInternal)
+	child.SetParentSpanID(root.SpanID())
+	child.SetStartTimestamp(pcommon.Timestamp(1_000_250_000))
+	child.SetEndTimestamp(pcommon.Timestamp(1_001_750_500))
+	child.Status().SetCode(ptrace.StatusCodeOk)
+
+	srv := newCaptureServer(t)
+	cfg := testConfig(t, srv.URL)
+	cfg.TracesEncoding = encodingZipkin
+	if err := newTestExporter(t, cfg).pushTracesZipkin(context.Background(), td); err != nil {
+		t.Fatal(err)
+	}
+	reqs := srv.received()
+	if len(reqs) != 1 {
+		t.Fatalf("peticiones = %d, se esperaba 1", len(reqs))
+	}
+	var got []map[string]interface{}
+	decodeJSON(t, reqs[0].Body, &got)
+	if len(got) != 2 {
+		t.Fatalf("%d spans, se esperaban 2", len(got))
+	}
+
+	want := []map[string]interface{}{
+		{
+			"traceId":       "0102030405060708090a0b0c0d0e0f10",
+			"id":            "0102030405060701",
+			"name":          "GET /a",
+			"kind":          "SERVER",
+			"timestamp":     1_000_000.0, // microsegundos
+			"duration":      500_000.0,
+			"localEndpoint": map[string]interface{}{"serviceName": "svc"},
+			"tags": map[string]interface{}{
+				"http_method":      "GET",
+				"http_status_code": "500",
+				"otel.status_code": "ERROR",
+				"error":            "boom",
+			},
+		},
+		{
+			"traceId":       "0102030405060708090a0b0c0d0e0f10",
+			"id":            "0102030405060702",
+			"parentId":      "0102030405060701",
+			"name":          "db",
+			"timestamp":     1_000_250.0,
+			"duration":      1_500.0, // se trunca a microsegundos
+			"localEndpoint": map[string]interface{}{"serviceName": "svc"},
+			"tags":          map[string]interface{}{"otel.status_code": "OK"},
+		},
+	}
+	for i, w := range want {
+		if len(got[i]) != len(w) {
+			t.Errorf("span %d: campos %v, se esperaba %v", i, got[i], w)
+		}
+		for k, v := range w {
+			if nested, ok := v.(map[string]interface{}); ok {
+				g, _ := got[i][k].(map[string]interface{})
+				if len(g) != len(nested) {
+					t.Errorf("span %d: %s = %v, se esperaba %v", i, k, got[i][k], v)
+				}
+				for nk, nv := range nested {
+					if g[nk] != nv {
+						t.Errorf("span %d: %s.%s = %v, se esperaba %v", i, k, nk, g[nk], nv)
+					}
+				}
+				continue
+			}
+			if got[i][k] != v {
+				t.Errorf("span %d: %s = %v, se esperaba %v", i, k, got[i][k], v)
+			}
+		}
+	}
+}
@@ -0,0 +1,80 @@
+package opentelemetryexportermonitoring
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"fmt"
+	"os"
+	"strings"
+
+	"go.opentelemetry.io/collector/config/configopaque"
+)
+
+// EncryptionConfig cifra el payload antes del POST. El body enviado es
+// nonce || ciphertext (nonce de 12 bytes aleatorio por petición) y la
+// cabecera X-Payload-Encryption indica el algoritmo
+type EncryptionConfig struct {
+	// Algoritmo; solo "aes-gcm" (AES-128/192/256 según el tamaño de la clave)
+	Algorithm string `mapstructure:"algorithm"`
+	// Clave en base64, directamente o en un fichero
+	Key     configopaque.String `mapstructure:"key"`
+	KeyFile string              `mapstructure:"key_file"`
+}
+
+const encryptionAESGCM = "aes-gcm"
+
+// payloadEncryptor cifra los payloads con la clave configurada
+type payloadEncryptor struct {
+	algorithm string
+	aead      cipher.AEAD
+}
+
+// newPayloadEncryptor devuelve nil si no hay clave configurada
+func newPayloadEncryptor(cfg EncryptionConfig) (*payloadEncryptor, error) {
+	if cfg.Key == "" && cfg.KeyFile == "" {
+		return nil, nil
+	}
+	if cfg.Key != "" && cfg.KeyFile != "" {
+		return nil, fmt.Errorf("encryption: key y key_file son excluyentes")
+	}
+	algorithm := cfg.Algorithm
+	if algorithm == "" {
+		algorithm = encryptionAESGCM
+	}
+	if algorithm != encryptionAESGCM {
+		return nil, fmt.Errorf("encryption: algoritmo no soportado: %q", cfg.Algorithm)
+	}
+
+	encoded := string(cfg.Key)
+	if cfg.KeyFile != "" {
+		data, err := os.ReadFile(cfg.KeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("encryption: error al leer key_file: %w", err)
+		}
+		encoded = strings.TrimSpace(string(data))
+	}
+	key, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil {
+		return nil, fmt.Errorf("encryption: la clave no es base64 válido: %w", err)
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, fmt.Errorf("encryption: %w", err)
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, fmt.Errorf("encryption: %w", err)
+	}
+	return &payloadEncryptor{algorithm: algorithm, aead: aead}, nil
+}
+
+// seal cifra plain y antepone el nonce
+func (e *payloadEncryptor) seal(plain []byte) ([]byte, error) {
+	nonce := make([]byte, e.aead.NonceSize(), e.aead.NonceSize()+len(plain)+e.aead.Overhead())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, fmt.Errorf("encryption: error al generar el nonce: %w", err)
+	}
+	return e.aead.Seal(nonce, nonce, plain, nil), nil
+}
@@ -0,0 +1,113 @@
+package opentelemetryexportermonitoring
+
+import (
+	"bytes"
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"encoding/base64"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"go.opentelemetry.io/collector/config/configopaque"
+)
+
+// testEncryptionKey es una clave AES-256 fija en base64
+var testEncryptionKey = base64.StdEncoding.EncodeToString(bytes.Repeat([]byte{7}, 32))
+
+// openPayload descifra un body nonce || ciphertext como lo haría el sink
+func openPayload(t *testing.T, key string, body []byte) []byte {
+	t.Helper()
+	raw, _ := base64.StdEncoding.DecodeString(key)
+	block, err := aes.NewCipher(raw)
+	if err != nil {
+		t.Fatal(err)
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(body) < aead.NonceSize() {
+		t.Fatalf("body de %d bytes, no cabe el nonce", len(body))
+	}
+	plain, err := aead.Open(nil, body[:aead.NonceSize()], body[aead.NonceSize():], nil)
+	if err != nil {
+		t.Fatalf("no se puede descifrar: %v", err)
+	}
+	return plain
+}
+
+func TestEncryptionRoundTrip(t *testing.T) {
+	enc, err := newPayloadEncryptor(EncryptionConfig{Key: configopaque.String(testEncryptionKey)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	plain := []byte(`{"metrics":[]}`)
+	a, err := enc.seal(plain)
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, _ := enc.seal(plain)
+
+	if got := openPayload(t, testEncryptionKey, a); !bytes.Equal(got, plain) {
+		t.Errorf("descifrado = %q, se esperaba %q", got, plain)
+	}
+	// nonce de 12 bytes + texto + etiqueta GCM de 16
+	if len(a) != 12+len(plain)+16 {
+		t.Errorf("body cifrado de %d bytes, se esperaban %d", len(a), 12+len(plain)+16)
+	}
+	if bytes.Equal(a[:12], b[:12]) {
+		t.Error("dos peticiones comparten nonce")
+	}
+	if bytes.Contains(a, plain) {
+		t.Error("el body cifrado contiene el texto plano")
+	}
+}
+
+func TestEncryptionKeyFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "key")
+	if err := os.WriteFile(path, []byte(testEncryptionKey+"\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	enc, err := newPayloadEncryptor(EncryptionConfig{KeyFile: path})
+	if err != nil {
+		t.Fatal(err)
+	}
+	sealed, _ := enc.seal([]byte("x"))
+	if string(openPayload(t, testEncryptionKey, sealed)) != "x" {
+		t.Error("la clave de key_file no coincide")
+	}
+
+	for _, cfg := range []EncryptionConfig{
+		{Key: configopaque.String(testEncryptionKey), KeyFile: path},
+		{Key: "no es base64"},
+		{Key: configopaque.String(base64.StdEncoding.EncodeToString([]byte("corta")))},
+		{Key: configopaque.String(testEncryptionKey), Algorithm: "chacha20"},
+	} {
+		if _, err := newPayloadEncryptor(cfg); err == nil {
+			t.Errorf("se esperaba un error con %+v", cfg)
+		}
+	}
+}
+
+func TestEncryptionAppliedAfterCompression(t *testing.T) {
+	srv := newCaptureServer(t)
+	cfg := testConfig(t, srv.URL)
+	cfg.Encryption.Key = configopaque.String(testEncryptionKey)
+	cfg.Compression = compressionGzip
+	if err := newTestExporter(t, cfg).pushMetrics(context.Background(), gaugeMetrics("m")); err != nil {
+		t.Fatal(err)
+	}
+	req := srv.received()[0]
+	if req.Header.Get("X-Payload-Encryption") != encryptionAESGCM || req.Header.Get("Content-Encoding") != "gzip" {
+		t.Errorf("cabeceras = %v", req.Header)
+	}
+	var payload struct {
+		Metrics []transformedMetric `json:"metrics"`
+	}
+	decodeJSON(t, gunzip(t, openPayload(t, testEncryptionKey, req.Body)), &payload)
+	if len(payload.Metrics) != 1 {
+		t.Errorf("payload descifrado = %+v", payload)
+	}
+}
@@ -0,0 +1,13 @@
+package opentelemetryexportermonitoring
+
+import "go.opentelemetry.io/collector/pdata/pcommon"
+
+// useURLTemplate indica si las *_url_template se aplican a un resource.
+// Sin endpoint_from_attribute se aplican siempre; con él solo a los resources
+// que traen ese atributo (p. ej. tenant.id, usado como {tenant.id} en la
+// plantilla) y el resto va a la URL por defecto de region y ns. Como las URLs
+// se calculan por resource, un lote con varios tenants se envía en un POST
+// por tenant
+func (m *monitoringExporter) useURLTemplate(resAttrs pcommon.Map) bool {
+	return m.endpointFromAttribute == "" || getAttrString(resAttrs, m.endpointFromAttribute) != ""
+}
@@ -0,0 +1,122 @@
+package opentelemetryexportermonitoring
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"testing"
+
+	"go.opentelemetry.io/collector/pdata/pcommon"
+)
+
+// recordingTransport responde 200 a todo y anota cuántos registros JSON
+// llegan a cada URL, incluidas las de region y ns que no apuntan a un
+// servidor de pruebas
+type recordingTransport struct {
+	mu      sync.Mutex
+	records map[string]int
+}
+
+func (rt *recordingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	body, _ := io.ReadAll(req.Body)
+	var payload struct {
+		Metrics []json.RawMessage `json:"metrics"`
+	}
+	var logs []json.RawMessage
+	n := 0
+	if json.Unmarshal(body, &logs) == nil {
+		n = len(logs)
+	} else if json.Unmarshal(body, &payload) == nil {
+		n = len(payload.Metrics)
+	}
+	rt.mu.Lock()
+	rt.records[req.URL.String()] += n
+	rt.mu.Unlock()
+	return &http.Response{StatusCode: http.StatusOK, Body: io.NopCloser(strings.NewReader("")), Header: http.Header{}, Request: req}, nil
+}
+
+func TestEndpointFromAttributeGroupsByTenant(t *testing.T) {
+	cfg := testConfig(t, "http://ingest.test")
+	cfg.Region, cfg.NS = "eu-west", "ns1"
+	cfg.EndpointFromAttribute = "tenant.id"
+	cfg.MetricsURLTemplate = "http://ingest.test/{tenant.id}/v1/metrics"
+	cfg.LogsURLTemplate = "http://ingest.test/{tenant.id}/v1/logs"
+	if err := cfg.Validate(); err != nil {
+		t.Fatal(err)
+	}
+	exp := newTestExporter(t, cfg)
+	rt := &recordingTransport{records: map[string]int{}}
+	exp.client.Transport = rt
+
+	// Cuatro resources: dos de acme, uno de globex y uno sin tenant
+	tenants := []string{"acme", "globex", "acme", ""}
+	md := gaugeMetrics("m")
+	for i := 1; i < len(tenants); i++ {
+		md.ResourceMetrics().At(0).CopyTo(md.ResourceMetrics().AppendEmpty())
+	}
+	ld := logsWithResources(1, "a", "b", "c", "d")
+	for i, tenant := range tenants {
+		if tenant != "" {
+			md.ResourceMetrics().At(i).Resource().Attributes().PutStr("tenant.id", tenant)
+			ld.ResourceLogs().At(i).Resource().Attributes().PutStr("tenant.id", tenant)
+		}
+	}
+	if err := exp.pushMetrics(context.Background(), md); err != nil {
+		t.Fatal(err)
+	}
+	if err := exp.pushLogs(context.Background(), ld); err != nil {
+		t.Fatal(err)
+	}
+
+	want := map[string]int{
+		"http://ingest.test/acme/v1/metrics":   2,
+		"http://ingest.test/globex/v1/metrics": 1,
+		exp.metricsURL(pcommon.NewMap()):       1,
+		"http://ingest.test/acme/v1/logs":      2,
+		"http://ingest.test/globex/v1/logs":    1,
+		exp.logsResourceURL(pcommon.NewMap()):  1,
+	}
+	rt.mu.Lock()
+	defer rt.mu.Unlock()
+	if len(rt.records) != len(want) {
+		t.Errorf("URLs = %v, se esperaban %v", rt.records, want)
+	}
+	for url, n := range want {
+		if rt.records[url] != n {
+			t.Errorf("%s: registros = %d, se esperaban %d", url, rt.records[url], n)
+		}
+	}
+	for url := range rt.records {
+		if strings.Contains(url, "unknown") {
+			t.Errorf("un resource sin tenant fue a la plantilla: %s", url)
+		}
+	}
+}
+
+func TestEndpointFromAttributeFallbackURL(t *testing.T) {
+	cfg := testConfig(t, "http://ingest.test")
+	cfg.Region, cfg.NS = "eu-west", "ns1"
+	cfg.EndpointFromAttribute = "tenant.id"
+	cfg.MetricsURLTemplate = "http://ingest.test/{tenant.id}/v1/metrics"
+	exp := newTestExporter(t, cfg)
+
+	got := exp.metricsURL(pcommon.NewMap())
+	if strings.HasPrefix(got, "http://ingest.test/") || !strings.Contains(got, "eu-west") {
+		t.Errorf("URL sin tenant = %s, se esperaba la URL por defecto de region y ns", got)
+	}
+	attrs := pcommon.NewMap()
+	attrs.PutStr("tenant.id", "acme")
+	if got := exp.metricsURL(attrs); got != "http://ingest.test/acme/v1/metrics" {
+		t.Errorf("URL con tenant = %s", got)
+	}
+}
+
+func TestEndpointFromAttributeNeedsTemplate(t *testing.T) {
+	cfg := createDefaultConfig().(*Config)
+	cfg.Region, cfg.NS = "eu-west", "ns1"
+	cfg.EndpointFromAttribute = "tenant.id"
+	validateError(t, cfg, "endpoint_from_attribute")
+}
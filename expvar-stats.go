@@ -0,0 +1,98 @@
+package opentelemetryexportermonitoring
+
+import (
+	"context"
+	"expvar"
+	"net"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// connStats son los contadores de conexión publicados por expvar. Los
+// exporters que comparten expvar_name comparten también los contadores
+type connStats struct {
+	name string
+	once sync.Once
+
+	activeConns   expvar.Int
+	totalRequests expvar.Int
+	totalBytes    expvar.Int
+	queueDropped  expvar.Int
+}
+
+var (
+	connStatsMu       sync.Mutex
+	connStatsRegistry = map[string]*connStats{}
+)
+
+// sharedConnStats devuelve los contadores asociados a name, creándolos si no existen
+func sharedConnStats(name string) *connStats {
+	connStatsMu.Lock()
+	defer connStatsMu.Unlock()
+	if s, ok := connStatsRegistry[name]; ok {
+		return s
+	}
+	s := &connStats{name: name}
+	connStatsRegistry[name] = s
+	return s
+}
+
+// publish registra los contadores en expvar una única vez por nombre
+func (s *connStats) publish(logger *zap.Logger) {
+	s.once.Do(func() {
+		// Si el nombre ya está ocupado por otra variable no se sobrescribe
+		// (expvar.Publish entraría en pánico) y los contadores no se exponen
+		if expvar.Get(s.name) != nil {
+			logger.Warn("expvar_name ya está registrado por otra variable, no se publican los contadores",
+				zap.String("expvar_name", s.name))
+			return
+		}
+		vars := new(expvar.Map)
+		vars.Set("active_connections", &s.activeConns)
+		vars.Set("total_requests", &s.totalRequests)
+		vars.Set("total_bytes", &s.totalBytes)
+		vars.Set("queue_dropped", &s.queueDropped)
+		expvar.Publish(s.name, vars)
+	})
+}
+
+// recordRequest cuenta una petición y los bytes de su body
+func (s *connStats) recordRequest(bytes int) {
+	if s == nil {
+		return
+	}
+	s.totalRequests.Add(1)
+	s.totalBytes.Add(int64(bytes))
+}
+
+// dialer envuelve dial para llevar la cuenta de conexiones abiertas
+func (s *connStats) dialer(dial func(ctx context.Context, network, addr string) (net.Conn, error)) func(ctx context.Context, network, addr string) (net.Conn, error) {
+	if dial == nil {
+		dial = (&net.Dialer{
+			Timeout:   30 * time.Second,
+			KeepAlive: 30 * time.Second,
+		}).DialContext
+	}
+	return func(ctx context.Context, network, addr string) (net.Conn, error) {
+		conn, err := dial(ctx, network, addr)
+		if err != nil {
+			return nil, err
+		}
+		s.activeConns.Add(1)
+		return &countedConn{Conn: conn, stats: s}, nil
+	}
+}
+
+// countedConn descuenta la conexión activa al cerrarse (una sola vez)
+type countedConn struct {
+	net.Conn
+	stats *connStats
+	once  sync.Once
+}
+
+func (c *countedConn) Close() error {
+	c.once.Do(func() { c.stats.activeConns.Add(-1) })
+	return c.Conn.Close()
+}
@@ -0,0 +1,57 @@
+package opentelemetryexportermonitoring
+
+import (
+	"context"
+	"expvar"
+	"testing"
+
+	"go.opentelemetry.io/collector/component/componenttest"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+func TestExpvarReflectsPushes(t *testing.T) {
+	srv := newCaptureServer(t)
+	cfg := testConfig(t, srv.URL)
+	cfg.ExpvarName = "test_expvar_pushes"
+	exp := newTestExporter(t, cfg)
+	if err := exp.start(context.Background(), componenttest.NewNopHost()); err != nil {
+		t.Fatal(err)
+	}
+
+	vars, ok := expvar.Get(cfg.ExpvarName).(*expvar.Map)
+	if !ok {
+		t.Fatalf("%s no está publicado en expvar", cfg.ExpvarName)
+	}
+	for i := 0; i < 2; i++ {
+		if err := exp.pushMetrics(context.Background(), gaugeMetrics("m")); err != nil {
+			t.Fatal(err)
+		}
+	}
+	var sent int64
+	for _, req := range srv.received() {
+		sent += int64(len(req.Body))
+	}
+	if got := vars.Get("total_requests").(*expvar.Int).Value(); got != 2 {
+		t.Errorf("total_requests = %d, se esperaban 2", got)
+	}
+	if got := vars.Get("total_bytes").(*expvar.Int).Value(); got != sent {
+		t.Errorf("total_bytes = %d, el servidor recibió %d", got, sent)
+	}
+	if got := vars.Get("active_connections").(*expvar.Int).Value(); got < 1 {
+		t.Errorf("active_connections = %d con la conexión keep-alive abierta", got)
+	}
+}
+
+func TestExpvarNameTaken(t *testing.T) {
+	expvar.NewString("test_expvar_taken")
+	core, logs := observer.New(zapcore.WarnLevel)
+	sharedConnStats("test_expvar_taken").publish(zap.New(core))
+	if logs.FilterField(zap.String("expvar_name", "test_expvar_taken")).Len() != 1 {
+		t.Error("no se avisó de que expvar_name ya estaba registrado")
+	}
+	if _, ok := expvar.Get("test_expvar_taken").(*expvar.String); !ok {
+		t.Error("se sobrescribió la variable existente")
+	}
+}
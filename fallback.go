@@ -0,0 +1,55 @@
+package opentelemetryexportermonitoring
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"go.uber.org/zap"
+)
+
+// httpStatusError es la respuesta no 2xx del sink; conserva el código y el
+// principio del body para poder decidir el fallback
+type httpStatusError struct {
+	url    string
+	status int
+	body   string
+}
+
+func (e *httpStatusError) Error() string {
+	return fmt.Sprintf("monitoring exporter: %s -> HTTP %d", e.url, e.status)
+}
+
+// shouldFallback indica si el error es un rechazo que activa fallback_encoding:
+// un código de fallback_on_status y, si se configura, el texto de
+// fallback_body_contains en la respuesta
+func (m *monitoringExporter) shouldFallback(err error) bool {
+	var statusErr *httpStatusError
+	if !errors.As(err, &statusErr) {
+		return false
+	}
+	matched := false
+	for _, code := range m.fallbackOnStatus {
+		matched = matched || code == statusErr.status
+	}
+	if !matched {
+		return false
+	}
+	return m.fallbackBodyContains == "" || strings.Contains(statusErr.body, m.fallbackBodyContains)
+}
+
+// withFallback reenvía una única vez con la codificación de fallback si el
+// sink rechaza el payload principal. Si el lote iba a varias URLs se reenvía
+// completo, incluidas las que ya lo habían aceptado
+func withFallback[T any](m *monitoringExporter, primary, fallback func(context.Context, T) error) func(context.Context, T) error {
+	return func(ctx context.Context, data T) error {
+		err := primary(ctx, data)
+		if err == nil || !m.shouldFallback(err) {
+			return err
+		}
+		m.logger.Warn("payload rechazado, reenviando con la codificación de fallback",
+			zap.String("fallback_encoding", m.fallbackEncoding), zap.Error(err))
+		return fallback(ctx, data)
+	}
+}
@@ -0,0 +1,75 @@
+package opentelemetryexportermonitoring
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"testing"
+)
+
+func TestFallbackTriggers(t *testing.T) {
+	tests := []struct {
+		name         string
+		status       int
+		body         string
+		bodyContains string
+		wantFallback bool
+	}{
+		{"código configurado", http.StatusUnprocessableEntity, "schema mismatch", "", true},
+		{"código configurado con el texto esperado", http.StatusBadRequest, `{"error":"schema mismatch"}`, "schema", true},
+		{"código configurado sin el texto esperado", http.StatusBadRequest, `{"error":"quota"}`, "schema", false},
+		{"código no configurado", http.StatusInternalServerError, "schema mismatch", "", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := newCaptureServer(t)
+			// Rechaza el formato propio y acepta OTLP/JSON
+			srv.respond(func(w http.ResponseWriter, r *http.Request) {
+				if !bytes.Contains(srv.received()[len(srv.received())-1].Body, []byte(`"resourceMetrics"`)) {
+					w.WriteHeader(tt.status)
+					w.Write([]byte(tt.body))
+				}
+			})
+			cfg := testConfig(t, srv.URL)
+			cfg.FallbackEncoding = encodingOTLPJSON
+			cfg.FallbackBodyContains = tt.bodyContains
+			_, metrics, _ := newFactoryExporters(t, cfg)
+			err := metrics.ConsumeMetrics(context.Background(), gaugeMetrics("m"))
+
+			reqs := srv.received()
+			if !tt.wantFallback {
+				if err == nil || len(reqs) != 1 {
+					t.Errorf("err = %v, peticiones = %d; se esperaba el rechazo sin fallback", err, len(reqs))
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("err = %v, el fallback debía ser aceptado", err)
+			}
+			if len(reqs) != 2 {
+				t.Fatalf("peticiones = %d, se esperaba el rechazo y un único reenvío", len(reqs))
+			}
+			var otlp struct {
+				ResourceMetrics []interface{} `json:"resourceMetrics"`
+			}
+			decodeJSON(t, reqs[1].Body, &otlp)
+			if len(otlp.ResourceMetrics) != 1 {
+				t.Errorf("el reenvío no es OTLP/JSON: %s", reqs[1].Body)
+			}
+		})
+	}
+}
+
+func TestFallbackSentOnce(t *testing.T) {
+	srv := newCaptureServer(t)
+	srv.respond(func(w http.ResponseWriter, r *http.Request) { w.WriteHeader(http.StatusUnprocessableEntity) })
+	cfg := testConfig(t, srv.URL)
+	cfg.FallbackEncoding = encodingOTLPJSON
+	_, metrics, _ := newFactoryExporters(t, cfg)
+	if err := metrics.ConsumeMetrics(context.Background(), gaugeMetrics("m")); err == nil {
+		t.Error("se esperaba el error del fallback rechazado")
+	}
+	if got := len(srv.received()); got != 2 {
+		t.Errorf("peticiones = %d, el fallback se reenvía una sola vez", got)
+	}
+}
@@ -0,0 +1,92 @@
+package opentelemetryexportermonitoring
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"strconv"
+	"sync"
+
+	"github.com/google/uuid"
+	"go.opentelemetry.io/collector/exporter"
+	"go.opentelemetry.io/collector/pdata/plog"
+	"go.opentelemetry.io/collector/pdata/pmetric"
+	"go.opentelemetry.io/collector/pdata/ptrace"
+)
+
+// idempotencyBatchKey es la clave de contexto del identificador de lote de
+// idempotency_key_header
+type idempotencyBatchKey struct{}
+
+// withIdempotencyBatch asigna un identificador al lote antes de entrar en el
+// exporterhelper: los reintentos reutilizan el mismo contexto, así que todos
+// los intentos ven el mismo identificador
+func withIdempotencyBatch(ctx context.Context) context.Context {
+	return context.WithValue(ctx, idempotencyBatchKey{}, uuid.NewString())
+}
+
+// idempotencyAttemptKey es la clave de contexto del contador de POST de un
+// intento de push
+type idempotencyAttemptKey struct{}
+
+// idempotencyAttempt numera los POST de un intento por URL
+type idempotencyAttempt struct {
+	mu  sync.Mutex
+	seq map[string]int
+}
+
+// next devuelve el índice del siguiente POST a url en este intento
+func (a *idempotencyAttempt) next(url string) int {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	n := a.seq[url]
+	a.seq[url]++
+	return n
+}
+
+// idempotentPush envuelve la función de envío que recibe el exporterhelper:
+// cada intento, el primero y cada reintento, numera sus POST desde cero, así
+// que el N-ésimo POST a una URL lleva la misma clave en todos los intentos
+func idempotentPush[T any](push func(context.Context, T) error) func(context.Context, T) error {
+	return func(ctx context.Context, data T) error {
+		attempt := &idempotencyAttempt{seq: make(map[string]int)}
+		return push(context.WithValue(ctx, idempotencyAttemptKey{}, attempt), data)
+	}
+}
+
+// idempotencyKey deriva la clave de idempotency_key_header del lote, de la
+// URL y del índice del POST dentro del intento: es la misma en cada reintento
+// aunque el body se codifique distinto, y distinta para cada POST del lote
+// (URLs y trozos de max_payload_bytes). Si el contexto no trae lote ni
+// intento (cola persistente, batching del exporterhelper, ventanas o envíos
+// fuera del pipeline) se usa el digest canónico del body en lugar del índice
+func idempotencyKey(ctx context.Context, url string, body []byte, isJSON bool) string {
+	batch, _ := ctx.Value(idempotencyBatchKey{}).(string)
+	attempt, _ := ctx.Value(idempotencyAttemptKey{}).(*idempotencyAttempt)
+	request := contentDigest(body, isJSON)
+	if batch != "" && attempt != nil {
+		request = strconv.Itoa(attempt.next(url))
+	}
+	sum := sha256.Sum256([]byte(batch + "\n" + url + "\n" + request))
+	return hex.EncodeToString(sum[:16])
+}
+
+// idempotentTraces, idempotentMetrics e idempotentLogs envuelven el exporter
+// del exporterhelper para asignar el lote en cada Consume
+type idempotentTraces struct{ exporter.Traces }
+
+func (e idempotentTraces) ConsumeTraces(ctx context.Context, td ptrace.Traces) error {
+	return e.Traces.ConsumeTraces(withIdempotencyBatch(ctx), td)
+}
+
+type idempotentMetrics struct{ exporter.Metrics }
+
+func (e idempotentMetrics) ConsumeMetrics(ctx context.Context, md pmetric.Metrics) error {
+	return e.Metrics.ConsumeMetrics(withIdempotencyBatch(ctx), md)
+}
+
+type idempotentLogs struct{ exporter.Logs }
+
+func (e idempotentLogs) ConsumeLogs(ctx context.Context, ld plog.Logs) error {
+	return e.Logs.ConsumeLogs(withIdempotencyBatch(ctx), ld)
+}
@@ -0,0 +1,117 @@
+package opentelemetryexportermonitoring
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"go.opentelemetry.io/collector/pdata/plog"
+)
+
+func TestIdempotencyKeyStableAcrossRetries(t *testing.T) {
+	srv := newCaptureServer(t)
+	srv.respond(failFirst(2, "/metrics"))
+	cfg := testConfig(t, srv.URL)
+	cfg.IdempotencyKeyHeader = "Idempotency-Key"
+	cfg.BatchIDHeader = "X-Batch-Id"
+	fastRetries(cfg)
+	_, metrics, _ := newFactoryExporters(t, cfg)
+
+	ctx := context.Background()
+	if err := metrics.ConsumeMetrics(ctx, gaugeMetrics("m")); err != nil {
+		t.Fatal(err)
+	}
+	reqs := srv.received()
+	if len(reqs) != 3 {
+		t.Fatalf("peticiones = %d, se esperaban dos fallos y el reintento aceptado", len(reqs))
+	}
+	key := reqs[0].Header.Get("Idempotency-Key")
+	if key == "" {
+		t.Fatal("falta la cabecera Idempotency-Key")
+	}
+	for i, req := range reqs[1:] {
+		if got := req.Header.Get("Idempotency-Key"); got != key {
+			t.Errorf("intento %d: Idempotency-Key = %s, se esperaba %s", i+2, got, key)
+		}
+	}
+
+	// El mismo contenido en otro lote no es un duplicado
+	if err := metrics.ConsumeMetrics(ctx, gaugeMetrics("m")); err != nil {
+		t.Fatal(err)
+	}
+	reqs = srv.received()
+	if got := reqs[len(reqs)-1].Header.Get("Idempotency-Key"); got == key {
+		t.Error("dos lotes distintos con el mismo contenido comparten Idempotency-Key")
+	}
+}
+
+func TestIdempotencyKeyPerRequestInBatch(t *testing.T) {
+	srv := newCaptureServer(t)
+	cfg := testConfig(t, srv.URL)
+	cfg.IdempotencyKeyHeader = "Idempotency-Key"
+	cfg.MetricEndpointOverrides = map[string]string{"b": srv.URL + "/priority"}
+	_, metrics, _ := newFactoryExporters(t, cfg)
+	if err := metrics.ConsumeMetrics(context.Background(), gaugeMetrics("a", "b")); err != nil {
+		t.Fatal(err)
+	}
+	reqs := srv.received()
+	if len(reqs) != 2 {
+		t.Fatalf("peticiones = %d, se esperaba una por endpoint", len(reqs))
+	}
+	if reqs[0].Header.Get("Idempotency-Key") == reqs[1].Header.Get("Idempotency-Key") {
+		t.Error("los POST de un mismo lote a URLs distintas comparten Idempotency-Key")
+	}
+}
+
+func TestIdempotencyKeyStableWithLogTemplates(t *testing.T) {
+	srv := newCaptureServer(t)
+	srv.respond(failFirst(2, "/logs"))
+	cfg := testConfig(t, srv.URL)
+	cfg.IdempotencyKeyHeader = "Idempotency-Key"
+	cfg.AggregateLogTemplates = true
+	cfg.LogTemplateExamples = 1
+	fastRetries(cfg)
+	_, _, logs := newFactoryExporters(t, cfg)
+
+	ld := plog.NewLogs()
+	sl := ld.ResourceLogs().AppendEmpty().ScopeLogs().AppendEmpty()
+	for i := 0; i < 20; i++ {
+		sl.LogRecords().AppendEmpty().Body().SetStr(fmt.Sprintf("user %d logged in", i))
+	}
+	if err := logs.ConsumeLogs(context.Background(), ld); err != nil {
+		t.Fatal(err)
+	}
+	reqs := srv.received()
+	if len(reqs) != 3 {
+		t.Fatalf("peticiones = %d, se esperaban dos fallos y el reintento aceptado", len(reqs))
+	}
+	key := reqs[0].Header.Get("Idempotency-Key")
+	for i, req := range reqs[1:] {
+		if got := req.Header.Get("Idempotency-Key"); got != key {
+			t.Errorf("intento %d: Idempotency-Key = %s, se esperaba %s", i+2, got, key)
+		}
+	}
+}
+
+func TestIdempotencyKeyIgnoresBodyWithinBatch(t *testing.T) {
+	ctx := withIdempotencyBatch(context.Background())
+	var keys []string
+	// Cada intento codifica el lote de nuevo; la clave no puede depender del body
+	for attempt, body := range []string{`{"a":1}`, `{"a":2}`} {
+		push := idempotentPush(func(ctx context.Context, _ struct{}) error {
+			keys = append(keys,
+				idempotencyKey(ctx, "http://x/logs", []byte(body), true),
+				idempotencyKey(ctx, "http://x/logs", []byte(body), true))
+			return nil
+		})
+		if err := push(ctx, struct{}{}); err != nil {
+			t.Fatalf("intento %d: %v", attempt, err)
+		}
+	}
+	if keys[0] == keys[1] {
+		t.Error("dos POST a la misma URL en un intento comparten Idempotency-Key")
+	}
+	if keys[0] != keys[2] || keys[1] != keys[3] {
+		t.Errorf("las claves cambian entre intentos con otro body: %v", keys)
+	}
+}
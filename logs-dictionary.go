@@ -0,0 +1,53 @@
+package opentelemetryexportermonitoring
+
+import (
+	"encoding/json"
+	"sort"
+)
+
+// dictLog es un log cuyas properties se sustituyen por referencias al
+// diccionario del lote. El campo Properties (nil) oculta el del log embebido
+type dictLog struct {
+	transformedLog
+	Properties    map[string]interface{} `json:"properties,omitempty"`
+	PropertiesRef []int                  `json:"properties_ref"`
+}
+
+// buildAttributeDictionary construye el diccionario de atributos de un lote.
+//
+// Contrato de decodificación: el payload lleva "attributes", una lista de
+// pares [clave, valor] sin repetidos, y cada log lleva "properties_ref" con
+// los índices de sus pares en esa lista (en orden de clave) en lugar de
+// "properties". Las properties originales se reconstruyen como
+// {attributes[i][0]: attributes[i][1] para cada i en properties_ref}. Dos
+// pares son el mismo si su serialización JSON coincide.
+func buildAttributeDictionary(logs []transformedLog) ([][2]interface{}, []dictLog, error) {
+	entries := [][2]interface{}{}
+	index := make(map[string]int)
+	out := make([]dictLog, len(logs))
+	for i, l := range logs {
+		keys := make([]string, 0, len(l.Properties))
+		for k := range l.Properties {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+
+		refs := make([]int, 0, len(keys))
+		for _, k := range keys {
+			entry := [2]interface{}{k, l.Properties[k]}
+			key, err := json.Marshal(entry)
+			if err != nil {
+				return nil, nil, err
+			}
+			ref, ok := index[string(key)]
+			if !ok {
+				ref = len(entries)
+				index[string(key)] = ref
+				entries = append(entries, entry)
+			}
+			refs = append(refs, ref)
+		}
+		out[i] = dictLog{transformedLog: l, PropertiesRef: refs}
+	}
+	return entries, out, nil
+}
@@ -0,0 +1,75 @@
+package opentelemetryexportermonitoring
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+func TestAttributeDictionary(t *testing.T) {
+	logs := []transformedLog{
+		{Message: "a", Properties: map[string]interface{}{"env": "prod", "pod": "p1", "code": 200.0}},
+		{Message: "b", Properties: map[string]interface{}{"env": "prod", "pod": "p2", "code": 200.0}},
+		{Message: "c", Properties: map[string]interface{}{"env": "dev", "tags": []interface{}{"x", "y"}}},
+		{Message: "d"},
+		{Message: "e", Properties: map[string]interface{}{"env": "prod", "tags": []interface{}{"x", "y"}}},
+	}
+	cfg := testConfig(t, "http://localhost")
+	cfg.DedupLogAttributes = true
+	body, err := newTestExporter(t, cfg).encodeLogs(logs)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var payload struct {
+		Attributes [][2]interface{} `json:"attributes"`
+		Logs       []struct {
+			Message       string                 `json:"message"`
+			Properties    map[string]interface{} `json:"properties"`
+			PropertiesRef []int                  `json:"properties_ref"`
+		} `json:"logs"`
+	}
+	decodeJSON(t, body, &payload)
+
+	// Sin pares repetidos: env=prod, pod=p1, pod=p2, code=200, env=dev, tags
+	seen := map[string]bool{}
+	for _, entry := range payload.Attributes {
+		key, _ := json.Marshal(entry)
+		if seen[string(key)] {
+			t.Errorf("par repetido en el diccionario: %s", key)
+		}
+		seen[string(key)] = true
+	}
+	if len(payload.Attributes) != 6 {
+		t.Errorf("diccionario = %v, se esperaban 6 pares", payload.Attributes)
+	}
+
+	// Reconstrucción según el contrato de decodificación
+	for i, l := range payload.Logs {
+		if l.Properties != nil {
+			t.Errorf("log %d: properties no debe enviarse con el diccionario", i)
+		}
+		if l.PropertiesRef == nil {
+			t.Errorf("log %d: falta properties_ref", i)
+		}
+		got := map[string]interface{}{}
+		for _, ref := range l.PropertiesRef {
+			if ref < 0 || ref >= len(payload.Attributes) {
+				t.Fatalf("log %d: referencia %d fuera del diccionario", i, ref)
+			}
+			got[payload.Attributes[ref][0].(string)] = payload.Attributes[ref][1]
+		}
+		want := logs[i].Properties
+		if want == nil {
+			want = map[string]interface{}{}
+		}
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("log %d (%s): properties = %v, se esperaba %v", i, l.Message, got, want)
+		}
+	}
+
+	// El lote original no se modifica: puede volver a codificarse en trozos
+	if logs[0].Properties["env"] != "prod" {
+		t.Error("encodeLogs modificó los logs de entrada")
+	}
+}
@@ -0,0 +1,82 @@
+package opentelemetryexportermonitoring
+
+import (
+	"encoding/json"
+	"hash/fnv"
+	"math/rand"
+	"regexp"
+)
+
+// logTemplatePatterns sustituyen las partes variables del mensaje, en orden
+var logTemplatePatterns = []struct {
+	re   *regexp.Regexp
+	repl string
+}{
+	{regexp.MustCompile(`[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}`), "{uuid}"},
+	{regexp.MustCompile(`\b\d{1,3}(\.\d{1,3}){3}\b`), "{ip}"},
+	{regexp.MustCompile(`\b0x[0-9a-fA-F]+\b|\b[0-9a-fA-F]{16,}\b`), "{hex}"},
+	{regexp.MustCompile(`"[^"]*"|'[^']*'`), "{str}"},
+	{regexp.MustCompile(`-?\b\d+(\.\d+)?\b`), "{num}"},
+}
+
+// logTemplate normaliza el mensaje a su plantilla. Los body estructurados
+// (parse_json_log_bodies) se normalizan sobre su JSON
+func logTemplate(message interface{}) string {
+	text, ok := message.(string)
+	if !ok {
+		data, _ := json.Marshal(message)
+		text = string(data)
+	}
+	for _, p := range logTemplatePatterns {
+		text = p.re.ReplaceAllString(text, p.repl)
+	}
+	return text
+}
+
+// logTemplateGroup son los logs de una misma plantilla y nivel
+type logTemplateGroup struct {
+	Template string        `json:"template"`
+	Level    string        `json:"level"`
+	Count    int           `json:"count"`
+	Examples []interface{} `json:"examples"`
+
+	// rng elige los ejemplos; se siembra con la plantilla y el nivel para
+	// que el mismo lote dé siempre el mismo body
+	rng *rand.Rand
+}
+
+// encodeLogTemplates agrupa los logs por plantilla y nivel y emite
+// {template, level, count, examples}, con hasta log_template_examples
+// mensajes de ejemplo elegidos al azar (muestreo reservoir). El azar es
+// determinista por grupo: un reintento del mismo lote repite los ejemplos y con
+// ellos el Idempotency-Key, el digest y el modo canónico
+func (m *monitoringExporter) encodeLogTemplates(logs []transformedLog, batchID string) ([]byte, error) {
+	var groups []*logTemplateGroup
+	index := make(map[[2]string]*logTemplateGroup)
+	for _, log := range logs {
+		key := [2]string{logTemplate(log.Message), log.Level}
+		group, ok := index[key]
+		if !ok {
+			h := fnv.New64a()
+			h.Write([]byte(key[0] + "\x00" + key[1]))
+			group = &logTemplateGroup{Template: key[0], Level: key[1], Examples: []interface{}{}, rng: rand.New(rand.NewSource(int64(h.Sum64())))}
+			index[key] = group
+			groups = append(groups, group)
+		}
+		group.Count++
+		if len(group.Examples) < m.logTemplateExamples {
+			group.Examples = append(group.Examples, log.Message)
+		} else if r := group.rng.Intn(group.Count); r < m.logTemplateExamples {
+			group.Examples[r] = log.Message
+		}
+	}
+
+	payload := map[string]interface{}{"log_templates": groups}
+	if batchID != "" {
+		payload["batchId"] = batchID
+	}
+	if m.collectorHost != "" {
+		payload["collector_host"] = m.collectorHost
+	}
+	return json.Marshal(payload)
+}
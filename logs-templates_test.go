@@ -0,0 +1,143 @@
+package opentelemetryexportermonitoring
+
+import (
+	"bytes"
+	"fmt"
+	"testing"
+)
+
+func TestLogTemplateNormalizes(t *testing.T) {
+	tests := []struct {
+		message interface{}
+		want    string
+	}{
+		{"user 42 logged in from 10.0.0.7", "user {num} logged in from {ip}"},
+		{"took 12.5 ms", "took {num} ms"},
+		{`order "A-17" failed`, "order {str} failed"},
+		{"request 3f2b1c9a-0d4e-4f6a-9b8c-7d6e5f4a3b2c done", "request {uuid} done"},
+		{"ptr 0xdeadbeef", "ptr {hex}"},
+		{map[string]interface{}{"user": "ana", "n": 3}, `{{str}:{num},{str}:{str}}`},
+	}
+	for _, tt := range tests {
+		if got := logTemplate(tt.message); got != tt.want {
+			t.Errorf("logTemplate(%v) = %q, se esperaba %q", tt.message, got, tt.want)
+		}
+	}
+}
+
+// templateLogs devuelve n logs INFO "user <i> logged in" y dos WARN de otra plantilla
+func templateLogs(n int) []transformedLog {
+	var logs []transformedLog
+	for i := 0; i < n; i++ {
+		logs = append(logs, transformedLog{Level: "INFO", Message: fmt.Sprintf("user %d logged in", i)})
+	}
+	logs = append(logs,
+		transformedLog{Level: "WARN", Message: "cache miss for key 'a'"},
+		transformedLog{Level: "WARN", Message: "cache miss for key 'b'"},
+		// Misma plantilla con otro nivel: grupo aparte
+		transformedLog{Level: "ERROR", Message: "user 7 logged in"},
+	)
+	return logs
+}
+
+func encodedTemplates(t *testing.T, exp *monitoringExporter, logs []transformedLog) []logTemplateGroup {
+	t.Helper()
+	data, err := exp.encodeLogTemplates(logs, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var payload struct {
+		LogTemplates []logTemplateGroup `json:"log_templates"`
+	}
+	decodeJSON(t, data, &payload)
+	return payload.LogTemplates
+}
+
+func TestLogTemplatesGrouping(t *testing.T) {
+	cfg := testConfig(t, "http://localhost")
+	cfg.AggregateLogTemplates = true
+	exp := newTestExporter(t, cfg)
+
+	groups := encodedTemplates(t, exp, templateLogs(10))
+	want := []struct {
+		template, level string
+		count           int
+	}{
+		{"user {num} logged in", "INFO", 10},
+		{"cache miss for key {str}", "WARN", 2},
+		{"user {num} logged in", "ERROR", 1},
+	}
+	if len(groups) != len(want) {
+		t.Fatalf("grupos = %+v", groups)
+	}
+	for i, w := range want {
+		g := groups[i]
+		if g.Template != w.template || g.Level != w.level || g.Count != w.count {
+			t.Errorf("grupo %d = %s/%s x%d, se esperaba %s/%s x%d", i, g.Template, g.Level, g.Count, w.template, w.level, w.count)
+		}
+		if wantExamples := min(w.count, cfg.LogTemplateExamples); len(g.Examples) != wantExamples {
+			t.Errorf("grupo %d: %d ejemplos, se esperaban %d", i, len(g.Examples), wantExamples)
+		}
+	}
+}
+
+func TestLogTemplatesExampleSampling(t *testing.T) {
+	cfg := testConfig(t, "http://localhost")
+	cfg.AggregateLogTemplates = true
+	cfg.LogTemplateExamples = 2
+	exp := newTestExporter(t, cfg)
+
+	// El muestreo reservoir debe poder elegir cualquier mensaje, no solo los
+	// primeros. Cada plantilla siembra su propio azar, así que se varía la
+	// plantilla con un prefijo por lote
+	const n = 10
+	seen := make(map[int]bool)
+	for run := 0; run < 200; run++ {
+		prefix := fmt.Sprintf("%c%c", 'a'+run/26, 'a'+run%26)
+		var logs []transformedLog
+		for i := 0; i < n; i++ {
+			logs = append(logs, transformedLog{Level: "INFO", Message: fmt.Sprintf("%s user %d logged in", prefix, i)})
+		}
+		examples := encodedTemplates(t, exp, logs)[0].Examples
+		if len(examples) != 2 {
+			t.Fatalf("ejemplos = %v, se esperaban 2", examples)
+		}
+		if examples[0] == examples[1] {
+			t.Fatalf("ejemplo repetido: %v", examples)
+		}
+		for _, e := range examples {
+			var i int
+			if _, err := fmt.Sscanf(e.(string), prefix+" user %d logged in", &i); err != nil {
+				t.Fatalf("ejemplo inesperado %q: %v", e, err)
+			}
+			seen[i] = true
+		}
+	}
+	for i := 0; i < n; i++ {
+		if !seen[i] {
+			t.Errorf("\"user %d logged in\" nunca salió como ejemplo en 200 plantillas", i)
+		}
+	}
+}
+
+func TestLogTemplatesDeterministic(t *testing.T) {
+	cfg := testConfig(t, "http://localhost")
+	cfg.AggregateLogTemplates = true
+	cfg.LogTemplateExamples = 1
+	exp := newTestExporter(t, cfg)
+
+	// Un reintento vuelve a codificar el mismo lote: el body debe repetirse
+	first, err := exp.encodeLogTemplates(templateLogs(20), "batch")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for run := 0; run < 20; run++ {
+		again, err := exp.encodeLogTemplates(templateLogs(20), "batch")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(again, first) {
+			t.Fatalf("el mismo lote dio otro body:\n%s\n%s", first, again)
+		}
+	}
+}
@@ -0,0 +1,54 @@
+package opentelemetryexportermonitoring
+
+import (
+	"go.opentelemetry.io/collector/pdata/pmetric"
+)
+
+// outExemplar es un exemplar del punto tal como se envía al sink
+type outExemplar struct {
+	Timestamp  int64                  `json:"timestamp"`
+	Value      interface{}            `json:"value"`
+	TraceID    string                 `json:"traceId,omitempty"`
+	SpanID     string                 `json:"spanId,omitempty"`
+	Attributes map[string]interface{} `json:"attributes,omitempty"`
+}
+
+// transformExemplars convierte los exemplars del punto. Con
+// exemplars_sampled_only se descartan los que no llevan trace ID: el SDK solo
+// lo rellena cuando la medida se tomó dentro de un span muestreado
+func (m *monitoringExporter) transformExemplars(exemplars pmetric.ExemplarSlice) []outExemplar {
+	var out []outExemplar
+	for i := 0; i < exemplars.Len(); i++ {
+		ex := exemplars.At(i)
+		if m.exemplarsSampledOnly && ex.TraceID().IsEmpty() {
+			continue
+		}
+
+		var value interface{}
+		switch ex.ValueType() {
+		case pmetric.ExemplarValueTypeInt:
+			value = ex.IntValue()
+		case pmetric.ExemplarValueTypeDouble:
+			value = ex.DoubleValue()
+		default:
+			continue
+		}
+
+		o := outExemplar{
+			Timestamp: ex.Timestamp().AsTime().UnixNano(),
+			Value:     value,
+		}
+		if !ex.TraceID().IsEmpty() {
+			o.TraceID = ex.TraceID().String()
+		}
+		if !ex.SpanID().IsEmpty() {
+			o.SpanID = ex.SpanID().String()
+		}
+		if ex.FilteredAttributes().Len() > 0 {
+			o.Attributes = make(map[string]interface{}, ex.FilteredAttributes().Len())
+			m.putAttrs(o.Attributes, ex.FilteredAttributes().AsRaw())
+		}
+		out = append(out, o)
+	}
+	return out
+}
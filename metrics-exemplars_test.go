@@ -0,0 +1,54 @@
+package opentelemetryexportermonitoring
+
+import (
+	"testing"
+
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	"go.opentelemetry.io/collector/pdata/pmetric"
+)
+
+// exemplarMetrics devuelve un gauge con un exemplar muestreado (con trace y
+// span ID) y otro sin trazas
+func exemplarMetrics() pmetric.Metrics {
+	md := gaugeMetrics("m")
+	dp := md.ResourceMetrics().At(0).ScopeMetrics().At(0).Metrics().At(0).Gauge().DataPoints().At(0)
+	sampled := dp.Exemplars().AppendEmpty()
+	sampled.SetDoubleValue(1.5)
+	sampled.SetTraceID(pcommon.TraceID{1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12, 13, 14, 15, 16})
+	sampled.SetSpanID(pcommon.SpanID{1, 2, 3, 4, 5, 6, 7, 8})
+	dp.Exemplars().AppendEmpty().SetIntValue(7)
+	return md
+}
+
+func TestExemplarsSampledOnly(t *testing.T) {
+	tests := []struct {
+		sampledOnly bool
+		want        int
+	}{
+		{false, 2},
+		{true, 1},
+	}
+	for _, tt := range tests {
+		cfg := testConfig(t, "http://localhost")
+		cfg.IncludeExemplars = true
+		cfg.ExemplarsSampledOnly = tt.sampledOnly
+		records, _, err := newTestExporter(t, cfg).transformMetrics(exemplarMetrics())
+		if err != nil {
+			t.Fatal(err)
+		}
+		exemplars := records[0].Exemplars
+		if len(exemplars) != tt.want {
+			t.Fatalf("exemplars_sampled_only=%v: %d exemplars, se esperaban %d", tt.sampledOnly, len(exemplars), tt.want)
+		}
+		if exemplars[0].TraceID != "0102030405060708090a0b0c0d0e0f10" || exemplars[0].SpanID != "0102030405060708" {
+			t.Errorf("exemplar muestreado = %+v", exemplars[0])
+		}
+		if tt.sampledOnly {
+			for _, ex := range exemplars {
+				if ex.TraceID == "" {
+					t.Errorf("se incluyó un exemplar sin traza: %+v", ex)
+				}
+			}
+		}
+	}
+}
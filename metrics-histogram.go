@@ -0,0 +1,162 @@
+package opentelemetryexportermonitoring
+
+import (
+	"strconv"
+
+	"go.opentelemetry.io/collector/pdata/pmetric"
+)
+
+// transformHistogramPercentiles emite por cada punto del histograma un registro
+// cuyo valor es un objeto {"p50": ..., "p99": ...} con los percentiles
+// configurados en histogram_as_percentiles
+func (m *monitoringExporter) transformHistogramPercentiles(name string, dataPoints pmetric.HistogramDataPointSlice, res metricResource) []transformedMetric {
+	var records []transformedMetric
+	for l := 0; l < dataPoints.Len(); l++ {
+		dataPoint := dataPoints.At(l)
+		if dataPoint.Count() == 0 {
+			continue
+		}
+		records = append(records, m.newMetricRecord(name, res, dataPoint.Timestamp(), dataPoint.Attributes(), m.histogramPercentileValues(dataPoint)))
+	}
+	return records
+}
+
+// histogramPercentileValues calcula los percentiles configurados de un punto
+func (m *monitoringExporter) histogramPercentileValues(dp pmetric.HistogramDataPoint) map[string]interface{} {
+	out := make(map[string]interface{}, len(m.histogramPercentiles))
+	for _, p := range m.histogramPercentiles {
+		out["p"+strconv.FormatFloat(p, 'f', -1, 64)] = histogramPercentile(dp, p/100)
+	}
+	return out
+}
+
+// histogramPercentile aproxima el cuantil q (0-1] de un histograma de buckets
+// explícitos por interpolación lineal dentro del bucket que contiene el rango
+// q*count, igual que histogram_quantile de Prometheus. El límite inferior del
+// primer bucket es Min (o 0) y el superior del último bucket, que es abierto,
+// es Max (o el último límite explícito)
+func histogramPercentile(dp pmetric.HistogramDataPoint, q float64) float64 {
+	counts := dp.BucketCounts()
+	bounds := dp.ExplicitBounds()
+	if counts.Len() == 0 || dp.Count() == 0 {
+		return 0
+	}
+
+	rank := q * float64(dp.Count())
+	var cumulative float64
+	for i := 0; i < counts.Len(); i++ {
+		c := float64(counts.At(i))
+		if c == 0 || cumulative+c < rank {
+			cumulative += c
+			continue
+		}
+
+		var lower, upper float64
+		switch {
+		case i == 0:
+			if dp.HasMin() {
+				lower = dp.Min()
+			}
+		default:
+			lower = bounds.At(i - 1)
+		}
+		switch {
+		case i < bounds.Len():
+			upper = bounds.At(i)
+		case dp.HasMax():
+			upper = dp.Max()
+		case bounds.Len() > 0:
+			// Bucket +Inf sin máximo conocido: el mejor dato es el último límite
+			return bounds.At(bounds.Len() - 1)
+		default:
+			return lower
+		}
+		if i == 0 && bounds.Len() > 0 && lower > upper {
+			lower = upper
+		}
+		return lower + (upper-lower)*(rank-cumulative)/c
+	}
+
+	if bounds.Len() > 0 {
+		return bounds.At(bounds.Len() - 1)
+	}
+	return 0
+}
+
+// transformHistograms emite por cada punto un registro cuyo valor es un objeto
+// con count, sum, bucket_counts y explicit_bounds (min/max si vienen)
+func (m *monitoringExporter) transformHistograms(name string, dataPoints pmetric.HistogramDataPointSlice, res metricResource) []transformedMetric {
+	var records []transformedMetric
+	for l := 0; l < dataPoints.Len(); l++ {
+		dp := dataPoints.At(l)
+		value := map[string]interface{}{
+			"count":           dp.Count(),
+			"bucket_counts":   dp.BucketCounts().AsRaw(),
+			"explicit_bounds": dp.ExplicitBounds().AsRaw(),
+		}
+		if dp.HasSum() {
+			value["sum"] = dp.Sum()
+		}
+		if dp.HasMin() {
+			value["min"] = dp.Min()
+		}
+		if dp.HasMax() {
+			value["max"] = dp.Max()
+		}
+		records = append(records, m.newMetricRecord(name, res, dp.Timestamp(), dp.Attributes(), value))
+	}
+	return records
+}
+
+// transformExponentialHistograms emite scale, zero_count y los buckets
+// positivos y negativos (offset + bucket_counts) de cada punto
+func (m *monitoringExporter) transformExponentialHistograms(name string, dataPoints pmetric.ExponentialHistogramDataPointSlice, res metricResource) []transformedMetric {
+	var records []transformedMetric
+	for l := 0; l < dataPoints.Len(); l++ {
+		dp := dataPoints.At(l)
+		value := map[string]interface{}{
+			"count":      dp.Count(),
+			"scale":      dp.Scale(),
+			"zero_count": dp.ZeroCount(),
+			"positive": map[string]interface{}{
+				"offset":        dp.Positive().Offset(),
+				"bucket_counts": dp.Positive().BucketCounts().AsRaw(),
+			},
+			"negative": map[string]interface{}{
+				"offset":        dp.Negative().Offset(),
+				"bucket_counts": dp.Negative().BucketCounts().AsRaw(),
+			},
+		}
+		if dp.HasSum() {
+			value["sum"] = dp.Sum()
+		}
+		if dp.HasMin() {
+			value["min"] = dp.Min()
+		}
+		if dp.HasMax() {
+			value["max"] = dp.Max()
+		}
+		records = append(records, m.newMetricRecord(name, res, dp.Timestamp(), dp.Attributes(), value))
+	}
+	return records
+}
+
+// transformSummaries emite count, sum y los cuantiles {"quantile", "value"}
+func (m *monitoringExporter) transformSummaries(name string, dataPoints pmetric.SummaryDataPointSlice, res metricResource) []transformedMetric {
+	var records []transformedMetric
+	for l := 0; l < dataPoints.Len(); l++ {
+		dp := dataPoints.At(l)
+		quantiles := make([]map[string]float64, 0, dp.QuantileValues().Len())
+		for q := 0; q < dp.QuantileValues().Len(); q++ {
+			qv := dp.QuantileValues().At(q)
+			quantiles = append(quantiles, map[string]float64{"quantile": qv.Quantile(), "value": qv.Value()})
+		}
+		value := map[string]interface{}{
+			"count":           dp.Count(),
+			"sum":             dp.Sum(),
+			"quantile_values": quantiles,
+		}
+		records = append(records, m.newMetricRecord(name, res, dp.Timestamp(), dp.Attributes(), value))
+	}
+	return records
+}
@@ -0,0 +1,159 @@
+package opentelemetryexportermonitoring
+
+import (
+	"context"
+	"encoding/json"
+	"math"
+	"testing"
+
+	"go.opentelemetry.io/collector/pdata/pmetric"
+)
+
+// knownHistogram tiene límites [10, 20, 40] y 100 muestras repartidas
+// 10/20/30/40 entre los cuatro buckets
+func knownHistogram() pmetric.HistogramDataPoint {
+	dp := pmetric.NewHistogramDataPoint()
+	dp.ExplicitBounds().FromRaw([]float64{10, 20, 40})
+	dp.BucketCounts().FromRaw([]uint64{10, 20, 30, 40})
+	dp.SetCount(100)
+	return dp
+}
+
+func TestHistogramPercentileKnownBuckets(t *testing.T) {
+	tests := []struct {
+		name string
+		q    float64
+		max  float64 // 0 = sin máximo
+		want float64
+	}{
+		{"borde del primer bucket", 0.10, 0, 10},
+		{"dentro del segundo bucket", 0.25, 0, 17.5},
+		{"mediana", 0.50, 0, 20 + 20*20.0/30},
+		{"bucket abierto sin max", 0.90, 0, 40},
+		{"bucket abierto con max", 0.90, 100, 85},
+		{"máximo", 1, 100, 100},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dp := knownHistogram()
+			if tt.max > 0 {
+				dp.SetMax(tt.max)
+			}
+			if got := histogramPercentile(dp, tt.q); math.Abs(got-tt.want) > 1e-9 {
+				t.Errorf("p%v = %v, se esperaba %v", tt.q*100, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestHistogramPercentileMinAndEmpty(t *testing.T) {
+	dp := knownHistogram()
+	dp.SetMin(5)
+	if got := histogramPercentile(dp, 0.05); got != 7.5 {
+		t.Errorf("p5 con min 5 = %v, se esperaba 7.5", got)
+	}
+	if got := histogramPercentile(pmetric.NewHistogramDataPoint(), 0.5); got != 0 {
+		t.Errorf("histograma vacío = %v, se esperaba 0", got)
+	}
+}
+
+func TestHistogramAsPercentilesRecord(t *testing.T) {
+	md := pmetric.NewMetrics()
+	metric := md.ResourceMetrics().AppendEmpty().ScopeMetrics().AppendEmpty().Metrics().AppendEmpty()
+	metric.SetName("latency")
+	knownHistogram().CopyTo(metric.SetEmptyHistogram().DataPoints().AppendEmpty())
+
+	cfg := testConfig(t, "http://localhost")
+	cfg.HistogramAsPercentiles = []float64{50, 99.9}
+	records, _, err := newTestExporter(t, cfg).transformMetrics(md)
+	if err != nil {
+		t.Fatal(err)
+	}
+	values, ok := records[0].Values["latency"].(map[string]interface{})
+	if !ok || len(values) != 2 {
+		t.Fatalf("values = %v, se esperaban p50 y p99.9", records[0].Values)
+	}
+	if _, ok := values["p99.9"]; !ok {
+		t.Errorf("falta p99.9: %v", values)
+	}
+	if got := values["p50"].(float64); math.Abs(got-(20+20*20.0/30)) > 1e-9 {
+		t.Errorf("p50 = %v", got)
+	}
+}
+
+func TestPushMetricsStructuredTypes(t *testing.T) {
+	tests := []struct {
+		name  string
+		build func(pmetric.Metric)
+		want  string
+	}{
+		{
+			name: "histogram",
+			build: func(m pmetric.Metric) {
+				dp := m.SetEmptyHistogram().DataPoints().AppendEmpty()
+				dp.SetCount(6)
+				dp.SetSum(42.5)
+				dp.BucketCounts().FromRaw([]uint64{1, 2, 3})
+				dp.ExplicitBounds().FromRaw([]float64{5, 10})
+			},
+			want: `{"bucket_counts":[1,2,3],"count":6,"explicit_bounds":[5,10],"sum":42.5}`,
+		},
+		{
+			name: "exponential_histogram",
+			build: func(m pmetric.Metric) {
+				dp := m.SetEmptyExponentialHistogram().DataPoints().AppendEmpty()
+				dp.SetCount(7)
+				dp.SetScale(2)
+				dp.SetZeroCount(1)
+				dp.Positive().SetOffset(-1)
+				dp.Positive().BucketCounts().FromRaw([]uint64{2, 3})
+				dp.Negative().BucketCounts().FromRaw([]uint64{1})
+			},
+			want: `{"count":7,"negative":{"bucket_counts":[1],"offset":0},"positive":{"bucket_counts":[2,3],"offset":-1},"scale":2,"zero_count":1}`,
+		},
+		{
+			name: "summary",
+			build: func(m pmetric.Metric) {
+				dp := m.SetEmptySummary().DataPoints().AppendEmpty()
+				dp.SetCount(10)
+				dp.SetSum(123)
+				q := dp.QuantileValues().AppendEmpty()
+				q.SetQuantile(0.5)
+				q.SetValue(11)
+				q = dp.QuantileValues().AppendEmpty()
+				q.SetQuantile(0.99)
+				q.SetValue(40)
+			},
+			want: `{"count":10,"quantile_values":[{"quantile":0.5,"value":11},{"quantile":0.99,"value":40}],"sum":123}`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			md := pmetric.NewMetrics()
+			metric := md.ResourceMetrics().AppendEmpty().ScopeMetrics().AppendEmpty().Metrics().AppendEmpty()
+			metric.SetName("latency")
+			tt.build(metric)
+
+			srv := newCaptureServer(t)
+			if err := newTestExporter(t, testConfig(t, srv.URL)).pushMetrics(context.Background(), md); err != nil {
+				t.Fatal(err)
+			}
+			reqs := srv.received()
+			if len(reqs) != 1 {
+				t.Fatalf("peticiones = %d, se esperaba 1", len(reqs))
+			}
+			var body struct {
+				Metrics []struct {
+					Values map[string]json.RawMessage `json:"values"`
+				} `json:"metrics"`
+			}
+			decodeJSON(t, reqs[0].Body, &body)
+			if len(body.Metrics) != 1 {
+				t.Fatalf("registros = %d, se esperaba 1: %s", len(body.Metrics), reqs[0].Body)
+			}
+			if got := string(body.Metrics[0].Values["latency"]); got != tt.want {
+				t.Errorf("values.latency =\n%s\nse esperaba\n%s", got, tt.want)
+			}
+		})
+	}
+}
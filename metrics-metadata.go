@@ -0,0 +1,73 @@
+package opentelemetryexportermonitoring
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync"
+
+	"go.opentelemetry.io/collector/pdata/pmetric"
+)
+
+// metricDefinition es lo que se registra una vez por métrica en metadata_endpoint
+type metricDefinition struct {
+	Name        string `json:"name"`
+	Type        string `json:"type"`
+	Unit        string `json:"unit,omitempty"`
+	Description string `json:"description,omitempty"`
+}
+
+// metricMetadataRegistry recuerda las métricas ya registradas
+type metricMetadataRegistry struct {
+	mu   sync.Mutex
+	seen map[string]bool
+}
+
+// registerMetricMetadata envía a metadata_endpoint las definiciones de las
+// métricas del lote que aún no se han registrado. Solo se marcan como vistas
+// si el POST va bien; si falla se devuelve el error y el lote se reintenta
+func (m *monitoringExporter) registerMetricMetadata(ctx context.Context, md pmetric.Metrics) error {
+	var defs []metricDefinition
+	pending := make(map[string]bool)
+
+	m.metadata.mu.Lock()
+	rms := md.ResourceMetrics()
+	for i := 0; i < rms.Len(); i++ {
+		sms := rms.At(i).ScopeMetrics()
+		for j := 0; j < sms.Len(); j++ {
+			metrics := sms.At(j).Metrics()
+			for k := 0; k < metrics.Len(); k++ {
+				metric := metrics.At(k)
+				if m.metadata.seen[metric.Name()] || pending[metric.Name()] {
+					continue
+				}
+				pending[metric.Name()] = true
+				defs = append(defs, metricDefinition{
+					Name:        metric.Name(),
+					Type:        metric.Type().String(),
+					Unit:        metric.Unit(),
+					Description: metric.Description(),
+				})
+			}
+		}
+	}
+	m.metadata.mu.Unlock()
+
+	if len(defs) == 0 {
+		return nil
+	}
+	body, err := json.Marshal(map[string]interface{}{"metrics": defs})
+	if err != nil {
+		return fmt.Errorf("error marshaling metric metadata: %w", err)
+	}
+	if err := m.postJSON(ctx, m.metadataEndpoint, body); err != nil {
+		return fmt.Errorf("error registering metric metadata: %w", err)
+	}
+
+	m.metadata.mu.Lock()
+	for name := range pending {
+		m.metadata.seen[name] = true
+	}
+	m.metadata.mu.Unlock()
+	return nil
+}
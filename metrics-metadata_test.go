@@ -0,0 +1,78 @@
+package opentelemetryexportermonitoring
+
+import (
+	"context"
+	"reflect"
+	"testing"
+)
+
+// registeredMetrics devuelve los nombres registrados en cada POST a /metadata
+func registeredMetrics(t *testing.T, srv *captureServer) [][]string {
+	t.Helper()
+	var out [][]string
+	for _, req := range srv.received() {
+		if req.Path != "/metadata" {
+			continue
+		}
+		var body struct {
+			Metrics []metricDefinition `json:"metrics"`
+		}
+		decodeJSON(t, req.Body, &body)
+		var names []string
+		for _, def := range body.Metrics {
+			names = append(names, def.Name)
+		}
+		out = append(out, names)
+	}
+	return out
+}
+
+func TestMetricMetadataPostedOnce(t *testing.T) {
+	srv := newCaptureServer(t)
+	cfg := testConfig(t, srv.URL)
+	cfg.MetadataEndpoint = srv.URL + "/metadata"
+	exp := newTestExporter(t, cfg)
+	ctx := context.Background()
+
+	for _, md := range [][]string{{"a", "b"}, {"a", "b", "c"}, {"c", "a"}} {
+		if err := exp.pushMetrics(ctx, gaugeMetrics(md...)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	want := [][]string{{"a", "b"}, {"c"}}
+	if got := registeredMetrics(t, srv); !reflect.DeepEqual(got, want) {
+		t.Errorf("registros de metadatos = %v, se esperaba %v", got, want)
+	}
+	if got := countPath(srv.received(), "/metrics"); got != 3 {
+		t.Errorf("POST de valores = %d, se esperaba uno por lote", got)
+	}
+}
+
+func TestMetricMetadataRetriedAfterFailure(t *testing.T) {
+	srv := newCaptureServer(t)
+	srv.respond(failFirst(1, "/metadata"))
+	cfg := testConfig(t, srv.URL)
+	cfg.MetadataEndpoint = srv.URL + "/metadata"
+	exp := newTestExporter(t, cfg)
+	ctx := context.Background()
+
+	if err := exp.pushMetrics(ctx, gaugeMetrics("a")); err == nil {
+		t.Fatal("se esperaba el error del registro fallido")
+	}
+	if got := countPath(srv.received(), "/metrics"); got != 0 {
+		t.Errorf("se enviaron %d POST de valores sin registrar los metadatos", got)
+	}
+	// El registro fallido no cuenta como visto: el reintento vuelve a enviarlo
+	if err := exp.pushMetrics(ctx, gaugeMetrics("a")); err != nil {
+		t.Fatal(err)
+	}
+	if got := registeredMetrics(t, srv); len(got) != 2 || !reflect.DeepEqual(got[1], []string{"a"}) {
+		t.Errorf("registros de metadatos = %v", got)
+	}
+	if err := exp.pushMetrics(ctx, gaugeMetrics("a")); err != nil {
+		t.Fatal(err)
+	}
+	if got := len(registeredMetrics(t, srv)); got != 2 {
+		t.Errorf("registros = %d, una métrica ya registrada no se reenvía", got)
+	}
+}
@@ -0,0 +1,107 @@
+package opentelemetryexportermonitoring
+
+import (
+	"encoding/json"
+	"math"
+	"sync"
+	"time"
+
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	"go.opentelemetry.io/collector/pdata/pmetric"
+)
+
+// rateSeriesTTL es el tiempo sin observaciones tras el que se olvida una
+// serie; las que desaparecen (pods reciclados, etiquetas de alta
+// cardinalidad) no se acumulan en memoria
+const rateSeriesTTL = 15 * time.Minute
+
+// ratePoint es la última observación de una serie acumulativa y la tasa que
+// produjo, que se repite si el mismo punto llega otra vez (reintento)
+type ratePoint struct {
+	value    float64
+	ts       pcommon.Timestamp
+	rate     float64
+	hasRate  bool
+	lastSeen time.Time
+}
+
+// rateTracker guarda por serie el último valor de los contadores acumulativos
+// para calcular su tasa por segundo entre dos exportaciones
+type rateTracker struct {
+	mu        sync.Mutex
+	series    map[string]ratePoint
+	lastSweep time.Time
+	now       func() time.Time
+}
+
+func newRateTracker() *rateTracker {
+	return &rateTracker{series: make(map[string]ratePoint), lastSweep: time.Now(), now: time.Now}
+}
+
+// rate registra el punto y devuelve (actual-anterior)/(ts-tsAnterior) en
+// unidades por segundo. ok=false si es la primera observación, si el tiempo no
+// avanza o si el contador se ha reiniciado (delta negativo). Un punto igual al
+// último registrado (el exporterhelper reintenta con el mismo lote) devuelve
+// la misma tasa que la primera vez
+func (r *rateTracker) rate(key string, value float64, ts pcommon.Timestamp) (float64, bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	now := r.now()
+	r.sweep(now)
+
+	prev, seen := r.series[key]
+	if seen && ts == prev.ts && value == prev.value {
+		prev.lastSeen = now
+		r.series[key] = prev
+		return prev.rate, prev.hasRate
+	}
+	point := ratePoint{value: value, ts: ts, lastSeen: now}
+	if seen && ts > prev.ts {
+		delta := value - prev.value
+		if delta >= 0 && !math.IsNaN(delta) && !math.IsInf(delta, 0) {
+			point.rate, point.hasRate = delta/(float64(ts-prev.ts)/1e9), true
+		}
+	}
+	r.series[key] = point
+	return point.rate, point.hasRate
+}
+
+// sweep olvida, como mucho una vez cada rateSeriesTTL, las series sin
+// observaciones en el último rateSeriesTTL; se llama con mu tomado
+func (r *rateTracker) sweep(now time.Time) {
+	if now.Sub(r.lastSweep) < rateSeriesTTL {
+		return
+	}
+	r.lastSweep = now
+	for key, point := range r.series {
+		if now.Sub(point.lastSeen) >= rateSeriesTTL {
+			delete(r.series, key)
+		}
+	}
+}
+
+// seriesKey identifica una serie por nombre, resource y atributos del punto;
+// json.Marshal ordena las claves, así que la clave no depende del orden. La
+// parte del resource se calcula una vez por resource (resourceKey)
+func seriesKey(name string, res metricResource, attrs pcommon.Map) string {
+	attrsJSON, _ := json.Marshal(attrs.AsRaw())
+	return name + "\x00" + res.key + "\x00" + string(attrsJSON)
+}
+
+// resourceKey es la parte del resource de seriesKey
+func resourceKey(attrs map[string]interface{}) string {
+	data, _ := json.Marshal(attrs)
+	return string(data)
+}
+
+// addRate añade "<nombre>.rate" a los valores del registro si el punto
+// pertenece a un contador acumulativo con observación previa
+func (m *monitoringExporter) addRate(record *transformedMetric, name string, res metricResource, dp pmetric.NumberDataPoint) {
+	value := dp.DoubleValue()
+	if dp.ValueType() != pmetric.NumberDataPointValueTypeDouble {
+		value = float64(dp.IntValue())
+	}
+	if r, ok := m.rates.rate(seriesKey(name, res, dp.Attributes()), value, dp.Timestamp()); ok {
+		record.Values[m.metricKey(name+".rate")] = r
+	}
+}
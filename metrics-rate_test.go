@@ -0,0 +1,100 @@
+package opentelemetryexportermonitoring
+
+import (
+	"testing"
+	"time"
+
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	"go.opentelemetry.io/collector/pdata/pmetric"
+)
+
+// cumulativeSum devuelve un lote con la suma acumulativa requests del host
+func cumulativeSum(host string, value int64, seconds int64) pmetric.Metrics {
+	md := pmetric.NewMetrics()
+	rm := md.ResourceMetrics().AppendEmpty()
+	rm.Resource().Attributes().PutStr("host.name", host)
+	metric := rm.ScopeMetrics().AppendEmpty().Metrics().AppendEmpty()
+	metric.SetName("requests")
+	sum := metric.SetEmptySum()
+	sum.SetAggregationTemporality(pmetric.AggregationTemporalityCumulative)
+	dp := sum.DataPoints().AppendEmpty()
+	dp.SetTimestamp(pcommon.Timestamp(seconds * int64(time.Second)))
+	dp.SetIntValue(value)
+	return md
+}
+
+func TestEmitRatesTwoIntervals(t *testing.T) {
+	cfg := testConfig(t, "http://localhost")
+	cfg.EmitRates = true
+	exp := newTestExporter(t, cfg)
+
+	steps := []struct {
+		host    string
+		value   int64
+		seconds int64
+		want    interface{} // nil = sin tasa
+	}{
+		{"h1", 100, 10, nil},         // primera observación
+		{"h1", 150, 20, 5.0},         // (150-100)/10s
+		{"h1", 150, 20, 5.0},         // reintento del mismo lote: misma tasa
+		{"h2", 40, 20, nil},          // otro resource, otra serie
+		{"h1", 160, 30, 1.0},         // (160-150)/10s
+		{"h1", 10, 40, nil},          // reinicio del contador
+		{"h1", 30, 50, 2.0},          // tras el reinicio
+		{"h2", 100, 50, 60.0 / 30.0}, // la serie de h2 sigue su propia historia
+	}
+	for i, step := range steps {
+		records, _, err := exp.transformMetrics(cumulativeSum(step.host, step.value, step.seconds))
+		if err != nil {
+			t.Fatal(err)
+		}
+		got, ok := records[0].Values["requests.rate"]
+		if step.want == nil {
+			if ok {
+				t.Errorf("paso %d: requests.rate = %v, no debía emitirse", i, got)
+			}
+			continue
+		}
+		if got != step.want {
+			t.Errorf("paso %d: requests.rate = %v, se esperaba %v", i, got, step.want)
+		}
+	}
+}
+
+func TestRateTrackerEvictsIdleSeries(t *testing.T) {
+	now := time.Unix(0, 0)
+	r := newRateTracker()
+	r.now = func() time.Time { return now }
+	r.lastSweep = now
+
+	r.rate("idle", 1, 1)
+	r.rate("active", 1, 1)
+	for i := 2; i <= 4; i++ {
+		now = now.Add(rateSeriesTTL / 2)
+		r.rate("active", float64(i), pcommon.Timestamp(i))
+	}
+	if _, ok := r.series["idle"]; ok {
+		t.Error("la serie sin datos durante rateSeriesTTL no se olvidó")
+	}
+	if _, ok := r.series["active"]; !ok {
+		t.Error("se olvidó una serie activa")
+	}
+	if rate, ok := r.rate("idle", 5, 10); ok {
+		t.Errorf("una serie olvidada empieza de cero, tasa %v", rate)
+	}
+}
+
+func TestSeriesKeyUsesPrecomputedResource(t *testing.T) {
+	attrs := map[string]interface{}{"host.name": "h1", "a": "b"}
+	res := metricResource{attrs: attrs, key: resourceKey(attrs)}
+	dp := pcommon.NewMap()
+	dp.PutStr("path", "/")
+	other := metricResource{attrs: map[string]interface{}{"host.name": "h2"}}
+	other.key = resourceKey(other.attrs)
+	if seriesKey("m", res, dp) == seriesKey("m", other, dp) {
+		t.Error("resources distintos comparten clave de serie")
+	}
+	if seriesKey("m", res, dp) != "m\x00"+`{"a":"b","host.name":"h1"}`+"\x00"+`{"path":"/"}` {
+		t.Errorf("seriesKey = %q", seriesKey("m", res, dp))
+	}
+}
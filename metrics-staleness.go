@@ -0,0 +1,87 @@
+package opentelemetryexportermonitoring
+
+import (
+	"sync"
+	"time"
+
+	"go.opentelemetry.io/collector/pdata/pcommon"
+)
+
+// staleSeriesTTL es el tiempo sin ver una serie tras el que se olvida, igual
+// que en emit_rates
+const staleSeriesTTL = rateSeriesTTL
+
+// staleSeries es el último timestamp enviado de una serie y cuántos envíos
+// seguidos lo han repetido
+type staleSeries struct {
+	ts       pcommon.Timestamp
+	repeats  int
+	lastSeen time.Time
+}
+
+// stalenessTracker detecta las series que se reenvían sin actualizar su
+// timestamp (por ejemplo, acumulados de un proceso parado)
+type stalenessTracker struct {
+	threshold int
+
+	mu        sync.Mutex
+	series    map[string]staleSeries
+	lastSweep time.Time
+	now       func() time.Time
+}
+
+func newStalenessTracker(threshold int) *stalenessTracker {
+	return &stalenessTracker{threshold: threshold, series: make(map[string]staleSeries), lastSweep: time.Now(), now: time.Now}
+}
+
+// stale indica si el punto sería el envío threshold con el mismo timestamp;
+// en cuanto el timestamp cambia vuelve a emitirse. No cuenta el envío: lo
+// hace commit cuando el POST va bien, así que ni los reintentos del
+// exporterhelper ni el reenvío de fallback suman envíos
+func (s *stalenessTracker) stale(key string, ts pcommon.Timestamp) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	now := s.now()
+	s.sweep(now)
+	prev, seen := s.series[key]
+	if !seen {
+		return false
+	}
+	prev.lastSeen = now
+	s.series[key] = prev
+	return prev.ts == ts && prev.repeats+1 >= s.threshold
+}
+
+// commit cuenta como enviados los registros de un lote ya aceptado
+func (s *stalenessTracker) commit(records []transformedMetric) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	now := s.now()
+	for _, r := range records {
+		if r.staleKey == "" {
+			continue
+		}
+		prev, seen := s.series[r.staleKey]
+		if seen && prev.ts == r.staleTS {
+			prev.repeats++
+		} else {
+			prev = staleSeries{ts: r.staleTS}
+		}
+		prev.lastSeen = now
+		s.series[r.staleKey] = prev
+	}
+}
+
+// sweep olvida, como mucho una vez cada staleSeriesTTL, las series que no se
+// han visto en el último staleSeriesTTL; se llama con mu tomado
+func (s *stalenessTracker) sweep(now time.Time) {
+	if now.Sub(s.lastSweep) < staleSeriesTTL {
+		return
+	}
+	s.lastSweep = now
+	for key, series := range s.series {
+		if now.Sub(series.lastSeen) >= staleSeriesTTL {
+			delete(s.series, key)
+		}
+	}
+}
@@ -0,0 +1,88 @@
+package opentelemetryexportermonitoring
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"go.opentelemetry.io/collector/pdata/pcommon"
+)
+
+func TestStalenessThreshold(t *testing.T) {
+	srv := newCaptureServer(t)
+	cfg := testConfig(t, srv.URL)
+	cfg.StaleAfterPushes = 2
+	exp := newTestExporter(t, cfg)
+
+	// Dos envíos con el mismo timestamp pasan, los siguientes se suprimen y
+	// el punto vuelve en cuanto cambia el timestamp
+	for i, tt := range []struct {
+		ts   pcommon.Timestamp
+		want int
+	}{{1, 1}, {1, 1}, {1, 0}, {1, 0}, {2, 1}} {
+		md := gaugeMetrics("m")
+		md.ResourceMetrics().At(0).ScopeMetrics().At(0).Metrics().At(0).Gauge().DataPoints().At(0).SetTimestamp(tt.ts)
+		if err := exp.pushMetrics(context.Background(), md); err != nil {
+			t.Fatal(err)
+		}
+		if got := len(metricRecords(t, srv)); got != tt.want {
+			t.Errorf("envío %d (ts=%d): registros = %d, se esperaban %d", i+1, tt.ts, got, tt.want)
+		}
+	}
+}
+
+func TestStalenessIgnoresRetries(t *testing.T) {
+	srv := newCaptureServer(t)
+	srv.respond(failFirst(2, "/metrics"))
+	cfg := testConfig(t, srv.URL)
+	cfg.StaleAfterPushes = 2
+	fastRetries(cfg)
+	_, metrics, _ := newFactoryExporters(t, cfg)
+
+	// El primer lote necesita tres intentos pero cuenta como un único envío
+	for i := 0; i < 2; i++ {
+		if err := metrics.ConsumeMetrics(context.Background(), gaugeMetrics("m")); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if got := countPath(srv.received(), "/metrics"); got != 4 {
+		t.Fatalf("peticiones = %d, se esperaban 3 intentos y el segundo envío", got)
+	}
+	if got := len(metricRecords(t, srv)); got != 1 {
+		t.Errorf("el segundo envío lleva %d registros; los reintentos no deben contar", got)
+	}
+}
+
+// metricRecords devuelve los registros de la última petición recibida
+func metricRecords(t *testing.T, srv *captureServer) []json.RawMessage {
+	t.Helper()
+	reqs := srv.received()
+	var body struct {
+		Metrics []json.RawMessage `json:"metrics"`
+	}
+	decodeJSON(t, reqs[len(reqs)-1].Body, &body)
+	return body.Metrics
+}
+
+func TestStalenessExpiresIdleSeries(t *testing.T) {
+	now := time.Unix(0, 0)
+	s := newStalenessTracker(1)
+	s.now = func() time.Time { return now }
+	s.lastSweep = now
+
+	s.commit([]transformedMetric{{staleKey: "a", staleTS: 1}, {staleKey: "b", staleTS: 1}})
+	if !s.stale("a", 1) {
+		t.Fatal("con umbral 1 el segundo envío del mismo timestamp es obsoleto")
+	}
+	now = now.Add(staleSeriesTTL / 2)
+	s.stale("a", 1)
+	now = now.Add(staleSeriesTTL / 2)
+	s.stale("a", 1)
+	if _, ok := s.series["b"]; ok {
+		t.Error("la serie b lleva staleSeriesTTL sin verse y debía olvidarse")
+	}
+	if _, ok := s.series["a"]; !ok {
+		t.Error("la serie a se ha visto hace poco y debía conservarse")
+	}
+}
@@ -0,0 +1,42 @@
+package opentelemetryexportermonitoring
+
+import (
+	"fmt"
+	"net/http"
+	"sync"
+)
+
+// Middleware envuelve el RoundTripper del exporter para añadir lógica por
+// petición (cabeceras, métricas, trazas...) sin modificar el exporter
+type Middleware func(next http.RoundTripper) http.RoundTripper
+
+var (
+	middlewaresMu sync.RWMutex
+	middlewares   = map[string]Middleware{}
+)
+
+// RegisterMiddleware registra un middleware con un nombre para poder
+// activarlo desde la configuración (middlewares). Debe llamarse antes de
+// crear los exporters, normalmente desde un init()
+func RegisterMiddleware(name string, mw Middleware) {
+	middlewaresMu.Lock()
+	defer middlewaresMu.Unlock()
+	middlewares[name] = mw
+}
+
+// chainMiddlewares compone los middlewares sobre base; el primero de la lista
+// es el más externo y ve la petición antes que los demás
+func chainMiddlewares(base http.RoundTripper, names []string) (http.RoundTripper, error) {
+	middlewaresMu.RLock()
+	defer middlewaresMu.RUnlock()
+
+	rt := base
+	for i := len(names) - 1; i >= 0; i-- {
+		mw, ok := middlewares[names[i]]
+		if !ok {
+			return nil, fmt.Errorf("middleware no registrado: %q", names[i])
+		}
+		rt = mw(rt)
+	}
+	return rt, nil
+}
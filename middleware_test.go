@@ -0,0 +1,68 @@
+package opentelemetryexportermonitoring
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"testing"
+)
+
+// roundTripperFunc adapta una función a http.RoundTripper
+type roundTripperFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripperFunc) RoundTrip(req *http.Request) (*http.Response, error) { return f(req) }
+
+// recordingMiddleware anota con su nombre cada petición que ve en order
+func recordingMiddleware(name string, mu *sync.Mutex, order *[]string) Middleware {
+	return func(next http.RoundTripper) http.RoundTripper {
+		return roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+			mu.Lock()
+			*order = append(*order, name+" "+req.URL.Path)
+			mu.Unlock()
+			req.Header.Set("X-"+name, "1")
+			return next.RoundTrip(req)
+		})
+	}
+}
+
+func TestMiddlewareObservesEachRequest(t *testing.T) {
+	var mu sync.Mutex
+	var order []string
+	RegisterMiddleware("test-outer", recordingMiddleware("outer", &mu, &order))
+	RegisterMiddleware("test-inner", recordingMiddleware("inner", &mu, &order))
+
+	srv := newCaptureServer(t)
+	cfg := testConfig(t, srv.URL)
+	cfg.Middlewares = []string{"test-outer", "test-inner"}
+	exp := newTestExporter(t, cfg)
+	ctx := context.Background()
+	if err := exp.pushMetrics(ctx, gaugeMetrics("m")); err != nil {
+		t.Fatal(err)
+	}
+	if err := exp.pushLogs(ctx, logsWithResources(1, "svc")); err != nil {
+		t.Fatal(err)
+	}
+
+	want := []string{"outer /metrics", "inner /metrics", "outer /logs", "inner /logs"}
+	if len(order) != len(want) {
+		t.Fatalf("middlewares = %v, se esperaba %v", order, want)
+	}
+	for i := range want {
+		if order[i] != want[i] {
+			t.Errorf("paso %d = %q, se esperaba %q", i, order[i], want[i])
+		}
+	}
+	for _, req := range srv.received() {
+		if req.Header.Get("X-outer") != "1" || req.Header.Get("X-inner") != "1" {
+			t.Errorf("%s: las cabeceras de los middlewares no llegaron al servidor", req.Path)
+		}
+	}
+}
+
+func TestMiddlewareNotRegistered(t *testing.T) {
+	cfg := testConfig(t, "http://localhost")
+	cfg.Middlewares = []string{"no-existe"}
+	if _, err := newMonitoringExporter(cfg, nil); err == nil {
+		t.Error("se esperaba un error con un middleware no registrado")
+	}
+}
@@ -6,18 +6,32 @@ import (
 	"crypto/tls"
 	"crypto/x509"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"hash/fnv"
+	"io"
+	"math"
 	"net/http"
+	"net/url"
 	"os"
+	"regexp"
+	"sort"
+	"strconv"
 	"strings"
+	"sync"
+	"sync/atomic"
 	"time"
+	"unicode/utf8"
 
+	"github.com/google/uuid"
 	"go.uber.org/zap"
 
 	//	"opentelemetryexportermonitoring/muclient"
 
 	"go.opentelemetry.io/collector/component"
 	"go.opentelemetry.io/collector/config/configretry"
+	"go.opentelemetry.io/collector/config/configtls"
+	"go.opentelemetry.io/collector/consumer/consumererror"
 	"go.opentelemetry.io/collector/exporter"
 	"go.opentelemetry.io/collector/exporter/exporterhelper"
 
@@ -33,6 +47,14 @@ var typeStr = component.MustNewType("monitoring")
 // define the stability level of the exporter actually "Beta"
 const stability = component.StabilityLevelBeta
 
+// LogSeverityRoute envía los logs con severidad entre MinSeverity y
+// MaxSeverity (0 = sin límite superior) a Endpoint
+type LogSeverityRoute struct {
+	MinSeverity int    `mapstructure:"min_severity"`
+	MaxSeverity int    `mapstructure:"max_severity"`
+	Endpoint    string `mapstructure:"endpoint"`
+}
+
 type Config struct {
 	// Namespace de usuario en Atenea (obligatorio)
 	NS string `mapstructure:"ns"`
@@ -51,9 +73,356 @@ type Config struct {
 	MaxLines     int    `mapstructure:"max_lines"`
 	MaxBodyBytes int    `mapstructure:"max_body_bytes"`
 
+	// Sanea los nombres de métrica usados como clave en "values"
+	SanitizeMetricNames bool `mapstructure:"sanitize_metric_names"`
+	// Caracteres permitidos en nombres de métrica (clase de regexp sin corchetes)
+	MetricNameAllowedChars string `mapstructure:"metric_name_allowed_chars"`
+	// Emite un registro con "values" vacío si un resource no produce registros (latido)
+	HeartbeatOnEmpty bool `mapstructure:"heartbeat_on_empty"`
+
+	// URLs plantilla por señal (opcional). Los marcadores {clave} se sustituyen
+	// por region/ns/mrid/metricsets o por atributos ({service} = service.name)
+	TracesURLTemplate  string `mapstructure:"traces_url_template"`
+	MetricsURLTemplate string `mapstructure:"metrics_url_template"`
+	LogsURLTemplate    string `mapstructure:"logs_url_template"`
+
+	// Deduplica los resources de logs en un array "resources" referenciado por índice
+	DedupLogResources bool `mapstructure:"dedup_log_resources"`
+
+	// Cabecera con un UUID por lote, repetido en el payload como "batchId"
+	BatchIDHeader string `mapstructure:"batch_id_header"`
+
+	// Cabecera con una clave estable por POST (misma en los reintentos) para
+	// que el backend descarte duplicados, p. ej. "Idempotency-Key"
+	IdempotencyKeyHeader string `mapstructure:"idempotency_key_header"`
+
+	// Zona horaria IANA para las fechas RFC3339 (por defecto UTC)
+	TimestampTimezone string `mapstructure:"timestamp_timezone"`
+
+	// Tratamiento de valores NaN/Inf en métricas: "error", "null", "skip" o "string"
+	NonFiniteHandling string `mapstructure:"non_finite_handling"`
+	// Clave bajo la que se emiten los atributos del punto ("attributes" por
+	// defecto o, p. ej., "labels"); vacío los mezcla en properties. No puede
+	// coincidir con un campo del registro (timestamp, properties, values...)
+	DataPointAttributesKey string `mapstructure:"data_point_attributes_key"`
+
+	// Petición OPTIONS previa al POST (gateways con CORS); el resultado se cachea por URL
+	Preflight    bool          `mapstructure:"preflight"`
+	PreflightTTL time.Duration `mapstructure:"preflight_ttl"`
+
+	// Decimales a los que se redondean los valores double de métricas (-1 = sin redondeo)
+	DecimalPlaces int `mapstructure:"decimal_places"`
+
+	// Diccionario de claves de atributo -> código corto. El diccionario inverso
+	// (código -> clave) se envía en KeyDictionaryHeader en el primer POST a cada URL
+	KeyDictionary       map[string]string `mapstructure:"key_dictionary"`
+	KeyDictionaryHeader string            `mapstructure:"key_dictionary_header"`
+
+	// Codificación por señal: "custom" (formato Atenea) u "otlp_json"
+	TracesEncoding  string `mapstructure:"traces_encoding"`
+	MetricsEncoding string `mapstructure:"metrics_encoding"`
+	LogsEncoding    string `mapstructure:"logs_encoding"`
+
+	// Descarta los registros de log sin body
+	RequireLogBody bool `mapstructure:"require_log_body"`
+
+	// Emite los atributos del resource como un string JSON en el campo "resource"
+	ResourceAsString bool `mapstructure:"resource_as_string"`
+
+	// Reintentos por señal; con false la señal descarta el lote al primer fallo
+	TracesRetryEnabled  bool `mapstructure:"traces_retry_enabled"`
+	MetricsRetryEnabled bool `mapstructure:"metrics_retry_enabled"`
+	LogsRetryEnabled    bool `mapstructure:"logs_retry_enabled"`
+
+	// Añade el hostname del collector ("collector_host") a cada payload
+	IncludeHostname bool `mapstructure:"include_hostname"`
+
+	// Prefijo de ruta entre el host y el sufijo de la señal (vacío: /v1 en
+	// spans y logs, /v0 en métricas) y sufijos por señal con marcadores {clave}
+	PathPrefix        string `mapstructure:"path_prefix"`
+	TracesPathSuffix  string `mapstructure:"traces_path_suffix"`
+	MetricsPathSuffix string `mapstructure:"metrics_path_suffix"`
+	LogsPathSuffix    string `mapstructure:"logs_path_suffix"`
+
+	// Agrupa code.filepath/code.lineno/code.function de los logs en "source"
+	HoistLogSource bool `mapstructure:"hoist_log_source"`
+
+	// Máximo de resoluciones DNS concurrentes (0 = sin límite)
+	MaxConcurrentDNS int `mapstructure:"max_concurrent_dns"`
+
+	// Percentiles (0-100] calculados a partir de los buckets de los histogramas
+	HistogramAsPercentiles []float64 `mapstructure:"histogram_as_percentiles"`
+
+	// Firma AWS SigV4 de las peticiones (activa si sigv4.service no está vacío)
+	SigV4 SigV4Config `mapstructure:"sigv4"`
+
+	// Ordena los logs de cada POST por timestamp
+	SortLogsByTimestamp bool `mapstructure:"sort_logs_by_timestamp"`
+
+	// Cabecera de respuesta con un código de estado estilo gRPC (p.ej.
+	// "Grpc-Status"); un valor distinto de 0 es un error aunque el HTTP sea 2xx.
+	// Los códigos de StatusHeaderRetryableCodes se reintentan, el resto son permanentes
+	StatusHeader               string `mapstructure:"status_header"`
+	StatusHeaderRetryableCodes []int  `mapstructure:"status_header_retryable_codes"`
+
+	// Calcula la tasa por segundo de las sumas acumulativas ("<nombre>.rate");
+	// las series sin datos durante 15 minutos se olvidan
+	EmitRates bool `mapstructure:"emit_rates"`
+
+	// Ventana de acumulación antes del POST (0 = desactivada) y tamaño máximo
+	// del buffer en bytes antes de vaciarlo anticipadamente
+	BatchWindow         time.Duration `mapstructure:"batch_window"`
+	BatchWindowMaxBytes int           `mapstructure:"batch_window_max_bytes"`
+
+	// Renombrado de claves de atributo (clave OTel -> nombre del sink) y tabla
+	// de convenciones semánticas incorporada (k8s.pod.name -> pod, ...)
+	AttributeRenames    map[string]string `mapstructure:"attribute_renames"`
+	ApplySemconvMapping bool              `mapstructure:"apply_semconv_mapping"`
+
+	// Tamaño máximo del body de log en bytes (0 = sin límite); los body
+	// estructurados se miden ya serializados a JSON
+	MaxLogBodyBytes int `mapstructure:"max_log_body_bytes"`
+
+	// Añade el campo "signal" ("trace", "metric" o "log") a los payloads custom
+	IncludeSignalType bool `mapstructure:"include_signal_type"`
+
+	// Nombre bajo el que se publican por expvar las estadísticas de conexión
+	// (conexiones activas, peticiones y bytes enviados); vacío = desactivado
+	ExpvarName string `mapstructure:"expvar_name"`
+
+	// Incluye los exemplars de los puntos de sumas y gauges; con
+	// ExemplarsSampledOnly solo los asociados a una traza muestreada
+	IncludeExemplars     bool `mapstructure:"include_exemplars"`
+	ExemplarsSampledOnly bool `mapstructure:"exemplars_sampled_only"`
+
+	// Timeout proporcional al tamaño del body: base + bytes/throughput. Si
+	// RequestBaseTimeout > 0 sustituye al timeout fijo
+	RequestBaseTimeout    time.Duration `mapstructure:"request_base_timeout"`
+	RequestBytesPerSecond int           `mapstructure:"request_bytes_per_second"`
+
+	// Sustituye las properties de cada log por referencias a un diccionario
+	// de pares clave/valor por lote ("attributes" + "properties_ref")
+	DedupLogAttributes bool `mapstructure:"dedup_log_attributes"`
+
+	// Deriva de las trazas métricas RED (llamadas, errores y latencia) por
+	// servicio, span y kind y las envía al endpoint de métricas después de los
+	// spans. Los spans solo se envían además si traces está activo. Requiere
+	// traces_encoding custom
+	TracesToMetrics        bool            `mapstructure:"traces_to_metrics"`
+	TracesToMetricsBuckets []time.Duration `mapstructure:"traces_to_metrics_buckets"`
+
+	// Envía como objeto los body de log que son texto JSON (objeto o array);
+	// si no se pueden parsear se mantiene el texto
+	ParseJSONLogBodies bool `mapstructure:"parse_json_log_bodies"`
+
+	// Claves de atributo usadas como etiquetas de stream con logs_encoding: loki
+	LokiLabels []string `mapstructure:"loki_labels"`
+
+	// Middlewares registrados con RegisterMiddleware que envuelven el
+	// transporte, en orden (el primero es el más externo)
+	Middlewares []string `mapstructure:"middlewares"`
+
+	// Comportamiento con la cola de envío llena: "block" o "drop_newest"
+	// (descarta el lote entrante y lo cuenta); vacío = sending_queue.block_on_overflow.
+	// "drop_oldest" no está soportado: la cola del exporterhelper no permite
+	// sacar el lote más antiguo, así que se rechaza al crear el exporter
+	QueueFullPolicy string `mapstructure:"queue_full_policy"`
+
+	// Cabecera con el SHA-256 del payload canonicalizado, estable entre
+	// reintentos, para deduplicación en el sink (vacío = desactivado)
+	ContentDigestHeader string `mapstructure:"content_digest_header"`
+
+	// Espera fija antes de reintentar un 425 Too Early (ventana de ingesta
+	// del sink aún cerrada)
+	TooEarlyRetryDelay time.Duration `mapstructure:"too_early_retry_delay"`
+
+	// Endpoint propio por nombre de métrica; el resto va a la URL por defecto
+	MetricEndpointOverrides map[string]string `mapstructure:"metric_endpoint_overrides"`
+
+	// Modo combinado: las tres señales se acumulan durante CombinedWindow (o
+	// hasta CombinedMaxItems registros) y se envían juntas a CombinedEndpoint
+	CombinedEndpoint string        `mapstructure:"combined_endpoint"`
+	CombinedWindow   time.Duration `mapstructure:"combined_window"`
+	CombinedMaxItems int           `mapstructure:"combined_max_items"`
+
+	// Enrutado de logs por rango de severidad (SeverityNumber de OTel: 1-4
+	// TRACE, 5-8 DEBUG, 9-12 INFO, 13-16 WARN, 17-20 ERROR, 21-24 FATAL); gana
+	// la primera ruta que coincida y el resto va a la URL por defecto
+	LogSeverityRoutes []LogSeverityRoute `mapstructure:"log_severity_routes"`
+
+	// Cifrado del payload (AES-GCM) antes del envío
+	Encryption EncryptionConfig `mapstructure:"encryption"`
+
+	// Envía los spans como objeto con el número de spans por trace ID
+	IncludeTraceSpanCounts bool `mapstructure:"include_trace_span_counts"`
+
+	// Reglas que deciden por el body JSON de la respuesta si un envío es
+	// reintentable o permanente (sinks que responden 200 con error)
+	RetryClassification []RetryClassificationRule `mapstructure:"retry_classification"`
+
+	// Añade a cada registro de métrica un hash estable de la serie (nombre +
+	// atributos del punto) en "series_hash"
+	IncludeSeriesHash bool `mapstructure:"include_series_hash"`
+
+	// Compresión del body: "none" (por defecto) o "gzip"
+	Compression string `mapstructure:"compression"`
+
+	// Destinos adicionales; si hay alguno, cada push se reparte entre ellos
+	Sinks []SinkConfig `mapstructure:"sinks"`
+
+	// Añade a cada span los IDs en hex, kind, estado, los atributos de
+	// resource y scope, y los eventos y enlaces
+	IncludeSpanDetails bool `mapstructure:"include_span_details"`
+
+	// Marca con contains_pii los logs cuyo body o atributos coinciden con
+	// algún patrón de PIIPatterns (por defecto emails y SSN)
+	DetectPII   bool     `mapstructure:"detect_pii"`
+	PIIPatterns []string `mapstructure:"pii_patterns"`
+
+	// Percentiles de duración por operación (servicio + span) calculados
+	// sobre ventanas de SpanLatencyWindow y enviados como métricas (0 = desactivado)
+	SpanLatencyWindow      time.Duration `mapstructure:"span_latency_window"`
+	SpanLatencyPercentiles []float64     `mapstructure:"span_latency_percentiles"`
+	SpanLatencyMaxSamples  int           `mapstructure:"span_latency_max_samples"`
+
+	// Incluye el conjunto de atributos del punto en la clave de "values"
+	// (nombre{k1=v1,k2=v2}) para que series distintas no compartan clave
+	KeyDataPointsByAttributes bool `mapstructure:"key_data_points_by_attributes"`
+
+	// Envía solo el punto más reciente de cada serie de gauges y sumas
+	LatestOnly bool `mapstructure:"latest_only"`
+
+	// Codificación con la que se reenvía una vez el lote si el sink rechaza
+	// el payload con un código de FallbackOnStatus (y, si se indica, un body
+	// que contiene FallbackBodyContains)
+	FallbackEncoding     string `mapstructure:"fallback_encoding"`
+	FallbackOnStatus     []int  `mapstructure:"fallback_on_status"`
+	FallbackBodyContains string `mapstructure:"fallback_body_contains"`
+
+	// Junta los POST JSON a una misma URL recibidos durante CoalesceWindow
+	// (0 = desactivado) en una única petición de hasta CoalesceMaxBytes (o
+	// MaxPayloadBytes si es menor)
+	CoalesceWindow   time.Duration `mapstructure:"coalesce_window"`
+	CoalesceMaxBytes int           `mapstructure:"coalesce_max_bytes"`
+
+	// Si se indica, las definiciones de las métricas (name, type, unit,
+	// description) se registran una sola vez en este endpoint
+	MetadataEndpoint string `mapstructure:"metadata_endpoint"`
+
+	// Si se indica, por cada lote de traces se envían aquí las aristas
+	// caller -> callee entre servicios derivadas de los spans
+	DependencyEndpoint string `mapstructure:"dependency_endpoint"`
+
+	// Fracción de trazas [0, 1] que se envían, decidida por el hash del
+	// trace ID. Las métricas derivadas de los spans se calculan antes del
+	// muestreo. Con TraceSampleKeepErrors las trazas con errores se envían siempre
+	TraceSampleRate       float64 `mapstructure:"trace_sample_rate"`
+	TraceSampleKeepErrors bool    `mapstructure:"trace_sample_keep_errors"`
+
+	// Con batch_window, ajusta el tamaño máximo del buffer entre
+	// AdaptiveBatchMinBytes y AdaptiveBatchMaxBytes según la latencia de los
+	// envíos respecto a AdaptiveBatchTargetLatency (0 = tamaño fijo)
+	AdaptiveBatchTargetLatency time.Duration `mapstructure:"adaptive_batch_target_latency"`
+	AdaptiveBatchMinBytes      int           `mapstructure:"adaptive_batch_min_bytes"`
+	AdaptiveBatchMaxBytes      int           `mapstructure:"adaptive_batch_max_bytes"`
+
+	// Agrupa los logs de cada envío por plantilla de mensaje (números, IDs y
+	// cadenas sustituidos) y nivel, y envía {template, level, count,
+	// examples} con hasta LogTemplateExamples mensajes de ejemplo
+	AggregateLogTemplates bool `mapstructure:"aggregate_log_templates"`
+	LogTemplateExamples   int  `mapstructure:"log_template_examples"`
+
+	// Deja de emitir las series de sumas y gauges cuyo timestamp no cambia en
+	// StaleAfterPushes envíos seguidos, hasta que se actualicen (0 = desactivado)
+	StaleAfterPushes int `mapstructure:"stale_after_pushes"`
+
+	// Serializa los payloads JSON en forma canónica (claves ordenadas, sin
+	// espacios, números estables): mismos datos, mismos bytes
+	Canonical bool `mapstructure:"canonical"`
+
+	// Al arrancar hace un HEAD al host del endpoint y avisa si no responde
+	StartupProbe bool `mapstructure:"startup_probe"`
+
+	// Formato por defecto de las tres señales: "custom", "otlp_json" u
+	// "otlp_proto". Las *_encoding con un valor distinto de "custom" tienen
+	// prioridad
+	Format string `mapstructure:"format"`
+
+	// Tamaño máximo del body JSON; los lotes mayores se parten en varios POST
+	// por registros (0 = sin límite)
+	MaxPayloadBytes int `mapstructure:"max_payload_bytes"`
+
+	// Nombres de span que se incluyen en el log debug de cada lote de traces
+	// (0 = desactivado, -1 = todos)
+	SampleSpanNames int `mapstructure:"sample_span_names"`
+
+	// Formato de los timestamps del JSON propio: "unix_nano" (por defecto),
+	// "unix_millis" o "rfc3339" (en timestamp_timezone); los 0 se emiten null
+	TimestampFormat string `mapstructure:"timestamp_format"`
+
+	// Claves de atributo (resource, scope, punto, span y log) que se
+	// serializan: solo las de AttributeAllowlist o todas salvo las de
+	// AttributeDenylist. Si se indican ambas gana la allowlist. Las
+	// codificaciones OTLP envían los atributos sin filtrar
+	AttributeAllowlist []string `mapstructure:"attribute_allowlist"`
+	AttributeDenylist  []string `mapstructure:"attribute_denylist"`
+
+	// Claves de atributo cuyo valor se sustituye por RedactionPlaceholder,
+	// también dentro de mapas anidados
+	RedactAttributes     []string `mapstructure:"redact_attributes"`
+	RedactionPlaceholder string   `mapstructure:"redaction_placeholder"`
+
+	// Los atributos bytes se emiten como string base64; con BytesDataPrefix
+	// llevan delante "data:application/octet-stream;base64,"
+	BytesDataPrefix bool `mapstructure:"bytes_data_prefix"`
+
+	// Valor que se emite para los atributos vacíos (ValueTypeEmpty), para
+	// distinguirlos de una clave ausente. Por defecto "": hasta ahora salían
+	// null, así que los consumidores que comprobaban null deben comprobar
+	// también el string vacío (o este valor si se configura otro)
+	EmptyAttributeValue string `mapstructure:"empty_attribute_value"`
+
+	// Prefijo que se antepone al nombre de cada métrica en "values" (p. ej.
+	// "myprefix." convierte cpu.usage en myprefix.cpu.usage)
+	MetricNamePrefix string `mapstructure:"metric_name_prefix"`
+
+	// Método HTTP de los envíos: POST (por defecto), PUT o PATCH, con
+	// sustitución opcional por señal
+	Method        string `mapstructure:"method"`
+	TracesMethod  string `mapstructure:"traces_method"`
+	MetricsMethod string `mapstructure:"metrics_method"`
+	LogsMethod    string `mapstructure:"logs_method"`
+
+	// TLS del cliente (CA, certificado de cliente, insecure_skip_verify). Si
+	// se indica sustituye a ca_cert_file, client_cert_file y client_key_file
+	TLS *configtls.ClientConfig `mapstructure:"tls"`
+
+	// Autenticación de las peticiones (bearer token o basic auth)
+	Auth AuthConfig `mapstructure:"auth"`
+
+	// Firma HMAC del body de cada petición (activa si signing.secret no está vacío)
+	Signing SigningConfig `mapstructure:"signing"`
+
+	// Corta los envíos durante un tiempo si el backend falla seguido
+	CircuitBreaker CircuitBreakerConfig `mapstructure:"circuit_breaker"`
+
+	// Proxy de salida (http, https o socks5); sin él se usan las variables
+	// de entorno HTTP_PROXY, HTTPS_PROXY y NO_PROXY
+	ProxyURL string `mapstructure:"proxy_url"`
+
+	// Atributo de resource (p. ej. "tenant.id") que decide si se usan las
+	// *_url_template: los resources sin él van a la URL por defecto
+	EndpointFromAttribute string `mapstructure:"endpoint_from_attribute"`
+
 	// Cabeceras HTTP opcionales
 	Headers map[string]string `mapstructure:"headers"`
 
+	// Cabeceras de cada señal; se añaden a Headers y tienen prioridad sobre
+	// ellas y sobre el Authorization de auth
+	TracesHeaders  map[string]string `mapstructure:"traces_headers"`
+	MetricsHeaders map[string]string `mapstructure:"metrics_headers"`
+	LogsHeaders    map[string]string `mapstructure:"logs_headers"`
+
 	CaCertFile     string `mapstructure:"ca_cert_file"`
 	ClientCertFile string `mapstructure:"client_cert_file"`
 	ClientKeyFile  string `mapstructure:"client_key_file"`
@@ -93,9 +462,59 @@ func createDefaultConfig() component.Config {
 		LogFile:        "/var/log/otel_failed_requests.log",
 		MaxLines:       30000,
 		MaxBodyBytes:   2048,
+
+		SanitizeMetricNames:    false,
+		MetricNameAllowedChars: "a-zA-Z0-9_",
+		HeartbeatOnEmpty:       false,
+		TimestampTimezone:      "UTC",
+		NonFiniteHandling:      "null",
+		Preflight:              false,
+		PreflightTTL:           5 * time.Minute,
+		DecimalPlaces:          -1,
+		KeyDictionaryHeader:    "X-Key-Dictionary",
+		TracesEncoding:         encodingCustom,
+		MetricsEncoding:        encodingCustom,
+		LogsEncoding:           encodingCustom,
+		TracesRetryEnabled:     true,
+		MetricsRetryEnabled:    true,
+		LogsRetryEnabled:       true,
+		TracesPathSuffix:       defaultTracesPathSuffix,
+		MetricsPathSuffix:      defaultMetricsPathSuffix,
+		LogsPathSuffix:         defaultLogsPathSuffix,
+		// CANCELLED, DEADLINE_EXCEEDED, RESOURCE_EXHAUSTED, ABORTED, UNAVAILABLE
+		StatusHeaderRetryableCodes: []int{1, 4, 8, 10, 14},
+		BatchWindowMaxBytes:        8 << 20,
+		RequestBytesPerSecond:      1 << 20,
+		LokiLabels:                 []string{"service.name", "level"},
+		TooEarlyRetryDelay:         time.Second,
+		CombinedWindow:             time.Second,
+		CombinedMaxItems:           1000,
+		Compression:                compressionNone,
+		SpanLatencyMaxSamples:      10000,
+		FallbackOnStatus:           []int{400, 422},
+		CoalesceMaxBytes:           1 << 20,
+		TraceSampleRate:            1,
+		AdaptiveBatchMinBytes:      64 << 10,
+		AdaptiveBatchMaxBytes:      32 << 20,
+		LogTemplateExamples:        3,
+		Format:                     encodingCustom,
+		SampleSpanNames:            10,
+		TimestampFormat:            timestampUnixNano,
+		RedactionPlaceholder:       "REDACTED",
+		Signing:                    SigningConfig{Header: "X-Signature", Algorithm: "sha256"},
+		Method:                     http.MethodPost,
+		CircuitBreaker:             CircuitBreakerConfig{Window: time.Minute, Cooldown: 30 * time.Second},
+		DataPointAttributesKey:     "attributes",
 	}
 }
 
+// Sufijos de ruta por defecto de cada señal
+const (
+	defaultTracesPathSuffix  = "/ns/{ns}/mrs/{mrid}/spans"
+	defaultMetricsPathSuffix = "/ns/{ns}/metric-sets/{metricsets}:addMeasurements"
+	defaultLogsPathSuffix    = "/ns/{ns}/logs"
+)
+
 func sanitizeName(name string) string {
 	// Reemplazar caracteres no permitidos por Atenea
 	name = strings.ReplaceAll(name, ".", "_")
@@ -104,45 +523,275 @@ func sanitizeName(name string) string {
 }
 
 func createTracesExporter(ctx context.Context, set exporter.Settings, cfg component.Config) (exporter.Traces, error) {
-	c := cfg.(*Config)
-	exp, err := newMonitoringExporter(c, set.Logger)
+	c := cfg.(*Config).withFormat()
+	var exp *monitoringExporter
+	var push func(context.Context, ptrace.Traces) error
+	var err error
+	if len(c.Sinks) > 0 {
+		exp, push, err = newSinks(c, set, "traces", newTracesPipeline)
+	} else {
+		exp, push, err = newTracesPipeline(c, set)
+	}
 	if err != nil {
 		return nil, err
 	}
-	return exporterhelper.NewTraces(
-		ctx, set, cfg, exp.pushTraces,
-		exporterhelper.WithTimeout(c.TimeoutConfig),
-		exporterhelper.WithQueue(c.QueueSettings),
-		exporterhelper.WithRetry(c.RetrySettings),
-	)
+	if c.CombinedEndpoint != "" {
+		combined := acquireCombinedBuffer(set.ID, exp, c)
+		push = combined.pushTraces
+		exp.addLifecycle(combined.start, combined.shutdown)
+	}
+	if c.BatchWindow > 0 {
+		buf := newTracesWindowBuffer(c, set.Logger, push)
+		push = buf.push
+		exp.addLifecycle(buf.start, buf.shutdown)
+	}
+	if c.IdempotencyKeyHeader != "" {
+		push = idempotentPush(push)
+	}
+	opts := exporterOptions(c, exp, c.TracesRetryEnabled)
+	e, err := exporterhelper.NewTraces(ctx, set, cfg, push, opts...)
+	if err != nil {
+		return nil, err
+	}
+	if c.QueueFullPolicy == queueFullDropNewest {
+		e = queueDropTraces{Traces: e, exp: exp}
+	}
+	if c.IdempotencyKeyHeader != "" {
+		e = idempotentTraces{e}
+	}
+	return e, nil
 }
 
 func createMetricsExporter(ctx context.Context, set exporter.Settings, cfg component.Config) (exporter.Metrics, error) {
-	c := cfg.(*Config)
-	exp, err := newMonitoringExporter(c, set.Logger)
+	c := cfg.(*Config).withFormat()
+	var exp *monitoringExporter
+	var push func(context.Context, pmetric.Metrics) error
+	var err error
+	if len(c.Sinks) > 0 {
+		exp, push, err = newSinks(c, set, "metrics", newMetricsPipeline)
+	} else {
+		exp, push, err = newMetricsPipeline(c, set)
+	}
 	if err != nil {
 		return nil, err
 	}
-	return exporterhelper.NewMetrics(
-		ctx, set, cfg, exp.pushMetrics,
-		exporterhelper.WithTimeout(c.TimeoutConfig),
-		exporterhelper.WithQueue(c.QueueSettings),
-		exporterhelper.WithRetry(c.RetrySettings),
-	)
+	if c.CombinedEndpoint != "" {
+		combined := acquireCombinedBuffer(set.ID, exp, c)
+		push = combined.pushMetrics
+		exp.addLifecycle(combined.start, combined.shutdown)
+	}
+	if c.BatchWindow > 0 {
+		buf := newMetricsWindowBuffer(c, set.Logger, push)
+		push = buf.push
+		exp.addLifecycle(buf.start, buf.shutdown)
+	}
+	if c.IdempotencyKeyHeader != "" {
+		push = idempotentPush(push)
+	}
+	opts := exporterOptions(c, exp, c.MetricsRetryEnabled)
+	e, err := exporterhelper.NewMetrics(ctx, set, cfg, push, opts...)
+	if err != nil {
+		return nil, err
+	}
+	if c.QueueFullPolicy == queueFullDropNewest {
+		e = queueDropMetrics{Metrics: e, exp: exp}
+	}
+	if c.IdempotencyKeyHeader != "" {
+		e = idempotentMetrics{e}
+	}
+	return e, nil
 }
 
 func createLogsExporter(ctx context.Context, set exporter.Settings, cfg component.Config) (exporter.Logs, error) {
-	c := cfg.(*Config)
-	exp, err := newMonitoringExporter(c, set.Logger)
+	c := cfg.(*Config).withFormat()
+	var exp *monitoringExporter
+	var push func(context.Context, plog.Logs) error
+	var err error
+	if len(c.Sinks) > 0 {
+		exp, push, err = newSinks(c, set, "logs", newLogsPipeline)
+	} else {
+		exp, push, err = newLogsPipeline(c, set)
+	}
 	if err != nil {
 		return nil, err
 	}
-	return exporterhelper.NewLogs(
-		ctx, set, cfg, exp.pushLogs,
-		exporterhelper.WithTimeout(c.TimeoutConfig),
-		exporterhelper.WithQueue(c.QueueSettings),
-		exporterhelper.WithRetry(c.RetrySettings),
-	)
+	if c.CombinedEndpoint != "" {
+		combined := acquireCombinedBuffer(set.ID, exp, c)
+		push = combined.pushLogs
+		exp.addLifecycle(combined.start, combined.shutdown)
+	}
+	if c.BatchWindow > 0 {
+		buf := newLogsWindowBuffer(c, set.Logger, push)
+		push = buf.push
+		exp.addLifecycle(buf.start, buf.shutdown)
+	}
+	if c.IdempotencyKeyHeader != "" {
+		push = idempotentPush(push)
+	}
+	opts := exporterOptions(c, exp, c.LogsRetryEnabled)
+	e, err := exporterhelper.NewLogs(ctx, set, cfg, push, opts...)
+	if err != nil {
+		return nil, err
+	}
+	if c.QueueFullPolicy == queueFullDropNewest {
+		e = queueDropLogs{Logs: e, exp: exp}
+	}
+	if c.IdempotencyKeyHeader != "" {
+		e = idempotentLogs{e}
+	}
+	return e, nil
+}
+
+// newTracesPipeline, newMetricsPipeline y newLogsPipeline crean el exporter
+// de una señal y su función de envío: cabeceras y método de la señal,
+// autotelemetría, sonda de arranque y fallback. Cada sink usa la misma
+// preparación con su propia configuración
+func newTracesPipeline(c *Config, set exporter.Settings) (*monitoringExporter, func(context.Context, ptrace.Traces) error, error) {
+	exp, err := newMonitoringExporter(c, set.Logger)
+	if err != nil {
+		return nil, nil, err
+	}
+	exp.headers = mergeHeaders(exp.headers, c.TracesHeaders)
+	exp.method = strings.ToUpper(orDefault(c.TracesMethod, exp.method))
+	if exp.telemetry, err = newSelfTelemetry(set.MeterProvider, "traces"); err != nil {
+		return nil, nil, err
+	}
+	if c.StartupProbe {
+		exp.addLifecycle(exp.startupProbe(orDefault(c.CombinedEndpoint, exp.tracesResourceURL(pcommon.NewMap()))), nil)
+	}
+	if c.SpanLatencyWindow > 0 {
+		exp.latency = newLatencyAggregator(exp, c)
+		exp.addLifecycle(exp.latency.start, exp.latency.shutdown)
+	}
+	push := tracesPush(c, exp)
+	if c.FallbackEncoding != "" && c.FallbackEncoding != c.TracesEncoding {
+		fallbackCfg := *c
+		fallbackCfg.TracesEncoding = c.FallbackEncoding
+		push = withFallback(exp, push, tracesPush(&fallbackCfg, exp))
+	}
+	return exp, push, nil
+}
+
+func newMetricsPipeline(c *Config, set exporter.Settings) (*monitoringExporter, func(context.Context, pmetric.Metrics) error, error) {
+	exp, err := newMonitoringExporter(c, set.Logger)
+	if err != nil {
+		return nil, nil, err
+	}
+	exp.headers = mergeHeaders(exp.headers, c.MetricsHeaders)
+	exp.method = strings.ToUpper(orDefault(c.MetricsMethod, exp.method))
+	if exp.telemetry, err = newSelfTelemetry(set.MeterProvider, "metrics"); err != nil {
+		return nil, nil, err
+	}
+	if c.StartupProbe {
+		exp.addLifecycle(exp.startupProbe(orDefault(c.CombinedEndpoint, exp.metricsURL(pcommon.NewMap()))), nil)
+	}
+	push := metricsPush(c, exp)
+	if c.FallbackEncoding != "" && c.FallbackEncoding != c.MetricsEncoding {
+		fallbackCfg := *c
+		fallbackCfg.MetricsEncoding = c.FallbackEncoding
+		push = withFallback(exp, push, metricsPush(&fallbackCfg, exp))
+	}
+	return exp, push, nil
+}
+
+func newLogsPipeline(c *Config, set exporter.Settings) (*monitoringExporter, func(context.Context, plog.Logs) error, error) {
+	exp, err := newMonitoringExporter(c, set.Logger)
+	if err != nil {
+		return nil, nil, err
+	}
+	exp.headers = mergeHeaders(exp.headers, c.LogsHeaders)
+	exp.method = strings.ToUpper(orDefault(c.LogsMethod, exp.method))
+	if exp.telemetry, err = newSelfTelemetry(set.MeterProvider, "logs"); err != nil {
+		return nil, nil, err
+	}
+	if c.StartupProbe {
+		exp.addLifecycle(exp.startupProbe(orDefault(c.CombinedEndpoint, exp.logsResourceURL(pcommon.NewMap()))), nil)
+	}
+	push := logsPush(c, exp)
+	if c.FallbackEncoding != "" && c.FallbackEncoding != c.LogsEncoding {
+		fallbackCfg := *c
+		fallbackCfg.LogsEncoding = c.FallbackEncoding
+		push = withFallback(exp, push, logsPush(&fallbackCfg, exp))
+	}
+	return exp, push, nil
+}
+
+// tracesPush, metricsPush y logsPush eligen la función de envío según la
+// codificación configurada para la señal
+func tracesPush(c *Config, exp *monitoringExporter) func(context.Context, ptrace.Traces) error {
+	switch c.TracesEncoding {
+	case encodingOTLPJSON:
+		return exp.withDerivedTraces(exp.pushTracesOTLP)
+	case encodingOTLPProto:
+		return exp.withDerivedTraces(exp.pushTracesOTLPProto)
+	case encodingZipkin:
+		return exp.withDerivedTraces(exp.pushTracesZipkin)
+	}
+	return exp.pushTraces
+}
+
+// withDerivedTraces añade a un envío de spans las señales derivadas de
+// pushDerivedTraces, para que se apliquen con cualquier codificación
+func (m *monitoringExporter) withDerivedTraces(push func(context.Context, ptrace.Traces) error) func(context.Context, ptrace.Traces) error {
+	return func(ctx context.Context, td ptrace.Traces) error {
+		if err := push(ctx, td); err != nil {
+			return err
+		}
+		return m.pushDerivedTraces(ctx, td)
+	}
+}
+
+func metricsPush(c *Config, exp *monitoringExporter) func(context.Context, pmetric.Metrics) error {
+	switch c.MetricsEncoding {
+	case encodingOTLPJSON:
+		return exp.pushMetricsOTLP
+	case encodingOTLPProto:
+		return exp.pushMetricsOTLPProto
+	case encodingPromRW:
+		return exp.pushMetricsPRW
+	}
+	return exp.pushMetrics
+}
+
+func logsPush(c *Config, exp *monitoringExporter) func(context.Context, plog.Logs) error {
+	switch c.LogsEncoding {
+	case encodingOTLPJSON:
+		return exp.pushLogsOTLP
+	case encodingOTLPProto:
+		return exp.pushLogsOTLPProto
+	case encodingLoki:
+		return exp.pushLogsLoki
+	case encodingNDJSON:
+		return exp.pushLogsNDJSON
+	}
+	return exp.pushLogs
+}
+
+// exporterOptions devuelve las opciones comunes del exporterhelper; WithRetry
+// solo se incluye si la señal tiene los reintentos habilitados
+func exporterOptions(c *Config, exp *monitoringExporter, retryEnabled bool) []exporterhelper.Option {
+	timeout := c.TimeoutConfig
+	if c.RequestBaseTimeout > 0 {
+		// Sin plazo global: lo impone postJSON en función del tamaño del body
+		timeout = exporterhelper.TimeoutConfig{}
+	}
+	queue := c.QueueSettings
+	switch c.QueueFullPolicy {
+	case queueFullBlock:
+		queue.BlockOnOverflow = true
+	case queueFullDropNewest:
+		queue.BlockOnOverflow = false
+	}
+	opts := []exporterhelper.Option{
+		exporterhelper.WithTimeout(timeout),
+		exporterhelper.WithQueue(queue),
+		exporterhelper.WithStart(exp.start),
+		exporterhelper.WithShutdown(exp.shutdown),
+	}
+	if retryEnabled {
+		opts = append(opts, exporterhelper.WithRetry(c.RetrySettings))
+	}
+	return opts
 }
 
 type monitoringExporter struct {
@@ -159,13 +808,178 @@ type monitoringExporter struct {
 	LogFile      string
 	MaxLines     int
 	MaxBodyBytes int
+
+	sanitizeMetricNames bool
+	metricNameInvalid   *regexp.Regexp
+	heartbeatOnEmpty    bool
+
+	tracesURLTemplate  string
+	metricsURLTemplate string
+	logsURLTemplate    string
+
+	dedupLogResources    bool
+	batchIDHeader        string
+	idempotencyKeyHeader string
+	location             *time.Location
+	nonFiniteHandling    string
+
+	dataPointAttributesKey string
+
+	preflight    bool
+	preflightTTL time.Duration
+	preflightMu  sync.Mutex
+	preflightOK  map[string]time.Time
+
+	decimalPlaces int
+
+	keyDictionary       map[string]string
+	keyDictionaryHeader string
+	keyDictionaryValue  string
+	keyDictionaryMu     sync.Mutex
+	keyDictionarySent   map[string]bool
+
+	requireLogBody   bool
+	resourceAsString bool
+	collectorHost    string
+
+	pathPrefix        string
+	tracesPathSuffix  string
+	metricsPathSuffix string
+	logsPathSuffix    string
+
+	hoistLogSource bool
+
+	histogramPercentiles []float64
+
+	sigv4 *sigV4Signer
+	hmac  *hmacSigner
+
+	sortLogsByTimestamp bool
+
+	statusHeader          string
+	statusHeaderRetryable map[int]bool
+
+	rates *rateTracker
+
+	attributeRenames map[string]string
+
+	maxLogBodyBytes   int
+	includeSignalType bool
+
+	connStats *connStats
+
+	includeExemplars     bool
+	exemplarsSampledOnly bool
+
+	requestBaseTimeout    time.Duration
+	requestBytesPerSecond int
+
+	dedupLogAttributes bool
+
+	tracesToMetrics bool
+	redBuckets      []time.Duration
+
+	parseJSONLogBodies bool
+
+	lokiLabelKeys []string
+
+	queueFullPolicy string
+	queueDropped    atomic.Int64
+
+	contentDigestHeader string
+
+	tooEarlyRetryDelay time.Duration
+
+	metricEndpointOverrides map[string]string
+
+	logSeverityRoutes []LogSeverityRoute
+
+	encryptor *payloadEncryptor
+
+	includeTraceSpanCounts bool
+
+	retryClassification []RetryClassificationRule
+
+	includeSeriesHash bool
+
+	compression string
+
+	includeSpanDetails bool
+
+	piiPatterns []*regexp.Regexp
+
+	latency *latencyAggregator
+
+	keyDataPointsByAttributes bool
+	latestOnly                bool
+
+	fallbackEncoding     string
+	fallbackOnStatus     []int
+	fallbackBodyContains string
+
+	coalescer *endpointCoalescer
+
+	metadataEndpoint string
+	metadata         *metricMetadataRegistry
+
+	dependencyEndpoint string
+
+	traceSampleRate       float64
+	traceSampleKeepErrors bool
+
+	aggregateLogTemplates bool
+	logTemplateExamples   int
+
+	staleness *stalenessTracker
+
+	canonical bool
+
+	shutdownDone atomic.Bool
+
+	maxPayloadBytes int
+
+	sampleSpanNames int
+
+	timestampFormat string
+
+	attrAllowlist map[string]bool
+	attrDenylist  map[string]bool
+
+	redactKeys           map[string]bool
+	redactionPlaceholder string
+
+	bytesDataPrefix bool
+
+	emptyAttributeValue string
+
+	metricNamePrefix string
+
+	method string
+
+	breaker *circuitBreaker
+
+	// Métricas propias; nil en los exporters de los sinks
+	telemetry *selfTelemetry
+
+	endpointFromAttribute string
+
+	// Funciones de arranque y parada adicionales (p. ej. la ventana de batch)
+	startHooks    []component.StartFunc
+	shutdownHooks []component.ShutdownFunc
 }
 
 func newMonitoringExporter(cfg *Config, lg *zap.Logger) (*monitoringExporter, error) {
 
 	// Crear transporte HTTP con soporte para certificados CA personalizados
 	var transport *http.Transport
-	if cfg.CaCertFile != "" {
+	if cfg.TLS != nil {
+		tlsConfig, err := cfg.TLS.LoadTLSConfig(context.Background())
+		if err != nil {
+			return nil, fmt.Errorf("error al cargar la configuración tls: %w", err)
+		}
+		transport = http.DefaultTransport.(*http.Transport).Clone()
+		transport.TLSClientConfig = tlsConfig
+	} else if cfg.CaCertFile != "" {
 		caCert, err := os.ReadFile(cfg.CaCertFile)
 		if err != nil {
 			return nil, fmt.Errorf("error al leer el archivo de certificado CA: %w", err)
@@ -205,13 +1019,159 @@ func newMonitoringExporter(cfg *Config, lg *zap.Logger) (*monitoringExporter, er
 		}
 
 	} else {
-		transport = http.DefaultTransport.(*http.Transport)
+		// Copia propia: Shutdown cierra las conexiones de este transport
+		transport = http.DefaultTransport.(*http.Transport).Clone()
+	}
+
+	// Compilar el patrón de caracteres no permitidos en nombres de métrica
+	var metricNameInvalid *regexp.Regexp
+	if cfg.SanitizeMetricNames {
+		allowed := cfg.MetricNameAllowedChars
+		if allowed == "" {
+			allowed = "a-zA-Z0-9_"
+		}
+		re, err := regexp.Compile("[^" + allowed + "]")
+		if err != nil {
+			return nil, fmt.Errorf("metric_name_allowed_chars: %w", err)
+		}
+		metricNameInvalid = re
+	}
+
+	// Zona horaria para las fechas RFC3339
+	location := time.UTC
+	if cfg.TimestampTimezone != "" {
+		loc, err := time.LoadLocation(cfg.TimestampTimezone)
+		if err != nil {
+			return nil, fmt.Errorf("timestamp_timezone: %w", err)
+		}
+		location = loc
+	}
+
+	// Tratamiento de valores no finitos
+	nonFinite := cfg.NonFiniteHandling
+	if nonFinite == "" {
+		nonFinite = "null"
+	}
+
+	// Diccionario inverso de claves que se envía al sink (Validate descarta
+	// los códigos duplicados)
+	var keyDictionaryValue string
+	if len(cfg.KeyDictionary) > 0 {
+		inverse := make(map[string]string, len(cfg.KeyDictionary))
+		for k, code := range cfg.KeyDictionary {
+			inverse[code] = k
+		}
+		data, err := json.Marshal(inverse)
+		if err != nil {
+			return nil, fmt.Errorf("key_dictionary: %w", err)
+		}
+		keyDictionaryValue = string(data)
+	}
+
+	var staleness *stalenessTracker
+	if cfg.StaleAfterPushes > 0 {
+		staleness = newStalenessTracker(cfg.StaleAfterPushes)
+	}
+
+	var coalescer *endpointCoalescer
+	if cfg.CoalesceWindow > 0 {
+		coalescer = newEndpointCoalescer(cfg.CoalesceWindow, cfg.CoalesceMaxBytes, cfg.MaxPayloadBytes)
+	}
+
+	// Limitar las resoluciones DNS concurrentes (sin tocar el transporte global)
+	if cfg.MaxConcurrentDNS > 0 {
+		transport = transport.Clone()
+		transport.DialContext = newBoundedDialer(cfg.MaxConcurrentDNS).DialContext
+	}
+
+	// Proxy de salida: proxy_url o, si no se indica, HTTP_PROXY/HTTPS_PROXY/
+	// NO_PROXY (los transportes propios de TLS no lo traían)
+	if cfg.ProxyURL != "" || transport.Proxy == nil {
+		transport = transport.Clone()
+		transport.Proxy = http.ProxyFromEnvironment
+		if cfg.ProxyURL != "" {
+			proxyURL, err := url.Parse(cfg.ProxyURL)
+			if err != nil {
+				return nil, fmt.Errorf("proxy_url: %w", err)
+			}
+			transport.Proxy = http.ProxyURL(proxyURL)
+		}
+	}
+
+	// Límites de latencia de traces_to_metrics, ordenados
+	redBuckets := defaultRedBuckets
+	if len(cfg.TracesToMetricsBuckets) > 0 {
+		redBuckets = append([]time.Duration(nil), cfg.TracesToMetricsBuckets...)
+		sort.Slice(redBuckets, func(a, b int) bool { return redBuckets[a] < redBuckets[b] })
+	}
+
+	encryptor, err := newPayloadEncryptor(cfg.Encryption)
+	if err != nil {
+		return nil, err
+	}
+
+	var piiPatterns []*regexp.Regexp
+	if cfg.DetectPII {
+		if piiPatterns, err = compilePIIPatterns(cfg.PIIPatterns); err != nil {
+			return nil, err
+		}
+	}
+
+	// Estadísticas de conexión por expvar; se publican en Start
+	var stats *connStats
+	if cfg.ExpvarName != "" {
+		stats = sharedConnStats(cfg.ExpvarName)
+		transport = transport.Clone()
+		transport.DialContext = stats.dialer(transport.DialContext)
+	}
+
+	// Firmante SigV4
+	var sigv4 *sigV4Signer
+	if cfg.SigV4.Service != "" {
+		signer, err := newSigV4Signer(cfg.SigV4)
+		if err != nil {
+			return nil, err
+		}
+		sigv4 = signer
+	}
+	hmacSigner, err := newHMACSigner(cfg.Signing)
+	if err != nil {
+		return nil, err
+	}
+
+	statusHeaderRetryable := make(map[int]bool, len(cfg.StatusHeaderRetryableCodes))
+	for _, code := range cfg.StatusHeaderRetryableCodes {
+		statusHeaderRetryable[code] = true
+	}
+
+	var rates *rateTracker
+	if cfg.EmitRates {
+		rates = newRateTracker()
+	}
+
+	// Hostname del collector, resuelto una sola vez al arrancar
+	var collectorHost string
+	if cfg.IncludeHostname {
+		host, err := os.Hostname()
+		if err != nil {
+			return nil, fmt.Errorf("error al obtener el hostname: %w", err)
+		}
+		collectorHost = host
 	}
 
 	// Crear cliente HTTP con el transporte configurado
+	roundTripper, err := chainMiddlewares(transport, cfg.Middlewares)
+	if err != nil {
+		return nil, err
+	}
+
 	httpClient := &http.Client{
 		Timeout:   cfg.Timeout,
-		Transport: transport,
+		Transport: roundTripper,
+	}
+	if cfg.RequestBaseTimeout > 0 {
+		// El plazo lo fija cada petición según su tamaño
+		httpClient.Timeout = 0
 	}
 
 	return &monitoringExporter{
@@ -221,16 +1181,285 @@ func newMonitoringExporter(cfg *Config, lg *zap.Logger) (*monitoringExporter, er
 		traces:       cfg.Traces,
 		metrics:      cfg.Metrics,
 		logs:         cfg.Logs,
-		headers:      cfg.Headers,
+		headers:      authHeaders(cfg, lg),
 		logger:       lg,
 		client:       httpClient,
 		mrid:         cfg.MrId,
 		LogFile:      cfg.LogFile,
 		MaxLines:     cfg.MaxLines,
 		MaxBodyBytes: cfg.MaxBodyBytes,
+
+		sanitizeMetricNames: cfg.SanitizeMetricNames,
+		metricNameInvalid:   metricNameInvalid,
+		heartbeatOnEmpty:    cfg.HeartbeatOnEmpty,
+
+		tracesURLTemplate:  cfg.TracesURLTemplate,
+		metricsURLTemplate: cfg.MetricsURLTemplate,
+		logsURLTemplate:    cfg.LogsURLTemplate,
+
+		dedupLogResources:    cfg.DedupLogResources,
+		batchIDHeader:        cfg.BatchIDHeader,
+		idempotencyKeyHeader: cfg.IdempotencyKeyHeader,
+		location:             location,
+		nonFiniteHandling:    nonFinite,
+
+		dataPointAttributesKey: cfg.DataPointAttributesKey,
+
+		preflight:    cfg.Preflight,
+		preflightTTL: cfg.PreflightTTL,
+		preflightOK:  make(map[string]time.Time),
+
+		decimalPlaces: cfg.DecimalPlaces,
+
+		keyDictionary:       cfg.KeyDictionary,
+		keyDictionaryHeader: cfg.KeyDictionaryHeader,
+		keyDictionaryValue:  keyDictionaryValue,
+		keyDictionarySent:   make(map[string]bool),
+
+		requireLogBody:   cfg.RequireLogBody,
+		resourceAsString: cfg.ResourceAsString,
+		collectorHost:    collectorHost,
+
+		pathPrefix:        cfg.PathPrefix,
+		tracesPathSuffix:  orDefault(cfg.TracesPathSuffix, defaultTracesPathSuffix),
+		metricsPathSuffix: orDefault(cfg.MetricsPathSuffix, defaultMetricsPathSuffix),
+		logsPathSuffix:    orDefault(cfg.LogsPathSuffix, defaultLogsPathSuffix),
+
+		hoistLogSource: cfg.HoistLogSource,
+
+		histogramPercentiles: cfg.HistogramAsPercentiles,
+
+		sigv4: sigv4,
+		hmac:  hmacSigner,
+
+		sortLogsByTimestamp: cfg.SortLogsByTimestamp,
+
+		statusHeader:          cfg.StatusHeader,
+		statusHeaderRetryable: statusHeaderRetryable,
+
+		rates: rates,
+
+		attributeRenames: buildAttributeRenames(cfg.ApplySemconvMapping, cfg.AttributeRenames),
+
+		maxLogBodyBytes:   cfg.MaxLogBodyBytes,
+		includeSignalType: cfg.IncludeSignalType,
+
+		connStats: stats,
+
+		includeExemplars:     cfg.IncludeExemplars,
+		exemplarsSampledOnly: cfg.ExemplarsSampledOnly,
+
+		requestBaseTimeout:    cfg.RequestBaseTimeout,
+		requestBytesPerSecond: cfg.RequestBytesPerSecond,
+
+		dedupLogAttributes: cfg.DedupLogAttributes,
+
+		tracesToMetrics: cfg.TracesToMetrics,
+		redBuckets:      redBuckets,
+
+		parseJSONLogBodies: cfg.ParseJSONLogBodies,
+
+		lokiLabelKeys: cfg.LokiLabels,
+
+		queueFullPolicy: cfg.QueueFullPolicy,
+
+		contentDigestHeader: cfg.ContentDigestHeader,
+
+		tooEarlyRetryDelay: cfg.TooEarlyRetryDelay,
+
+		metricEndpointOverrides: cfg.MetricEndpointOverrides,
+
+		logSeverityRoutes: cfg.LogSeverityRoutes,
+
+		encryptor: encryptor,
+
+		includeTraceSpanCounts: cfg.IncludeTraceSpanCounts,
+
+		retryClassification: cfg.RetryClassification,
+
+		includeSeriesHash: cfg.IncludeSeriesHash,
+
+		compression: cfg.Compression,
+
+		includeSpanDetails: cfg.IncludeSpanDetails,
+
+		piiPatterns: piiPatterns,
+
+		keyDataPointsByAttributes: cfg.KeyDataPointsByAttributes,
+		latestOnly:                cfg.LatestOnly,
+
+		fallbackEncoding:     cfg.FallbackEncoding,
+		fallbackOnStatus:     cfg.FallbackOnStatus,
+		fallbackBodyContains: cfg.FallbackBodyContains,
+
+		coalescer: coalescer,
+
+		metadataEndpoint: cfg.MetadataEndpoint,
+		metadata:         &metricMetadataRegistry{seen: make(map[string]bool)},
+
+		dependencyEndpoint: cfg.DependencyEndpoint,
+
+		traceSampleRate:       cfg.TraceSampleRate,
+		traceSampleKeepErrors: cfg.TraceSampleKeepErrors,
+
+		aggregateLogTemplates: cfg.AggregateLogTemplates,
+		logTemplateExamples:   cfg.LogTemplateExamples,
+
+		staleness: staleness,
+
+		canonical: cfg.Canonical,
+
+		maxPayloadBytes: cfg.MaxPayloadBytes,
+
+		sampleSpanNames: cfg.SampleSpanNames,
+
+		timestampFormat: cfg.TimestampFormat,
+
+		attrAllowlist: toSet(cfg.AttributeAllowlist),
+		attrDenylist:  toSet(cfg.AttributeDenylist),
+
+		redactKeys:           toSet(cfg.RedactAttributes),
+		redactionPlaceholder: cfg.RedactionPlaceholder,
+
+		bytesDataPrefix: cfg.BytesDataPrefix,
+
+		emptyAttributeValue: cfg.EmptyAttributeValue,
+
+		metricNamePrefix: cfg.MetricNamePrefix,
+
+		method: strings.ToUpper(orDefault(cfg.Method, http.MethodPost)),
+
+		breaker: newCircuitBreaker(cfg.CircuitBreaker, lg),
+
+		endpointFromAttribute: cfg.EndpointFromAttribute,
 	}, nil
 }
 
+// addLifecycle registra funciones de arranque y parada adicionales; el
+// exporterhelper solo admite una de cada, así que se encadenan aquí
+func (m *monitoringExporter) addLifecycle(start component.StartFunc, shutdown component.ShutdownFunc) {
+	if start != nil {
+		m.startHooks = append(m.startHooks, start)
+	}
+	if shutdown != nil {
+		m.shutdownHooks = append(m.shutdownHooks, shutdown)
+	}
+}
+
+func (m *monitoringExporter) start(ctx context.Context, host component.Host) error {
+	if m.connStats != nil {
+		m.connStats.publish(m.logger)
+	}
+	for _, start := range m.startHooks {
+		if err := start(ctx, host); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// shutdown ejecuta las paradas en orden inverso al arranque. El
+// exporterhelper ya ha vaciado su cola antes de llamarla; aquí se envía lo
+// que queda en los buffers propios y se cierran las conexiones. Una segunda
+// llamada no hace nada
+func (m *monitoringExporter) shutdown(ctx context.Context) error {
+	if !m.shutdownDone.CompareAndSwap(false, true) {
+		return nil
+	}
+	var errs []error
+	for i := len(m.shutdownHooks) - 1; i >= 0; i-- {
+		if err := m.shutdownHooks[i](ctx); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	// Lo que vacían las paradas anteriores puede quedar en el coalescer
+	if m.coalescer != nil {
+		m.coalescer.flushAll()
+	}
+	m.client.CloseIdleConnections()
+	return errors.Join(errs...)
+}
+
+// orDefault devuelve v o def si v está vacío
+func orDefault(v, def string) string {
+	if v == "" {
+		return def
+	}
+	return v
+}
+
+// attrKey devuelve la clave con la que se serializa un atributo: se aplica el
+// renombrado configurado y después su código del diccionario de claves si
+// existe o, si no, el nombre saneado. El sink decodifica sustituyendo cada
+// código por su clave según el diccionario inverso recibido en la cabecera
+// key_dictionary_header
+func (m *monitoringExporter) attrKey(k string) string {
+	if renamed, ok := m.attributeRenames[k]; ok {
+		k = renamed
+	}
+	if code, ok := m.keyDictionary[k]; ok {
+		return code
+	}
+	return sanitizeName(k)
+}
+
+// putAttrs copia attrs en dst con las claves saneadas. Recorre las claves en
+// orden para que, si dos claves colisionan al sanearse, gane siempre la misma;
+// json.Marshal ya emite las claves de los mapas ordenadas, así que el payload
+// resultante es estable entre ejecuciones
+func (m *monitoringExporter) putAttrs(dst, attrs map[string]interface{}) {
+	keys := make([]string, 0, len(attrs))
+	for k := range attrs {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		if !m.attrAllowed(k) {
+			continue
+		}
+		value := m.attrValue(attrs[k])
+		if m.redactKeys != nil {
+			value = m.redactValue(k, k, value)
+		}
+		dst[m.attrKey(k)] = value
+	}
+}
+
+// internalAttrs son los atributos que el exporter consume (mrId, parentSpan y
+// la URL) y no se repiten en properties
+var internalAttrs = []string{"mrid", "parentspan", "ns", "region"}
+
+// deleteInternalAttrs quita de properties los atributos internos con la clave
+// con la que los escribió putAttrs (renombrada o codificada por el diccionario)
+func (m *monitoringExporter) deleteInternalAttrs(properties map[string]interface{}) {
+	for _, k := range internalAttrs {
+		delete(properties, m.attrKey(k))
+	}
+}
+
+// resourceString serializa los atributos del resource (claves saneadas) como
+// un string JSON compacto para sinks que solo admiten una columna de texto
+func (m *monitoringExporter) resourceString(resourceAttrs map[string]interface{}) (string, error) {
+	resource := make(map[string]interface{}, len(resourceAttrs))
+	m.putAttrs(resource, resourceAttrs)
+	data, err := json.Marshal(resource)
+	if err != nil {
+		return "", fmt.Errorf("error serializing resource: %w", err)
+	}
+	return string(data), nil
+}
+
+// metricKey devuelve el nombre de métrica usado como clave en "values", con
+// metric_name_prefix delante y reemplazando por "_" los caracteres no
+// permitidos si está habilitado
+func (m *monitoringExporter) metricKey(name string) string {
+	name = m.metricNamePrefix + name
+	if !m.sanitizeMetricNames || m.metricNameInvalid == nil {
+		return name
+	}
+	return m.metricNameInvalid.ReplaceAllString(name, "_")
+}
+
 // Trace Started
 
 type outSpan struct {
@@ -244,6 +1473,27 @@ type outSpan struct {
 	Properties     map[string]interface{} `json:"properties,omitempty"`
 	ParentSpanTest string                 `json:"parentSpanTest,omitempty"`
 	CreateUrl      string                 `json:"CreateUrl,omitempty"`
+	BatchID        string                 `json:"batchId,omitempty"`
+	CollectorHost  string                 `json:"collector_host,omitempty"`
+	Signal         string                 `json:"signal,omitempty"`
+	Resource       string                 `json:"resource,omitempty"`
+	TraceState     string                 `json:"trace_state,omitempty"`
+	Scope          *outScope              `json:"scope,omitempty"`
+	// Atributos descartados aguas arriba por los límites del SDK (0 se omite)
+	DroppedAttributesCount uint32 `json:"dropped_attributes_count,omitempty"`
+	// Detalle completo del span (include_span_details)
+	TraceIDHex         string                 `json:"traceIdHex,omitempty"`
+	SpanIDHex          string                 `json:"spanIdHex,omitempty"`
+	ParentSpanIDHex    string                 `json:"parentSpanIdHex,omitempty"`
+	Kind               string                 `json:"kind,omitempty"`
+	StatusCode         string                 `json:"statusCode,omitempty"`
+	StatusMessage      string                 `json:"statusMessage,omitempty"`
+	ResourceAttributes map[string]interface{} `json:"resourceAttributes,omitempty"`
+	ScopeAttributes    map[string]interface{} `json:"scopeAttributes,omitempty"`
+	Events             []outSpanEvent         `json:"events,omitempty"`
+	Links              []outSpanLink          `json:"links,omitempty"`
+	DroppedEventsCount uint32                 `json:"dropped_events_count,omitempty"`
+	DroppedLinksCount  uint32                 `json:"dropped_links_count,omitempty"`
 }
 
 // Config opcional para construir el parentSpan
@@ -278,10 +1528,18 @@ func (m *monitoringExporter) transformTraces(td ptrace.Traces, cfg transformCfg)
 
 		// Extra opcional: atributos de resource para properties
 		resAttrs := rs.Resource().Attributes()
+		var resourceJSON string
+		if m.resourceAsString {
+			var err error
+			if resourceJSON, err = m.resourceString(resAttrs.AsRaw()); err != nil {
+				return nil, nil, err
+			}
+		}
 
 		ssSlice := rs.ScopeSpans()
 		for j := 0; j < ssSlice.Len(); j++ {
 			ss := ssSlice.At(j)
+			scope := newOutScope(ss.Scope())
 
 			spans := ss.Spans()
 			for k := 0; k < spans.Len(); k++ {
@@ -323,17 +1581,9 @@ func (m *monitoringExporter) transformTraces(td ptrace.Traces, cfg transformCfg)
 
 				// Properties: copia atributos del span salvo los internos
 				props := map[string]interface{}{}
-				sp.Attributes().Range(func(k string, v pcommon.Value) bool {
-					// Limpiar el nombre de la clave
-					cleanKey := sanitizeName(k)
-					props[cleanKey] = v.AsRaw()
-					return true
-				})
+				m.putAttrs(props, sp.Attributes().AsRaw())
 				// no duplicar mrid (ya lo usamos como mrId)
-				delete(props, "mrid")
-				delete(props, "parentspan")
-				delete(props, "ns")
-				delete(props, "region")
+				m.deleteInternalAttrs(props)
 
 				item := outSpan{
 					MRID:       mrID,
@@ -342,13 +1592,25 @@ func (m *monitoringExporter) transformTraces(td ptrace.Traces, cfg transformCfg)
 					FinishDate: uint64(sp.EndTimestamp()),           // ns
 					Name:       sp.Name(),
 					TraceID:    spanHexToUUID(sp.TraceID().String()), // hex de 16 bytes (32 chars)
+					Resource:   resourceJSON,
+					TraceState: sp.TraceState().AsRaw(), // W3C tracestate (vacío se omite)
+					Scope:      scope,
+
+					DroppedAttributesCount: sp.DroppedAttributesCount(),
 				}
 				if len(props) > 0 {
 					item.Properties = props
 				}
+				if m.includeSpanDetails {
+					m.addSpanDetails(&item, sp, resAttrs, ss.Scope().Attributes())
+				}
 
-				if regionAtt != "" && regionAtt != "unknown" && nsAtt != "" && nsAtt != "unknown" {
-					createUrls = append(createUrls, fmt.Sprintf("https://rho.%s/v1/ns/%s/mrs/%s/spans", regionAtt, nsAtt, mrID)) // Guardar CreateUrl
+				if m.tracesURLTemplate != "" && m.useURLTemplate(resAttrs) {
+					vars := map[string]string{"region": regionAtt, "ns": nsAtt, "mrid": mrID}
+					createUrls = append(createUrls, expandURLTemplate(m.tracesURLTemplate, vars, sp.Attributes(), resAttrs))
+				} else if regionAtt != "" && regionAtt != "unknown" && nsAtt != "" && nsAtt != "unknown" {
+					vars := map[string]string{"region": regionAtt, "ns": nsAtt, "mrid": mrID}
+					createUrls = append(createUrls, m.defaultURL("rho", "/v1", m.tracesPathSuffix, vars, sp.Attributes(), resAttrs)) // Guardar CreateUrl
 
 				}
 				// parentSpan (si existe parentSpanId)
@@ -379,6 +1641,38 @@ func (m *monitoringExporter) transformTraces(td ptrace.Traces, cfg transformCfg)
 	return outJSON, createUrls, nil
 }
 
+// defaultURL compone la URL por defecto de una señal: https://<host>.<region>
+// + path_prefix (o el prefijo de versión por defecto) + sufijo de la señal
+func (m *monitoringExporter) defaultURL(host, defaultPrefix, suffix string, vars map[string]string, attrs ...pcommon.Map) string {
+	prefix := m.pathPrefix
+	if prefix == "" {
+		prefix = defaultPrefix
+	}
+	return fmt.Sprintf("https://%s.%s", host, vars["region"]) + prefix + expandURLTemplate(suffix, vars, attrs...)
+}
+
+var urlPlaceholder = regexp.MustCompile(`\{([^{}]+)\}`)
+
+// expandURLTemplate sustituye los marcadores {clave} de la plantilla: primero
+// con vars y después con el primer mapa de atributos que tenga la clave
+func expandURLTemplate(tmpl string, vars map[string]string, attrs ...pcommon.Map) string {
+	return urlPlaceholder.ReplaceAllStringFunc(tmpl, func(ph string) string {
+		key := ph[1 : len(ph)-1]
+		if v := vars[key]; v != "" {
+			return url.PathEscape(v)
+		}
+		if key == "service" {
+			key = "service.name"
+		}
+		for _, a := range attrs {
+			if v := getAttrString(a, key); v != "" {
+				return url.PathEscape(v)
+			}
+		}
+		return "unknown"
+	})
+}
+
 func getAttrString(attrs pcommon.Map, key string) string {
 	if v, ok := attrs.Get(key); ok {
 		return v.AsString()
@@ -386,9 +1680,64 @@ func getAttrString(attrs pcommon.Map, key string) string {
 	return ""
 }
 
+// pushTraces envía los spans y, solo cuando se han enviado, las señales
+// derivadas de ellos
 func (m *monitoringExporter) pushTraces(ctx context.Context, td ptrace.Traces) error {
+	if err := m.pushSpans(ctx, td); err != nil {
+		return err
+	}
+	return m.pushDerivedTraces(ctx, td)
+}
+
+// pushDerivedTraces envía las aristas de dependency_endpoint y las métricas
+// RED de traces_to_metrics y acumula las latencias de span_latency_window. Va
+// después de los spans porque un fallo de los spans hace que el exporterhelper
+// reintente el lote completo, y lo ya contado se contaría dos veces. Por lo
+// mismo, un error solo se devuelve si todavía no ha salido nada: con los
+// spans o alguno de los envíos derivados ya aceptados se registra, porque el
+// reintento los repetiría. Las latencias se acumulan únicamente cuando no
+// habrá reintento
+func (m *monitoringExporter) pushDerivedTraces(ctx context.Context, td ptrace.Traces) error {
+	sent := m.traces
+	var errs []error
+	derived := []struct {
+		enabled bool
+		push    func(context.Context, ptrace.Traces) error
+	}{
+		{m.dependencyEndpoint != "", m.pushDependencyEdges},
+		{m.tracesToMetrics, m.pushSpanMetrics},
+	}
+	for _, d := range derived {
+		if !d.enabled {
+			continue
+		}
+		if err := d.push(ctx, td); err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		sent = true
+	}
+	err := errors.Join(errs...)
+	if err != nil && !sent {
+		return err
+	}
+	if err != nil {
+		m.logger.Error("error al enviar las señales derivadas de las trazas", zap.Error(err))
+	}
+	if m.latency != nil {
+		m.latency.record(td)
+	}
+	return nil
+}
+
+func (m *monitoringExporter) pushSpans(ctx context.Context, td ptrace.Traces) error {
 	if !m.traces { // Verificar si el envío de traces está habilitado
-		m.logger.Sugar().Warnln("El envío de traces está deshabilitado, no se realizará el POST.")
+		if !m.tracesToMetrics && m.latency == nil && m.dependencyEndpoint == "" {
+			m.logger.Sugar().Warnln("El envío de traces está deshabilitado, no se realizará el POST.")
+		}
+		return nil
+	}
+	if td = m.sampleTraces(td); td.SpanCount() == 0 {
 		return nil
 	}
 
@@ -405,117 +1754,412 @@ func (m *monitoringExporter) pushTraces(ctx context.Context, td ptrace.Traces) e
 		return fmt.Errorf("error unmarshaling transformed traces: %w", err)
 	}
 
+	m.logSpanNames(spans)
+
+	// Identificador del lote (cabecera + payload)
+	ctx, batchID := m.withBatchID(ctx)
+
 	// Agrupar los datos por URL
 	urlToBody := make(map[string][]outSpan)
 	for i, url := range createUrls {
+		spans[i].BatchID = batchID
+		spans[i].CollectorHost = m.collectorHost
+		spans[i].Signal = m.signalType("trace")
 		urlToBody[url] = append(urlToBody[url], spans[i])
 	}
 
 	// Enviar los datos agrupados
 	for url, spans := range urlToBody {
-		body, err := json.Marshal(spans)
+		bodies, err := splitPayload(m, spans, m.encodeSpans)
 		if err != nil {
 			return fmt.Errorf("error marshaling spans for URL %s: %w", url, err)
 		}
-
-		// Enviar los datos a la URL correspondiente
-		if err := m.postJSON(ctx, url, body); err != nil {
-			return fmt.Errorf("error sending data to URL %s: %w", url, err)
+
+		// Enviar los datos a la URL correspondiente
+		for _, body := range bodies {
+			if err := m.postJSON(ctx, url, body); err != nil {
+				return fmt.Errorf("error sending data to URL %s: %w", url, err)
+			}
+		}
+	}
+
+	return nil
+}
+
+// logSpanNames registra en debug una muestra de los nombres de span del lote:
+// los sample_span_names primeros (0 = nada, -1 = todos)
+func (m *monitoringExporter) logSpanNames(spans []outSpan) {
+	if m.sampleSpanNames == 0 || !m.logger.Core().Enabled(zap.DebugLevel) {
+		return
+	}
+	var names []string
+	for _, span := range spans {
+		if m.sampleSpanNames > 0 && len(names) >= m.sampleSpanNames {
+			break
+		}
+		names = append(names, span.Name)
+	}
+	m.logger.Debug("monitoring/exporter spans del lote",
+		zap.Int("spans", len(spans)), zap.Strings("sample_names", names))
+}
+
+// Crear una estructura para las métricas transformadas
+type transformedMetric struct {
+	Timestamp  int64                  `json:"timestamp"`
+	Properties map[string]interface{} `json:"properties"`
+	Values     map[string]interface{} `json:"values"`
+	Resource   string                 `json:"resource,omitempty"`
+	Exemplars  []outExemplar          `json:"exemplars,omitempty"`
+	SeriesHash string                 `json:"series_hash,omitempty"`
+	Scope      *outScope              `json:"scope,omitempty"`
+	// Atributos del punto emitidos bajo attributesKey (si está configurada)
+	Attributes    map[string]interface{} `json:"-"`
+	attributesKey string
+	// Serie y timestamp para stale_after_pushes; se cuentan al enviarse
+	staleKey string
+	staleTS  pcommon.Timestamp
+}
+
+// addSpanDetails completa el span con los IDs en hex, kind, estado, los
+// atributos de resource y scope, y los eventos y enlaces
+func (m *monitoringExporter) addSpanDetails(item *outSpan, sp ptrace.Span, resAttrs, scopeAttrs pcommon.Map) {
+	item.TraceIDHex = sp.TraceID().String()
+	item.SpanIDHex = sp.SpanID().String()
+	if !sp.ParentSpanID().IsEmpty() {
+		item.ParentSpanIDHex = sp.ParentSpanID().String()
+	}
+	item.Kind = sp.Kind().String()
+	item.StatusCode = sp.Status().Code().String()
+	item.StatusMessage = sp.Status().Message()
+	if resAttrs.Len() > 0 && !m.resourceAsString {
+		item.ResourceAttributes = make(map[string]interface{}, resAttrs.Len())
+		m.putAttrs(item.ResourceAttributes, resAttrs.AsRaw())
+	}
+	if scopeAttrs.Len() > 0 {
+		item.ScopeAttributes = make(map[string]interface{}, scopeAttrs.Len())
+		m.putAttrs(item.ScopeAttributes, scopeAttrs.AsRaw())
+	}
+	m.addSpanEventsAndLinks(item, sp)
+}
+
+// encodeSpans serializa los spans de una URL; con include_trace_span_counts el
+// payload pasa a ser {"spans": [...], "trace_span_counts": {traceId: n}}
+func (m *monitoringExporter) encodeSpans(spans []outSpan) ([]byte, error) {
+	if !m.includeTraceSpanCounts {
+		return m.marshalRecords(spans)
+	}
+	counts := make(map[string]int)
+	for _, span := range spans {
+		counts[span.TraceID]++
+	}
+	return m.marshalRecords(map[string]interface{}{"spans": spans, "trace_span_counts": counts})
+}
+
+// MarshalJSON añade los atributos del punto bajo su clave configurada
+func (t transformedMetric) MarshalJSON() ([]byte, error) {
+	type plain transformedMetric
+	data, err := json.Marshal(plain(t))
+	if err != nil || t.attributesKey == "" {
+		return data, err
+	}
+	key, err := json.Marshal(t.attributesKey)
+	if err != nil {
+		return nil, err
+	}
+	attrs, err := json.Marshal(t.Attributes)
+	if err != nil {
+		return nil, err
+	}
+	out := append(data[:len(data)-1:len(data)-1], ',')
+	out = append(out, key...)
+	out = append(out, ':')
+	out = append(out, attrs...)
+	return append(out, '}'), nil
+}
+
+// Transforma OTel Metrics -> Atenea, devolviendo la URL destino de cada registro
+func (m *monitoringExporter) transformMetrics(md pmetric.Metrics) ([]transformedMetric, []string, error) {
+	var transformedMetrics []transformedMetric
+	var createUrls []string
+
+	// Iterar sobre las métricas para transformarlas
+	resourceMetrics := md.ResourceMetrics()
+	for i := 0; i < resourceMetrics.Len(); i++ {
+		resourceMetric := resourceMetrics.At(i)
+		res := metricResource{attrs: resourceMetric.Resource().Attributes().AsRaw()}
+		if m.rates != nil || m.staleness != nil {
+			res.key = resourceKey(res.attrs)
+		}
+		if m.resourceAsString {
+			var err error
+			if res.json, err = m.resourceString(res.attrs); err != nil {
+				return nil, nil, err
+			}
+		}
+		emitted := len(transformedMetrics)
+		resourceURL := m.metricsURL(resourceMetric.Resource().Attributes())
+
+		scopeMetrics := resourceMetric.ScopeMetrics()
+		for j := 0; j < scopeMetrics.Len(); j++ {
+			scopeMetric := scopeMetrics.At(j)
+			metrics := scopeMetric.Metrics()
+			for k := 0; k < metrics.Len(); k++ {
+				metric := metrics.At(k)
+
+				// Iterar sobre los puntos de datos de la métrica
+				var records []transformedMetric
+				var err error
+				switch metric.Type() {
+				case pmetric.MetricTypeSum:
+					cumulative := metric.Sum().AggregationTemporality() == pmetric.AggregationTemporalityCumulative
+					records, err = m.transformNumberDataPoints(metric.Name(), metric.Sum().DataPoints(), res, cumulative)
+				case pmetric.MetricTypeGauge:
+					records, err = m.transformNumberDataPoints(metric.Name(), metric.Gauge().DataPoints(), res, false)
+				case pmetric.MetricTypeHistogram:
+					if len(m.histogramPercentiles) > 0 {
+						records = m.transformHistogramPercentiles(metric.Name(), metric.Histogram().DataPoints(), res)
+					} else {
+						records = m.transformHistograms(metric.Name(), metric.Histogram().DataPoints(), res)
+					}
+				case pmetric.MetricTypeExponentialHistogram:
+					records = m.transformExponentialHistograms(metric.Name(), metric.ExponentialHistogram().DataPoints(), res)
+				case pmetric.MetricTypeSummary:
+					records = m.transformSummaries(metric.Name(), metric.Summary().DataPoints(), res)
+				}
+				if err != nil {
+					return nil, nil, err
+				}
+				if scope := newOutScope(scopeMetric.Scope()); scope != nil {
+					for r := range records {
+						records[r].Scope = scope
+					}
+				}
+				transformedMetrics = append(transformedMetrics, records...)
+				url := m.metricOverrideURL(metric.Name(), resourceURL)
+				for range records {
+					createUrls = append(createUrls, url)
+				}
+			}
+		}
+
+		// Latido: registro sin valores con las propiedades del resource
+		if m.heartbeatOnEmpty && len(transformedMetrics) == emitted {
+			record := transformedMetric{
+				Timestamp:  time.Now().UnixNano(),
+				Properties: make(map[string]interface{}),
+				Values:     map[string]interface{}{},
+				Resource:   res.json,
+			}
+			if !m.resourceAsString {
+				m.putAttrs(record.Properties, res.attrs)
+			}
+			transformedMetrics = append(transformedMetrics, record)
+		}
+
+		// El latido va a la URL del resource
+		for len(createUrls) < len(transformedMetrics) {
+			createUrls = append(createUrls, resourceURL)
+		}
+	}
+
+	return transformedMetrics, createUrls, nil
+}
+
+// metricResource agrupa los datos del resource compartidos por sus registros
+type metricResource struct {
+	attrs map[string]interface{}
+	json  string // solo con resource_as_string
+	key   string // solo con emit_rates o stale_after_pushes (seriesKey)
+}
+
+// transformNumberDataPoints convierte los puntos de un gauge o sum en registros;
+// cumulative indica una suma acumulativa (candidata a emit_rates)
+func (m *monitoringExporter) transformNumberDataPoints(name string, dataPoints pmetric.NumberDataPointSlice, res metricResource, cumulative bool) ([]transformedMetric, error) {
+	var records []transformedMetric
+	latest := m.latestDataPoints(name, dataPoints)
+	for l := 0; l < dataPoints.Len(); l++ {
+		if latest != nil && !latest[l] {
+			continue
+		}
+		dataPoint := dataPoints.At(l)
+		var staleKey string
+		if m.staleness != nil {
+			staleKey = seriesKey(name, res, dataPoint.Attributes())
+			if m.staleness.stale(staleKey, dataPoint.Timestamp()) {
+				continue
+			}
+		}
+		value, ok, err := m.numberValue(dataPoint)
+		if err != nil {
+			return nil, fmt.Errorf("métrica %s: %w", name, err)
+		}
+		if !ok {
+			continue
+		}
+		record := m.newMetricRecord(name, res, dataPoint.Timestamp(), dataPoint.Attributes(), value)
+		record.staleKey, record.staleTS = staleKey, dataPoint.Timestamp()
+		if cumulative && m.rates != nil {
+			m.addRate(&record, name, res, dataPoint)
+		}
+		if m.includeExemplars {
+			record.Exemplars = m.transformExemplars(dataPoint.Exemplars())
 		}
+		records = append(records, record)
 	}
-
-	return nil
+	return records, nil
 }
 
-func (m *monitoringExporter) processMetrics(md pmetric.Metrics) ([]byte, error) {
-	// Crear una estructura para las métricas transformadas
-	type transformedMetric struct {
-		Timestamp  int64                  `json:"timestamp"`
-		Properties map[string]interface{} `json:"properties"`
-		Values     map[string]interface{} `json:"values"`
+// newMetricRecord construye el registro de un punto: properties con el
+// resource, el nombre y los atributos del punto, y el valor bajo su nombre
+func (m *monitoringExporter) newMetricRecord(name string, res metricResource, ts pcommon.Timestamp, attrs pcommon.Map, value interface{}) transformedMetric {
+	properties := make(map[string]interface{})
+	if !m.resourceAsString {
+		// formateamos los . por _ en los nombres de las keys
+		m.putAttrs(properties, res.attrs)
 	}
+	properties["name"] = name
 
-	var transformedMetrics []transformedMetric
-
-	// Iterar sobre las métricas para transformarlas
-	resourceMetrics := md.ResourceMetrics()
-	for i := 0; i < resourceMetrics.Len(); i++ {
-		resourceMetric := resourceMetrics.At(i)
-		resourceAttrs := resourceMetric.Resource().Attributes().AsRaw()
-
-		scopeMetrics := resourceMetric.ScopeMetrics()
-		for j := 0; j < scopeMetrics.Len(); j++ {
-			scopeMetric := scopeMetrics.At(j)
-			metrics := scopeMetric.Metrics()
-			for k := 0; k < metrics.Len(); k++ {
-				metric := metrics.At(k)
-
-				// Iterar sobre los puntos de datos de la métrica
-				switch metric.Type() {
-				case pmetric.MetricTypeSum:
-					dataPoints := metric.Sum().DataPoints()
-					for l := 0; l < dataPoints.Len(); l++ {
-						dataPoint := dataPoints.At(l)
-						properties := make(map[string]interface{})
-						for k, v := range resourceAttrs {
-							// formateamos los . por _ en los nombres de las keys
-							cleanKey := sanitizeName(k)
-							properties[cleanKey] = v
-
-						}
-						properties["name"] = metric.Name()
-						dataPoint.Attributes().Range(func(k string, v pcommon.Value) bool {
-							cleanKey := sanitizeName(k)
-							properties[cleanKey] = v.AsRaw()
+	// Atributos del punto: en properties o bajo su propia clave
+	attributes := properties
+	if m.dataPointAttributesKey != "" {
+		attributes = make(map[string]interface{})
+	}
+	m.putAttrs(attributes, attrs.AsRaw())
+
+	record := transformedMetric{
+		Timestamp:  ts.AsTime().UnixNano(),
+		Properties: properties,
+		Values: map[string]interface{}{
+			m.valueKey(name, attrs): value,
+		},
+		Resource: res.json,
+	}
+	if m.dataPointAttributesKey != "" {
+		record.attributesKey = m.dataPointAttributesKey
+		record.Attributes = attributes
+	}
+	if m.includeSeriesHash {
+		record.SeriesHash = seriesHash(name, attrs)
+	}
+	return record
+}
 
-							return true
-						})
+// latestDataPoints devuelve, con latest_only, los índices del punto más
+// reciente de cada serie (mismo conjunto de atributos); nil si está desactivado
+func (m *monitoringExporter) latestDataPoints(name string, dataPoints pmetric.NumberDataPointSlice) map[int]bool {
+	if !m.latestOnly {
+		return nil
+	}
+	newest := make(map[string]int)
+	for l := 0; l < dataPoints.Len(); l++ {
+		key := seriesHash(name, dataPoints.At(l).Attributes())
+		if prev, ok := newest[key]; !ok || dataPoints.At(l).Timestamp() >= dataPoints.At(prev).Timestamp() {
+			newest[key] = l
+		}
+	}
+	keep := make(map[int]bool, len(newest))
+	for _, l := range newest {
+		keep[l] = true
+	}
+	return keep
+}
 
-						values := map[string]interface{}{
-							metric.Name(): dataPoint.IntValue(),
-						}
+// valueKey es la clave del valor en "values": el nombre de la métrica y, con
+// key_data_points_by_attributes, sus atributos ordenados por clave (filtrados
+// y redactados como en properties)
+func (m *monitoringExporter) valueKey(name string, attrs pcommon.Map) string {
+	key := m.metricKey(name)
+	if !m.keyDataPointsByAttributes || attrs.Len() == 0 {
+		return key
+	}
+	pairs := make([]string, 0, attrs.Len())
+	attrs.Range(func(k string, v pcommon.Value) bool {
+		if m.attrAllowed(k) {
+			pairs = append(pairs, m.attrKey(k)+"="+m.redactString(k, v.AsString()))
+		}
+		return true
+	})
+	sort.Strings(pairs)
+	return key + "{" + strings.Join(pairs, ",") + "}"
+}
 
-						transformedMetrics = append(transformedMetrics, transformedMetric{
-							Timestamp:  dataPoint.Timestamp().AsTime().UnixNano(),
-							Properties: properties,
-							Values:     values,
-						})
-					}
-				case pmetric.MetricTypeGauge:
-					dataPoints := metric.Gauge().DataPoints()
-					for l := 0; l < dataPoints.Len(); l++ {
-						dataPoint := dataPoints.At(l)
-						properties := make(map[string]interface{})
-						for k, v := range resourceAttrs {
-							cleanKey := sanitizeName(k)
-							properties[cleanKey] = v
+// seriesHash es el FNV-1a de 64 bits del nombre y los atributos del punto;
+// json.Marshal ordena las claves, así que no depende del orden de llegada
+func seriesHash(name string, attrs pcommon.Map) string {
+	attrsJSON, _ := json.Marshal(attrs.AsRaw())
+	h := fnv.New64a()
+	h.Write([]byte(name))
+	h.Write([]byte{0})
+	h.Write(attrsJSON)
+	return fmt.Sprintf("%016x", h.Sum64())
+}
 
-						}
-						properties["name"] = metric.Name()
-						dataPoint.Attributes().Range(func(k string, v pcommon.Value) bool {
-							cleanKey := sanitizeName(k)
-							properties[cleanKey] = v.AsRaw()
+// numberValue devuelve el valor del punto según su tipo (entero o double).
+// Los double se redondean a decimal_places y los NaN/Inf se tratan según
+// non_finite_handling; ok=false indica que el punto debe descartarse
+func (m *monitoringExporter) numberValue(dp pmetric.NumberDataPoint) (interface{}, bool, error) {
+	if dp.ValueType() != pmetric.NumberDataPointValueTypeDouble {
+		return dp.IntValue(), true, nil
+	}
+	v := dp.DoubleValue()
+	if !math.IsNaN(v) && !math.IsInf(v, 0) {
+		if m.decimalPlaces >= 0 {
+			p := math.Pow10(m.decimalPlaces)
+			v = math.Round(v*p) / p
+		}
+		return v, true, nil
+	}
+	switch m.nonFiniteHandling {
+	case "error":
+		return nil, false, fmt.Errorf("valor no finito %v", v)
+	case "skip":
+		return nil, false, nil
+	case "string":
+		if math.IsNaN(v) {
+			return "NaN", true, nil
+		}
+		if v > 0 {
+			return "+Inf", true, nil
+		}
+		return "-Inf", true, nil
+	default:
+		return nil, true, nil
+	}
+}
 
-							return true
-						})
+// metricOverrideURL devuelve el endpoint de metric_endpoint_overrides para la
+// métrica o la URL del resource si no tiene
+func (m *monitoringExporter) metricOverrideURL(name, resourceURL string) string {
+	if url, ok := m.metricEndpointOverrides[name]; ok {
+		return url
+	}
+	return resourceURL
+}
 
-						values := map[string]interface{}{
-							metric.Name(): dataPoint.IntValue(),
-						}
+// metricsURL construye la URL de envío de métricas para un resource
+func (m *monitoringExporter) metricsURL(resAttrs pcommon.Map) string {
+	if m.metricsURLTemplate != "" && m.useURLTemplate(resAttrs) {
+		vars := map[string]string{"region": m.region, "ns": m.ns, "metricsets": m.metricsets}
+		return expandURLTemplate(m.metricsURLTemplate, vars, resAttrs)
+	}
+	vars := map[string]string{"region": m.region, "ns": m.ns, "metricsets": m.metricsets}
+	return m.defaultURL("mu", "/v0", m.metricsPathSuffix, vars, resAttrs)
+}
 
-						transformedMetrics = append(transformedMetrics, transformedMetric{
-							Timestamp:  dataPoint.Timestamp().AsTime().UnixNano(),
-							Properties: properties,
-							Values:     values,
-						})
-					}
-				}
-			}
-		}
+func (m *monitoringExporter) processMetrics(transformedMetrics []transformedMetric, batchID string) ([]byte, error) {
+	payload := map[string]interface{}{"metrics": transformedMetrics}
+	if batchID != "" {
+		payload["batchId"] = batchID
+	}
+	if m.collectorHost != "" {
+		payload["collector_host"] = m.collectorHost
+	}
+	if m.includeSignalType {
+		payload["signal"] = "metric"
 	}
 
 	// Serializar las metricas transformadas a JSON
-	data, err := json.Marshal(map[string]interface{}{"metrics": transformedMetrics})
+	data, err := m.marshalRecords(payload)
 	if err != nil {
 		return nil, fmt.Errorf("error al transformar métricas: %w", err)
 	}
@@ -534,28 +2178,89 @@ func (m *monitoringExporter) pushMetrics(ctx context.Context, md pmetric.Metrics
 		return nil
 	}
 
-	// Procesar las metricas antes de enviarlas
-	data, err := m.processMetrics(md)
+	// Registro previo de las métricas nuevas
+	if m.metadataEndpoint != "" {
+		if err := m.registerMetricMetadata(ctx, md); err != nil {
+			return err
+		}
+	}
+
+	// Identificador del lote (cabecera + payload)
+	ctx, batchID := m.withBatchID(ctx)
+
+	// Transformar y agrupar las metricas por URL destino
+	metrics, createUrls, err := m.transformMetrics(md)
 	if err != nil {
 		return err
 	}
-	// Log claro del JSON que realmente enviamos
-	//fmt.Printf("Metrics JSON to send: %s\n", string(data))
-	urlcomose := fmt.Sprintf(("https://mu.%s/v0/ns/%s/metric-sets/%s:addMeasurements"), m.region, m.ns, m.metricsets)
-	//Test()
-	// Enviar los datos procesados a postJSON
-	return m.postJSON(ctx, urlcomose, data)
+	urlToBody := make(map[string][]transformedMetric)
+	var urls []string
+	for i, url := range createUrls {
+		if _, ok := urlToBody[url]; !ok {
+			urls = append(urls, url)
+		}
+		urlToBody[url] = append(urlToBody[url], metrics[i])
+	}
+	if len(urls) == 0 {
+		// Sin registros: se mantiene el envío a la URL por defecto
+		urls = append(urls, m.metricsURL(pcommon.NewMap()))
+	}
+
+	encode := func(records []transformedMetric) ([]byte, error) {
+		return m.processMetrics(records, batchID)
+	}
+	for _, url := range urls {
+		// Procesar las metricas antes de enviarlas
+		bodies, err := splitPayload(m, urlToBody[url], encode)
+		if err != nil {
+			return err
+		}
+		// Log claro del JSON que realmente enviamos
+		//fmt.Printf("Metrics JSON to send: %s\n", string(data))
+		//Test()
+		// Enviar los datos procesados a postJSON
+		for _, data := range bodies {
+			if err := m.postJSON(ctx, url, data); err != nil {
+				return fmt.Errorf("error sending data to URL %s: %w", url, err)
+			}
+		}
+	}
+	if m.staleness != nil {
+		m.staleness.commit(metrics)
+	}
+	return nil
 }
 
 // Crear una estructura para los logs transformados
 type transformedLog struct {
-	MrId         string                 `json:"mrid"`
-	Level        string                 `json:"level"`
-	Message      string                 `json:"message"`
-	CreationDate int64                  `json:"creationDate"`
-	SpanId       string                 `json:"spanId"`
-	TraceId      string                 `json:"traceId"`
-	Properties   map[string]interface{} `json:"properties"`
+	MrId           string                 `json:"mrid"`
+	Level          string                 `json:"level"`
+	SeverityNumber int32                  `json:"severity_number"`
+	Message        interface{}            `json:"message"` // texto o JSON parseado (parse_json_log_bodies)
+	CreationDate   int64                  `json:"creationDate"`
+	SpanId         string                 `json:"spanId"`
+	TraceId        string                 `json:"traceId"`
+	Properties     map[string]interface{} `json:"properties"`
+	// Resource separado de properties (deduplicación o resource_as_string) y,
+	// con deduplicación, su índice en "resources"
+	Resource      interface{} `json:"resource,omitempty"`
+	ResourceRef   *int        `json:"resource_ref,omitempty"`
+	BatchID       string      `json:"batchId,omitempty"`
+	CollectorHost string      `json:"collector_host,omitempty"`
+	Signal        string      `json:"signal,omitempty"`
+	// Ubicación en el código fuente (file, line, function) si hoist_log_source
+	Source map[string]interface{} `json:"source,omitempty"`
+	// true si el mensaje se recortó por max_log_body_bytes
+	BodyTruncated bool `json:"body_truncated,omitempty"`
+	// true si detect_pii encontró datos personales en el body o los atributos
+	ContainsPII bool `json:"contains_pii,omitempty"`
+	// IDs de traza y span en hex para enlazar con la traza; vacíos si no vienen
+	TraceIDHex string `json:"trace_id,omitempty"`
+	SpanIDHex  string `json:"span_id,omitempty"`
+	// Instrumentation scope (nombre y versión) del registro
+	Scope *outScope `json:"scope,omitempty"`
+	// Igual que en los spans, solo si es distinto de 0
+	DroppedAttributesCount uint32 `json:"dropped_attributes_count,omitempty"`
 }
 
 func (m *monitoringExporter) processLogs(ld plog.Logs) ([]byte, error) {
@@ -576,7 +2281,7 @@ func (m *monitoringExporter) processLogs(ld plog.Logs) ([]byte, error) {
 				// Crear un mapa para las propiedades
 				properties := make(map[string]interface{})
 				logRecord.Attributes().Range(func(k string, v pcommon.Value) bool {
-					cleanKey := sanitizeName(k)
+					cleanKey := m.attrKey(k)
 					properties[cleanKey] = v.AsRaw()
 					return true
 				})
@@ -608,19 +2313,9 @@ func (m *monitoringExporter) processLogs(ld plog.Logs) ([]byte, error) {
 
 // Transforma OTel Logs -> Atenea JSON
 func (m *monitoringExporter) transformLogs(ld plog.Logs, cfg transformCfg) ([]byte, []string, error) {
-	type transformedLog struct {
-		MrId         string                 `json:"mrid"`
-		Level        string                 `json:"level"`
-		Message      string                 `json:"message"`
-		CreationDate int64                  `json:"creationDate"`
-		SpanId       string                 `json:"spanId"`
-		TraceId      string                 `json:"traceId"`
-		Properties   map[string]interface{} `json:"properties"`
-		//CreateUrl    string                 `json:"CreateUrl,omitempty"`
-	}
-
 	var transformedLogs []transformedLog
 	var createUrls []string
+	dropped := 0
 
 	// Iterar sobre los logs para transformarlos
 	resourceLogs := ld.ResourceLogs()
@@ -631,9 +2326,15 @@ func (m *monitoringExporter) transformLogs(ld plog.Logs, cfg transformCfg) ([]by
 		scopeLogs := resourceLog.ScopeLogs()
 		for j := 0; j < scopeLogs.Len(); j++ {
 			scopeLog := scopeLogs.At(j)
+			scope := newOutScope(scopeLog.Scope())
 			logRecords := scopeLog.LogRecords()
 			for k := 0; k < logRecords.Len(); k++ {
 				logRecord := logRecords.At(k)
+				// Descartar registros sin body si el sink lo exige
+				if m.requireLogBody && logRecord.Body().Type() == pcommon.ValueTypeEmpty {
+					dropped++
+					continue
+				}
 				mrID := getAttrString(logRecord.Attributes(), "mrid")
 				if mrID == "" {
 					mrID = getAttrString(resourceLog.Resource().Attributes(), "mrid") //"service.name")
@@ -670,43 +2371,101 @@ func (m *monitoringExporter) transformLogs(ld plog.Logs, cfg transformCfg) ([]by
 
 				// Crear un mapa para las propiedades
 				properties := make(map[string]interface{})
-				for k, v := range resourceAttrs {
-					cleanKey := sanitizeName(k)
-					properties[cleanKey] = v
+				var resource interface{}
+				if m.dedupLogResources || m.resourceAsString {
+					// El resource va en su propio campo (o una sola vez en "resources")
+					resourceMap := make(map[string]interface{})
+					for k, v := range resourceAttrs {
+						resourceMap[k] = v
+					}
+					for _, k := range internalAttrs {
+						delete(resourceMap, k)
+					}
+					if m.resourceAsString {
+						resourceJSON, err := m.resourceString(resourceMap)
+						if err != nil {
+							return nil, nil, err
+						}
+						resource = resourceJSON
+					} else {
+						sanitized := make(map[string]interface{}, len(resourceMap))
+						m.putAttrs(sanitized, resourceMap)
+						resource = sanitized
+					}
+				} else {
+					m.putAttrs(properties, resourceAttrs)
+				}
+				m.putAttrs(properties, logRecord.Attributes().AsRaw())
+				m.deleteInternalAttrs(properties)
+
+				var source map[string]interface{}
+				if m.hoistLogSource {
+					source = m.hoistSource(logRecord.Attributes(), properties)
+				}
+
+				var message interface{}
+				text, truncated := m.truncateLogBody(logRecord.Body().AsString())
+				message = text
+				if m.parseJSONLogBodies && !truncated && logRecord.Body().Type() == pcommon.ValueTypeStr {
+					if parsed, ok := parseJSONBody(text); ok {
+						message = parsed
+					}
 				}
-				logRecord.Attributes().Range(func(k string, v pcommon.Value) bool {
-					cleanKey := sanitizeName(k)
-					properties[cleanKey] = v.AsRaw()
-					return true
-				})
-				delete(properties, "mrid")
-				delete(properties, "parentspan")
-				delete(properties, "ns")
-				delete(properties, "region")
 
 				// Crear el log transformado
 				transformedLog := transformedLog{
-					MrId:         mrID, // Usar el namespace como MrId
-					Level:        logRecord.SeverityText(),
-					Message:      logRecord.Body().AsString(),
-					CreationDate: logRecord.Timestamp().AsTime().UnixNano(),
-					SpanId:       spanHexToUUID(logRecord.SpanID().String()),
-					TraceId:      spanHexToUUID(logRecord.TraceID().String()),
-					Properties:   properties,
+					MrId:           mrID, // Usar el namespace como MrId
+					Level:          severityText(logRecord),
+					SeverityNumber: int32(logRecord.SeverityNumber()),
+					Message:        message,
+					CreationDate:   logRecord.Timestamp().AsTime().UnixNano(),
+					SpanId:         spanHexToUUID(logRecord.SpanID().String()),
+					TraceId:        spanHexToUUID(logRecord.TraceID().String()),
+					Properties:     properties,
+					Resource:       resource,
+					Source:         source,
+					BodyTruncated:  truncated,
+					Scope:          scope,
+
+					DroppedAttributesCount: logRecord.DroppedAttributesCount(),
+				}
+				if !logRecord.TraceID().IsEmpty() {
+					transformedLog.TraceIDHex = logRecord.TraceID().String()
+				}
+				if !logRecord.SpanID().IsEmpty() {
+					transformedLog.SpanIDHex = logRecord.SpanID().String()
+				}
+				if len(m.piiPatterns) > 0 {
+					transformedLog.ContainsPII = m.logContainsPII(logRecord.Body().AsString(), logRecord.Attributes())
 				}
 
 				// Generar CreateUrl si es necesario
-				if regionAtt != "" && regionAtt != "unknown" && nsAtt != "" && nsAtt != "unknown" {
+				createUrl := ""
+				if m.logsURLTemplate != "" && m.useURLTemplate(resourceLog.Resource().Attributes()) {
+					vars := map[string]string{"region": regionAtt, "ns": nsAtt, "mrid": mrID}
+					createUrl = expandURLTemplate(m.logsURLTemplate, vars, logRecord.Attributes(), resourceLog.Resource().Attributes())
+				} else if regionAtt != "" && regionAtt != "unknown" && nsAtt != "" && nsAtt != "unknown" {
 					//createUrl := fmt.Sprintf("https://logs.example.com/v1/ns/%s/logs", cfg.UserNamespace)
 					//transformedLog.CreateUrl = createUrl
-					createUrls = append(createUrls, fmt.Sprintf("https://omega.%s/v1/ns/%s/logs", regionAtt, nsAtt))
+					vars := map[string]string{"region": regionAtt, "ns": nsAtt, "mrid": mrID}
+					createUrl = m.defaultURL("omega", "/v1", m.logsPathSuffix, vars, logRecord.Attributes(), resourceLog.Resource().Attributes())
 
 				}
+				// El enrutado por severidad tiene prioridad sobre la URL calculada
+				if routed, ok := m.severityRoute(logRecord.SeverityNumber()); ok {
+					createUrl = routed
+				}
+				if createUrl != "" {
+					createUrls = append(createUrls, createUrl)
+				}
 
 				transformedLogs = append(transformedLogs, transformedLog)
 			}
 		}
 	}
+	if dropped > 0 {
+		m.logger.Warn("logs sin body descartados", zap.Int("dropped", dropped))
+	}
 
 	// Serializar los logs transformados a JSON
 	data, err := json.MarshalIndent(transformedLogs, "", "  ")
@@ -733,6 +2492,10 @@ func (m *monitoringExporter) transformLogs(ld plog.Logs, cfg transformCfg) ([]by
 // }
 
 func (m *monitoringExporter) pushLogs(ctx context.Context, ld plog.Logs) error {
+	return m.pushLogsWith(ctx, ld, m.encodeLogs, m.postJSON)
+}
+
+func (m *monitoringExporter) pushLogsWith(ctx context.Context, ld plog.Logs, encodeLogs func([]transformedLog) ([]byte, error), post func(context.Context, string, []byte) error) error {
 	// if m.logsURL == "" {
 	// 	return nil
 	// }
@@ -759,21 +2522,40 @@ func (m *monitoringExporter) pushLogs(ctx context.Context, ld plog.Logs) error {
 	if err := json.Unmarshal(out, &logs); err != nil {
 		return fmt.Errorf("error unmarshaling transformed logs: %w", err)
 	}
+	// Identificador del lote (cabecera + payload)
+	ctx, batchID := m.withBatchID(ctx)
+
 	urlToBody := make(map[string][]transformedLog)
 	for i, url := range createUrls {
+		logs[i].BatchID = batchID
+		logs[i].CollectorHost = m.collectorHost
+		logs[i].Signal = m.signalType("log")
 		urlToBody[url] = append(urlToBody[url], logs[i])
 	}
 
 	for url, logs := range urlToBody {
-		body, err := json.Marshal(logs)
+		if m.sortLogsByTimestamp {
+			sort.SliceStable(logs, func(a, b int) bool {
+				return logs[a].CreationDate < logs[b].CreationDate
+			})
+		}
+		encode := encodeLogs
+		if m.aggregateLogTemplates {
+			encode = func(logs []transformedLog) ([]byte, error) {
+				return m.encodeLogTemplates(logs, batchID)
+			}
+		}
+		bodies, err := splitPayload(m, logs, encode)
 		if err != nil {
 			return fmt.Errorf("error marshaling logs for URL %s: %w", url, err)
 		}
 		// Log claro del JSON que realmente enviamos
 		//fmt.Printf("Custom Logs JSON to send >>> %s\n %s", string(body), url)
 		// Enviar los datos a la URL
-		if err := m.postJSON(ctx, url, body); err != nil {
-			return fmt.Errorf("error sending data to URL %s: %w", url, err)
+		for _, body := range bodies {
+			if err := post(ctx, url, body); err != nil {
+				return fmt.Errorf("error sending data to URL %s: %w", url, err)
+			}
 		}
 		// Enviar los datos transformados
 
@@ -781,6 +2563,137 @@ func (m *monitoringExporter) pushLogs(ctx context.Context, ld plog.Logs) error {
 	return nil
 }
 
+// truncateLogBody recorta el mensaje a max_log_body_bytes sin partir caracteres
+// UTF-8 y añade la marca de truncado
+func (m *monitoringExporter) truncateLogBody(message string) (string, bool) {
+	if m.maxLogBodyBytes <= 0 || len(message) <= m.maxLogBodyBytes {
+		return message, false
+	}
+	cut := m.maxLogBodyBytes
+	for cut > 0 && !utf8.RuneStart(message[cut]) {
+		cut--
+	}
+	return message[:cut] + "...<truncated>", true
+}
+
+// Atributos de ubicación en código (semconv actual y anterior) -> campo de "source"
+var logSourceAttrs = []struct{ key, field string }{
+	{"code.file.path", "file"},
+	{"code.filepath", "file"},
+	{"code.line.number", "line"},
+	{"code.lineno", "line"},
+	{"code.function.name", "function"},
+	{"code.function", "function"},
+}
+
+// severityRoute devuelve el endpoint de la primera ruta de log_severity_routes
+// cuyo rango incluye la severidad del registro
+func (m *monitoringExporter) severityRoute(severity plog.SeverityNumber) (string, bool) {
+	for _, route := range m.logSeverityRoutes {
+		if int(severity) < route.MinSeverity {
+			continue
+		}
+		if route.MaxSeverity > 0 && int(severity) > route.MaxSeverity {
+			continue
+		}
+		return route.Endpoint, true
+	}
+	return "", false
+}
+
+// severityNames son los textos canónicos de cada rango de SeverityNumber
+var severityNames = []string{"TRACE", "DEBUG", "INFO", "WARN", "ERROR", "FATAL"}
+
+// severityText devuelve el severity text del registro o, si viene vacío, el
+// texto canónico de su SeverityNumber ("" si tampoco tiene número)
+func severityText(record plog.LogRecord) string {
+	if text := record.SeverityText(); text != "" {
+		return text
+	}
+	n := int(record.SeverityNumber())
+	if n < 1 || n > 24 {
+		return ""
+	}
+	return severityNames[(n-1)/4]
+}
+
+// parseJSONBody parsea el body si es un objeto o array JSON válido
+func parseJSONBody(body string) (interface{}, bool) {
+	trimmed := strings.TrimSpace(body)
+	if trimmed == "" || (trimmed[0] != '{' && trimmed[0] != '[') {
+		return nil, false
+	}
+	var parsed interface{}
+	if err := json.Unmarshal([]byte(trimmed), &parsed); err != nil {
+		return nil, false
+	}
+	return parsed, true
+}
+
+// hoistSource extrae los atributos de ubicación en código a un objeto
+// {file, line, function}, quitándolos de properties. Los campos ausentes o
+// filtrados por attribute_allowlist/denylist se omiten y devuelve nil si no
+// hay ninguno
+func (m *monitoringExporter) hoistSource(attrs pcommon.Map, properties map[string]interface{}) map[string]interface{} {
+	var source map[string]interface{}
+	for _, a := range logSourceAttrs {
+		v, ok := attrs.Get(a.key)
+		if !ok || !m.attrAllowed(a.key) {
+			continue
+		}
+		delete(properties, m.attrKey(a.key))
+		if source == nil {
+			source = make(map[string]interface{})
+		}
+		if _, set := source[a.field]; !set {
+			source[a.field] = m.redactValue(a.key, a.key, m.attrValue(v.AsRaw()))
+		}
+	}
+	return source
+}
+
+// encodeLogs serializa un grupo de logs. Con deduplicación de resources genera
+// {"resources": [...], "logs": [...]} donde cada log lleva el índice de su
+// resource en "resource_ref"; los índices son válidos solo dentro del POST
+func (m *monitoringExporter) encodeLogs(logs []transformedLog) ([]byte, error) {
+	if !m.dedupLogResources && !m.dedupLogAttributes {
+		return m.marshalRecords(logs)
+	}
+
+	// Se trabaja sobre una copia: el mismo lote puede volver a codificarse
+	// en trozos (max_payload_bytes)
+	logs = append([]transformedLog(nil), logs...)
+	payload := map[string]interface{}{"logs": logs}
+	if m.dedupLogResources {
+		resources := []interface{}{}
+		index := make(map[string]int)
+		for i := range logs {
+			key, err := json.Marshal(logs[i].Resource)
+			if err != nil {
+				return nil, err
+			}
+			ref, ok := index[string(key)]
+			if !ok {
+				ref = len(resources)
+				index[string(key)] = ref
+				resources = append(resources, logs[i].Resource)
+			}
+			logs[i].ResourceRef = &ref
+			logs[i].Resource = nil
+		}
+		payload["resources"] = resources
+	}
+	if m.dedupLogAttributes {
+		entries, dictLogs, err := buildAttributeDictionary(logs)
+		if err != nil {
+			return nil, err
+		}
+		payload["attributes"] = entries
+		payload["logs"] = dictLogs
+	}
+	return m.marshalRecords(payload)
+}
+
 // func (m *monitoringExporter) postJSON(ctx context.Context, url string, body []byte) error {
 // 	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
 // 	if err != nil {
@@ -806,17 +2719,168 @@ func (m *monitoringExporter) pushLogs(ctx context.Context, ld plog.Logs) error {
 //		return nil
 //	}
 func (m *monitoringExporter) postJSON(ctx context.Context, url string, body []byte) error {
-	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if m.coalescer != nil {
+		return m.coalescer.post(ctx, url, body, m.postJSONNow)
+	}
+	return m.postJSONNow(ctx, url, body)
+}
+
+// postJSONNow envía body sin pasar por el coalescer
+func (m *monitoringExporter) postJSONNow(ctx context.Context, url string, body []byte) error {
+	if m.canonical {
+		canonical, err := canonicalizeJSON(body)
+		if err != nil {
+			return fmt.Errorf("error canonicalizing payload for URL %s: %w", url, err)
+		}
+		body = canonical
+	}
+	return m.postPayload(ctx, url, body, jsonHeaders)
+}
+
+// permanentStatus indica si el código es un rechazo del payload que no se
+// arregla reintentando: los 4xx salvo 408, 425 y 429
+func permanentStatus(code int) bool {
+	switch code {
+	case http.StatusRequestTimeout, http.StatusTooEarly, http.StatusTooManyRequests:
+		return false
+	}
+	return code >= 400 && code < 500
+}
+
+// mergeHeaders devuelve una copia de base con override encima; base sin
+// cambios si override está vacío
+func mergeHeaders(base, override map[string]string) map[string]string {
+	if len(override) == 0 {
+		return base
+	}
+	out := make(map[string]string, len(base)+len(override))
+	for k, v := range base {
+		out[k] = v
+	}
+	for k, v := range override {
+		out[k] = v
+	}
+	return out
+}
+
+// jsonHeaders son las cabeceras de contenido de los payloads JSON
+var jsonHeaders = http.Header{"Content-Type": {"application/json"}}
+
+// postPayload envía body con las cabeceras de contenido indicadas, pasando
+// antes por el circuit breaker si está configurado, y registra la petición
+// en las métricas propias
+func (m *monitoringExporter) postPayload(ctx context.Context, url string, body []byte, contentHeaders http.Header) error {
+	var probe bool
+	if m.breaker != nil {
+		var err error
+		if probe, err = m.breaker.allow(time.Now(), url); err != nil {
+			return err
+		}
+	}
+	start := time.Now()
+	err := m.sendPayload(ctx, url, body, contentHeaders)
+	m.telemetry.record(ctx, time.Since(start), len(body), err)
+	if m.breaker != nil {
+		m.breaker.record(time.Now(), probe, err)
+	}
+	return err
+}
+
+func (m *monitoringExporter) sendPayload(ctx context.Context, url string, body []byte, contentHeaders http.Header) error {
+	if m.requestBaseTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, m.requestTimeout(len(body)))
+		defer cancel()
+	}
+
+	if m.preflight {
+		if err := m.preflightCheck(ctx, url); err != nil {
+			m.logFailedRequest(err, url, body)
+			return err
+		}
+	}
+
+	// Compresión y después cifrado sobre el body final; el resto de la función
+	// sigue usando el texto plano para el digest y el log de fallos. Los
+	// formatos que ya traen Content-Encoding (snappy) no se recomprimen
+	payload := body
+	gzipped := false
+	if m.compression == compressionGzip && contentHeaders.Get("Content-Encoding") == "" {
+		compressed, err := gzipBody(body)
+		if err != nil {
+			m.logFailedRequest(err, url, body)
+			return err
+		}
+		payload = compressed
+		gzipped = true
+	}
+	if m.encryptor != nil {
+		sealed, err := m.encryptor.seal(payload)
+		if err != nil {
+			m.logFailedRequest(err, url, body)
+			return err
+		}
+		payload = sealed
+	}
+
+	req, err := http.NewRequestWithContext(ctx, m.method, url, bytes.NewReader(payload))
 	if err != nil {
 		m.logFailedRequest(err, url, body)
 		return err
 	}
 
-	req.Header.Set("Content-Type", "application/json")
+	for k, vs := range contentHeaders {
+		req.Header[k] = vs
+	}
+	if gzipped {
+		req.Header.Set("Content-Encoding", "gzip")
+	}
+	if m.encryptor != nil {
+		req.Header.Set("X-Payload-Encryption", m.encryptor.algorithm)
+	}
 	for k, v := range m.headers {
 		req.Header.Set(k, v)
 	}
+	if contentHeaders.Get("Content-Type") != "application/json" {
+		// Los formatos binarios mantienen sus cabeceras aunque headers
+		// indique otro Content-Type (el valor por defecto es JSON)
+		for k, vs := range contentHeaders {
+			req.Header[k] = vs
+		}
+	}
+	if m.contentDigestHeader != "" {
+		isJSON := contentHeaders.Get("Content-Type") == "application/json"
+		req.Header.Set(m.contentDigestHeader, contentDigest(body, isJSON))
+	}
+	if id, ok := ctx.Value(batchIDKey{}).(string); ok && m.batchIDHeader != "" {
+		req.Header.Set(m.batchIDHeader, id)
+	}
+	if m.idempotencyKeyHeader != "" {
+		isJSON := contentHeaders.Get("Content-Type") == "application/json"
+		req.Header.Set(m.idempotencyKeyHeader, idempotencyKey(ctx, url, body, isJSON))
+	}
+	sendDictionary := false
+	if m.keyDictionaryValue != "" {
+		m.keyDictionaryMu.Lock()
+		sendDictionary = !m.keyDictionarySent[url]
+		m.keyDictionaryMu.Unlock()
+		if sendDictionary {
+			req.Header.Set(m.keyDictionaryHeader, m.keyDictionaryValue)
+		}
+	}
+	// Las firmas van al final: HMAC sobre el payload tal como viaja y SigV4,
+	// que cubre todas las cabeceras ya fijadas (incluida la de HMAC)
+	if m.hmac != nil {
+		m.hmac.sign(req, payload)
+	}
+	if m.sigv4 != nil {
+		if err := m.sigv4.sign(ctx, req, payload); err != nil {
+			m.logFailedRequest(err, url, body)
+			return err
+		}
+	}
 
+	m.connStats.recordRequest(len(payload))
 	resp, err := m.client.Do(req)
 	if err != nil {
 		m.logFailedRequest(err, url, body)
@@ -824,12 +2888,56 @@ func (m *monitoringExporter) postJSON(ctx context.Context, url string, body []by
 	}
 	defer resp.Body.Close()
 
-	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
-		err = fmt.Errorf("monitoring exporter: %s -> HTTP %d", url, resp.StatusCode)
+	// El body se lee una sola vez y lo comparten retry_classification, el
+	// error HTTP y fallback_body_contains
+	failed := resp.StatusCode < 200 || resp.StatusCode >= 300
+	var respBody []byte
+	if failed || len(m.retryClassification) > 0 {
+		respBody, _ = io.ReadAll(io.LimitReader(resp.Body, maxClassifiedBody))
+	}
+
+	// Las reglas del body prevalecen sobre el código HTTP
+	if err := m.classifyResponse(resp.StatusCode, respBody, url); err != nil {
+		m.logFailedRequest(err, url, body)
+		return err
+	}
+
+	if failed {
+		snippet := respBody
+		if len(snippet) > 4096 {
+			snippet = snippet[:4096]
+		}
+		err = &httpStatusError{url: url, status: resp.StatusCode, body: string(snippet)}
+		m.logFailedRequest(err, url, body)
+		if resp.StatusCode == http.StatusTooEarly && m.tooEarlyRetryDelay > 0 {
+			// Reintentable tras una espera fija, sin el backoff exponencial
+			return exporterhelper.NewThrottleRetry(err, m.tooEarlyRetryDelay)
+		}
+		if resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode == http.StatusServiceUnavailable {
+			// El servidor indica cuándo reintentar; sin cabecera se usa el backoff
+			if delay := retryAfter(resp.Header.Get("Retry-After"), time.Now()); delay > 0 {
+				return exporterhelper.NewThrottleRetry(err, delay)
+			}
+		}
+		if permanentStatus(resp.StatusCode) {
+			// Reintentar un payload rechazado solo atasca la cola
+			return consumererror.NewPermanent(err)
+		}
+		return err
+	}
+
+	if err := m.checkStatusHeader(resp, url); err != nil {
 		m.logFailedRequest(err, url, body)
 		return err
 	}
 
+	if sendDictionary {
+		// El diccionario solo se reenvía si el POST que lo llevaba falló
+		m.keyDictionaryMu.Lock()
+		m.keyDictionarySent[url] = true
+		m.keyDictionaryMu.Unlock()
+	}
+
 	m.logger.Debug("monitoring/exporter POST OK",
 		zap.String("url", url),
 		zap.Int("status", resp.StatusCode),
@@ -838,17 +2946,103 @@ func (m *monitoringExporter) postJSON(ctx context.Context, url string, body []by
 	return nil
 }
 
+// requestTimeout devuelve el plazo para un body de size bytes: el timeout base
+// más el tiempo de transferirlo al throughput configurado
+func (m *monitoringExporter) requestTimeout(size int) time.Duration {
+	timeout := m.requestBaseTimeout
+	if m.requestBytesPerSecond > 0 {
+		timeout += time.Duration(float64(size) / float64(m.requestBytesPerSecond) * float64(time.Second))
+	}
+	return timeout
+}
+
+// checkStatusHeader trata como error un código distinto de 0 en status_header,
+// permanente salvo que esté entre los códigos reintentables
+func (m *monitoringExporter) checkStatusHeader(resp *http.Response, url string) error {
+	if m.statusHeader == "" {
+		return nil
+	}
+	value := strings.TrimSpace(resp.Header.Get(m.statusHeader))
+	if value == "" || value == "0" {
+		return nil
+	}
+	code, convErr := strconv.Atoi(value)
+	err := fmt.Errorf("monitoring exporter: %s -> HTTP %d, %s: %s", url, resp.StatusCode, m.statusHeader, value)
+	if convErr != nil || !m.statusHeaderRetryable[code] {
+		return consumererror.NewPermanent(err)
+	}
+	return err
+}
+
+// preflightCheck envía un OPTIONS a la URL y solo permite el POST si responde
+// 2xx. Una respuesta correcta se reutiliza durante preflight_ttl
+func (m *monitoringExporter) preflightCheck(ctx context.Context, url string) error {
+	m.preflightMu.Lock()
+	expires, ok := m.preflightOK[url]
+	m.preflightMu.Unlock()
+	if ok && time.Now().Before(expires) {
+		return nil
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodOptions, url, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Access-Control-Request-Method", m.method)
+	req.Header.Set("Access-Control-Request-Headers", "content-type")
+	for k, v := range m.headers {
+		req.Header.Set(k, v)
+	}
+
+	resp, err := m.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("monitoring exporter: preflight %s: %w", url, err)
+	}
+	resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("monitoring exporter: preflight %s -> HTTP %d", url, resp.StatusCode)
+	}
+
+	m.preflightMu.Lock()
+	m.preflightOK[url] = time.Now().Add(m.preflightTTL)
+	m.preflightMu.Unlock()
+	return nil
+}
+
+// batchIDKey es la clave de contexto del identificador de lote
+type batchIDKey struct{}
+
+// signalType devuelve el discriminador de señal si include_signal_type está activo
+func (m *monitoringExporter) signalType(signal string) string {
+	if !m.includeSignalType {
+		return ""
+	}
+	return signal
+}
+
+// withBatchID genera un UUID para el lote si batch_id_header está configurado y
+// lo guarda en el contexto para que postJSON lo envíe como cabecera
+func (m *monitoringExporter) withBatchID(ctx context.Context) (context.Context, string) {
+	if m.batchIDHeader == "" {
+		return ctx, ""
+	}
+	id := uuid.NewString()
+	return context.WithValue(ctx, batchIDKey{}, id), id
+}
+
 // logFailedRequest guarda errores y cuerpos fallidos en un archivo rotativo con límite de líneas
 func (m *monitoringExporter) logFailedRequest(err error, url string, body []byte) {
-	// Truncar body si excede el límite
+	// Truncar body si excede el límite, sobre una copia: body puede estar
+	// guardado para reenviarlo (ventana de batch, modo combinado)
 	truncatedBody := body
 	if len(body) > m.MaxBodyBytes {
-		truncatedBody = append(body[:m.MaxBodyBytes], []byte("...<truncated>")...)
+		truncatedBody = append(append([]byte(nil), body[:m.MaxBodyBytes]...), "...<truncated>"...)
 	}
 
 	entry := fmt.Sprintf(
 		"[%s] ERROR: %v\nURL: %s\nBODY: %s\n\n",
-		time.Now().Format(time.RFC3339),
+		time.Now().In(m.location).Format(time.RFC3339),
 		err,
 		url,
 		string(truncatedBody),
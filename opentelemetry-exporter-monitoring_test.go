@@ -0,0 +1,1805 @@
+package opentelemetryexportermonitoring
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"math"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/component/componenttest"
+	"go.opentelemetry.io/collector/config/configtls"
+	"go.opentelemetry.io/collector/consumer/consumererror"
+	"go.opentelemetry.io/collector/exporter"
+	"go.opentelemetry.io/collector/exporter/exportertest"
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	"go.opentelemetry.io/collector/pdata/plog"
+	"go.opentelemetry.io/collector/pdata/pmetric"
+	"go.opentelemetry.io/collector/pdata/ptrace"
+	"go.uber.org/zap"
+)
+
+// capturedRequest es una petición recibida por el servidor de prueba
+type capturedRequest struct {
+	Method string
+	Path   string
+	Header http.Header
+	Body   []byte
+}
+
+// captureServer guarda las peticiones que recibe y responde con handler (200
+// sin body si no se indica)
+type captureServer struct {
+	*httptest.Server
+	mu       sync.Mutex
+	requests []capturedRequest
+	handler  http.HandlerFunc
+}
+
+func newCaptureServer(t *testing.T) *captureServer {
+	t.Helper()
+	s := &captureServer{}
+	s.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		s.mu.Lock()
+		s.requests = append(s.requests, capturedRequest{Method: r.Method, Path: r.URL.Path, Header: r.Header.Clone(), Body: body})
+		handler := s.handler
+		s.mu.Unlock()
+		if handler != nil {
+			handler(w, r)
+		}
+	}))
+	t.Cleanup(s.Close)
+	return s
+}
+
+// respond fija la respuesta de las siguientes peticiones
+func (s *captureServer) respond(handler http.HandlerFunc) {
+	s.mu.Lock()
+	s.handler = handler
+	s.mu.Unlock()
+}
+
+// received devuelve una copia de las peticiones recibidas hasta ahora
+func (s *captureServer) received() []capturedRequest {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]capturedRequest(nil), s.requests...)
+}
+
+// testConfig devuelve la config por defecto con las tres señales activas,
+// sin certificados y con las URL de cada señal apuntando a serverURL
+func testConfig(t *testing.T, serverURL string) *Config {
+	t.Helper()
+	cfg := createDefaultConfig().(*Config)
+	cfg.CaCertFile = ""
+	cfg.Traces, cfg.Metrics, cfg.Logs = true, true, true
+	cfg.TracesURLTemplate = serverURL + "/traces"
+	cfg.MetricsURLTemplate = serverURL + "/metrics"
+	cfg.LogsURLTemplate = serverURL + "/logs"
+	cfg.LogFile = filepath.Join(t.TempDir(), "failed.log")
+	// Envío síncrono y sin reintentos salvo que el test los active
+	cfg.QueueSettings.Enabled = false
+	cfg.RetrySettings.Enabled = false
+	return cfg
+}
+
+// startComponent arranca c y lo apaga al terminar el test
+func startComponent(t *testing.T, c component.Component) {
+	t.Helper()
+	if err := c.Start(context.Background(), componenttest.NewNopHost()); err != nil {
+		t.Fatalf("Start: %v", err)
+	}
+	t.Cleanup(func() {
+		if err := c.Shutdown(context.Background()); err != nil {
+			t.Errorf("Shutdown: %v", err)
+		}
+	})
+}
+
+// newFactoryExporters crea y arranca con la factory los exporters de las
+// tres señales
+func newFactoryExporters(t *testing.T, cfg *Config) (exporter.Traces, exporter.Metrics, exporter.Logs) {
+	t.Helper()
+	ctx := context.Background()
+	set := exportertest.NewNopSettings(typeStr)
+	traces, err := createTracesExporter(ctx, set, cfg)
+	if err != nil {
+		t.Fatalf("createTracesExporter: %v", err)
+	}
+	metrics, err := createMetricsExporter(ctx, set, cfg)
+	if err != nil {
+		t.Fatalf("createMetricsExporter: %v", err)
+	}
+	logs, err := createLogsExporter(ctx, set, cfg)
+	if err != nil {
+		t.Fatalf("createLogsExporter: %v", err)
+	}
+	for _, c := range []component.Component{traces, metrics, logs} {
+		startComponent(t, c)
+	}
+	return traces, metrics, logs
+}
+
+func newTestExporter(t *testing.T, cfg *Config) *monitoringExporter {
+	t.Helper()
+	exp, err := newMonitoringExporter(cfg.withFormat(), zap.NewNop())
+	if err != nil {
+		t.Fatalf("newMonitoringExporter: %v", err)
+	}
+	return exp
+}
+
+// decodeJSON deserializa data o falla el test
+func decodeJSON(t *testing.T, data []byte, v interface{}) {
+	t.Helper()
+	if err := json.Unmarshal(data, v); err != nil {
+		t.Fatalf("JSON inválido: %v\n%s", err, data)
+	}
+}
+
+// gaugeMetrics devuelve un lote con un gauge por nombre, con un punto de valor 1
+func gaugeMetrics(names ...string) pmetric.Metrics {
+	md := pmetric.NewMetrics()
+	rm := md.ResourceMetrics().AppendEmpty()
+	rm.Resource().Attributes().PutStr("service.name", "svc")
+	sm := rm.ScopeMetrics().AppendEmpty()
+	for _, name := range names {
+		metric := sm.Metrics().AppendEmpty()
+		metric.SetName(name)
+		dp := metric.SetEmptyGauge().DataPoints().AppendEmpty()
+		dp.SetTimestamp(1_000_000_000)
+		dp.SetIntValue(1)
+	}
+	return md
+}
+
+// valueKeys devuelve las claves de "values" de cada registro
+func valueKeys(records []transformedMetric) []string {
+	var keys []string
+	for _, r := range records {
+		for k := range r.Values {
+			keys = append(keys, k)
+		}
+	}
+	return keys
+}
+
+func TestSanitizeMetricNames(t *testing.T) {
+	tests := []struct {
+		name    string
+		allowed string
+		metric  string
+		want    string
+	}{
+		{"puntos", "", "system.cpu.usage", "system_cpu_usage"},
+		{"dos puntos y guiones", "a-zA-Z0-9_", "http:server-duration", "http_server_duration"},
+		{"puntos permitidos", "a-zA-Z0-9_.", "system.cpu.usage", "system.cpu.usage"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := testConfig(t, "http://localhost")
+			cfg.SanitizeMetricNames = true
+			cfg.MetricNameAllowedChars = tt.allowed
+			exp := newTestExporter(t, cfg)
+
+			records, _, err := exp.transformMetrics(gaugeMetrics(tt.metric))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if keys := valueKeys(records); len(keys) != 1 || keys[0] != tt.want {
+				t.Errorf("claves de values = %v, se esperaba [%s]", keys, tt.want)
+			}
+			if got := records[0].Properties["name"]; got != tt.metric {
+				t.Errorf("properties.name = %v, se esperaba el nombre original %s", got, tt.metric)
+			}
+		})
+	}
+}
+
+func TestMetricNamePrefix(t *testing.T) {
+	for _, tt := range []struct{ prefix, want string }{
+		{"", "cpu.usage"},
+		{"myprefix.", "myprefix.cpu.usage"},
+	} {
+		cfg := testConfig(t, "http://localhost")
+		cfg.MetricNamePrefix = tt.prefix
+		records, _, err := newTestExporter(t, cfg).transformMetrics(gaugeMetrics("cpu.usage"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if keys := valueKeys(records); len(keys) != 1 || keys[0] != tt.want {
+			t.Errorf("prefijo %q: claves de values = %v, se esperaba [%s]", tt.prefix, keys, tt.want)
+		}
+	}
+}
+
+func TestSanitizeMetricNamesDisabled(t *testing.T) {
+	exp := newTestExporter(t, testConfig(t, "http://localhost"))
+	if got := exp.metricKey("system.cpu.usage"); got != "system.cpu.usage" {
+		t.Errorf("metricKey = %q, se esperaba el nombre sin cambios", got)
+	}
+}
+
+func TestHeartbeatOnEmpty(t *testing.T) {
+	md := pmetric.NewMetrics()
+	rm := md.ResourceMetrics().AppendEmpty()
+	rm.Resource().Attributes().PutStr("service.name", "svc")
+	rm.Resource().Attributes().PutStr("host.name", "h1")
+	rm.ScopeMetrics().AppendEmpty()
+
+	cfg := testConfig(t, "http://localhost")
+	cfg.HeartbeatOnEmpty = true
+	exp := newTestExporter(t, cfg)
+
+	records, urls, err := exp.transformMetrics(md)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(records) != 1 || len(urls) != 1 {
+		t.Fatalf("registros = %d, urls = %d; se esperaba un latido", len(records), len(urls))
+	}
+	data, err := json.Marshal(records[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got map[string]interface{}
+	decodeJSON(t, data, &got)
+	if values, ok := got["values"].(map[string]interface{}); !ok || len(values) != 0 {
+		t.Errorf("values = %v, se esperaba un objeto vacío", got["values"])
+	}
+	props, _ := got["properties"].(map[string]interface{})
+	if props["service_name"] != "svc" || props["host_name"] != "h1" {
+		t.Errorf("properties = %v, se esperaban los atributos del resource", props)
+	}
+	if _, ok := props["name"]; ok {
+		t.Errorf("el latido no debe llevar nombre de métrica: %v", props)
+	}
+	if ts, _ := got["timestamp"].(float64); ts <= 0 {
+		t.Errorf("timestamp = %v, se esperaba la hora actual", got["timestamp"])
+	}
+	if urls[0] != cfg.MetricsURLTemplate {
+		t.Errorf("url = %s, se esperaba la del resource", urls[0])
+	}
+
+	exp.heartbeatOnEmpty = false
+	if records, _, _ := exp.transformMetrics(md); len(records) != 0 {
+		t.Errorf("sin heartbeat_on_empty se emitieron %d registros", len(records))
+	}
+}
+
+func TestMetricsURLTemplatePathSubstitution(t *testing.T) {
+	srv := newCaptureServer(t)
+	cfg := testConfig(t, srv.URL)
+	cfg.MetricsURLTemplate = srv.URL + "/ingest/{service}/metrics"
+	exp := newTestExporter(t, cfg)
+
+	md := pmetric.NewMetrics()
+	for _, svc := range []string{"svc-a", "svc-b", "svc-a"} {
+		rm := md.ResourceMetrics().AppendEmpty()
+		rm.Resource().Attributes().PutStr("service.name", svc)
+		metric := rm.ScopeMetrics().AppendEmpty().Metrics().AppendEmpty()
+		metric.SetName("requests")
+		metric.SetEmptyGauge().DataPoints().AppendEmpty().SetIntValue(1)
+	}
+	if err := exp.pushMetrics(context.Background(), md); err != nil {
+		t.Fatal(err)
+	}
+
+	got := make(map[string][]string)
+	for _, req := range srv.received() {
+		var payload struct {
+			Metrics []transformedMetric `json:"metrics"`
+		}
+		decodeJSON(t, req.Body, &payload)
+		for _, record := range payload.Metrics {
+			got[req.Path] = append(got[req.Path], record.Properties["service_name"].(string))
+		}
+	}
+	want := map[string][]string{
+		"/ingest/svc-a/metrics": {"svc-a", "svc-a"},
+		"/ingest/svc-b/metrics": {"svc-b"},
+	}
+	if len(got) != len(want) {
+		t.Fatalf("rutas = %v, se esperaba %v", got, want)
+	}
+	for path, services := range want {
+		sort.Strings(got[path])
+		if len(got[path]) != len(services) || got[path][0] != services[0] || got[path][len(services)-1] != services[len(services)-1] {
+			t.Errorf("%s recibió %v, se esperaba %v", path, got[path], services)
+		}
+	}
+}
+
+// logsWithResources devuelve un lote con un resource por service.name y
+// records registros de log en cada uno
+func logsWithResources(records int, services ...string) plog.Logs {
+	ld := plog.NewLogs()
+	for _, svc := range services {
+		rl := ld.ResourceLogs().AppendEmpty()
+		rl.Resource().Attributes().PutStr("service.name", svc)
+		sl := rl.ScopeLogs().AppendEmpty()
+		for i := 0; i < records; i++ {
+			lr := sl.LogRecords().AppendEmpty()
+			lr.SetTimestamp(1_000_000_000)
+			lr.Body().SetStr(svc + " log")
+		}
+	}
+	return ld
+}
+
+// pushedLogs envía ld con pushLogs y devuelve los payloads recibidos
+func pushedLogs(t *testing.T, exp *monitoringExporter, srv *captureServer, ld plog.Logs) []capturedRequest {
+	t.Helper()
+	if err := exp.pushLogs(context.Background(), ld); err != nil {
+		t.Fatal(err)
+	}
+	return srv.received()
+}
+
+func TestDedupLogResources(t *testing.T) {
+	srv := newCaptureServer(t)
+	cfg := testConfig(t, srv.URL)
+	cfg.DedupLogResources = true
+	exp := newTestExporter(t, cfg)
+
+	reqs := pushedLogs(t, exp, srv, logsWithResources(2, "svc-a", "svc-b"))
+	if len(reqs) != 1 {
+		t.Fatalf("peticiones = %d, se esperaba 1", len(reqs))
+	}
+	var payload struct {
+		Resources []map[string]interface{} `json:"resources"`
+		Logs      []map[string]interface{} `json:"logs"`
+	}
+	decodeJSON(t, reqs[0].Body, &payload)
+	if len(payload.Resources) != 2 || len(payload.Logs) != 4 {
+		t.Fatalf("resources = %d, logs = %d; se esperaban 2 y 4", len(payload.Resources), len(payload.Logs))
+	}
+	for _, log := range payload.Logs {
+		if _, inline := log["resource"]; inline {
+			t.Errorf("el log lleva el resource en línea: %v", log)
+		}
+		ref := int(log["resource_ref"].(float64))
+		svc := payload.Resources[ref]["service_name"]
+		if msg := log["message"]; msg != svc.(string)+" log" {
+			t.Errorf("resource_ref %d apunta a %v, pero el log es %v", ref, svc, msg)
+		}
+	}
+
+	// Los índices son estables: el mismo lote produce el mismo payload
+	again := pushedLogs(t, exp, srv, logsWithResources(2, "svc-a", "svc-b"))
+	if string(again[1].Body) != string(reqs[0].Body) {
+		t.Errorf("el mismo lote produjo payloads distintos:\n%s\n%s", reqs[0].Body, again[1].Body)
+	}
+}
+
+func TestPerSignalHeaders(t *testing.T) {
+	srv := newCaptureServer(t)
+	cfg := testConfig(t, srv.URL)
+	cfg.Headers = map[string]string{"X-Api-Key": "global", "X-Team": "obs"}
+	cfg.TracesHeaders = map[string]string{"X-Api-Key": "traces-key"}
+	cfg.MetricsHeaders = map[string]string{"X-Api-Key": "metrics-key", "X-Extra": "m"}
+	traces, metrics, logs := newFactoryExporters(t, cfg)
+
+	ctx := context.Background()
+	if err := traces.ConsumeTraces(ctx, testTraces("svc", "op")); err != nil {
+		t.Fatal(err)
+	}
+	if err := metrics.ConsumeMetrics(ctx, gaugeMetrics("m")); err != nil {
+		t.Fatal(err)
+	}
+	if err := logs.ConsumeLogs(ctx, logsWithResources(1, "svc")); err != nil {
+		t.Fatal(err)
+	}
+
+	want := map[string]map[string]string{
+		"/traces":  {"X-Api-Key": "traces-key", "X-Team": "obs", "X-Extra": ""},
+		"/metrics": {"X-Api-Key": "metrics-key", "X-Team": "obs", "X-Extra": "m"},
+		"/logs":    {"X-Api-Key": "global", "X-Team": "obs", "X-Extra": ""},
+	}
+	reqs := srv.received()
+	if len(reqs) != len(want) {
+		t.Fatalf("peticiones = %d, se esperaba una por señal", len(reqs))
+	}
+	for _, req := range reqs {
+		for k, v := range want[req.Path] {
+			if got := req.Header.Get(k); got != v {
+				t.Errorf("%s: %s = %q, se esperaba %q", req.Path, k, got, v)
+			}
+		}
+	}
+	if cfg.Headers["X-Api-Key"] != "global" || len(cfg.Headers) != 2 {
+		t.Errorf("se modificaron las cabeceras globales: %v", cfg.Headers)
+	}
+}
+
+func TestPerSignalMethod(t *testing.T) {
+	srv := newCaptureServer(t)
+	cfg := testConfig(t, srv.URL)
+	cfg.Method = "patch"
+	cfg.MetricsMethod = http.MethodPut
+	traces, metrics, logs := newFactoryExporters(t, cfg)
+
+	ctx := context.Background()
+	if err := traces.ConsumeTraces(ctx, testTraces("svc", "op")); err != nil {
+		t.Fatal(err)
+	}
+	if err := metrics.ConsumeMetrics(ctx, gaugeMetrics("m")); err != nil {
+		t.Fatal(err)
+	}
+	if err := logs.ConsumeLogs(ctx, logsWithResources(1, "svc")); err != nil {
+		t.Fatal(err)
+	}
+
+	want := map[string]string{"/traces": http.MethodPatch, "/metrics": http.MethodPut, "/logs": http.MethodPatch}
+	reqs := srv.received()
+	if len(reqs) != len(want) {
+		t.Fatalf("peticiones = %d, se esperaba una por señal", len(reqs))
+	}
+	for _, req := range reqs {
+		if req.Method != want[req.Path] {
+			t.Errorf("%s: método = %s, se esperaba %s", req.Path, req.Method, want[req.Path])
+		}
+	}
+}
+
+func TestDefaultMethodIsPost(t *testing.T) {
+	srv := newCaptureServer(t)
+	cfg := testConfig(t, srv.URL)
+	cfg.Method = createDefaultConfig().(*Config).Method
+	if err := newTestExporter(t, cfg).pushMetrics(context.Background(), gaugeMetrics("m")); err != nil {
+		t.Fatal(err)
+	}
+	if got := srv.received()[0].Method; got != http.MethodPost {
+		t.Errorf("método = %s, se esperaba POST", got)
+	}
+}
+
+func TestBatchIDHeaderMatchesPayload(t *testing.T) {
+	srv := newCaptureServer(t)
+	cfg := testConfig(t, srv.URL)
+	cfg.BatchIDHeader = "X-Batch-Id"
+	exp := newTestExporter(t, cfg)
+
+	ctx := context.Background()
+	if err := exp.pushMetrics(ctx, gaugeMetrics("a", "b")); err != nil {
+		t.Fatal(err)
+	}
+	if err := exp.pushLogs(ctx, logsWithResources(2, "svc")); err != nil {
+		t.Fatal(err)
+	}
+	reqs := srv.received()
+	if len(reqs) != 2 {
+		t.Fatalf("peticiones = %d, se esperaban 2", len(reqs))
+	}
+
+	var metrics struct {
+		BatchID string `json:"batchId"`
+	}
+	decodeJSON(t, reqs[0].Body, &metrics)
+	if header := reqs[0].Header.Get("X-Batch-Id"); header == "" || header != metrics.BatchID {
+		t.Errorf("métricas: cabecera %q, payload %q", header, metrics.BatchID)
+	}
+
+	var logs []transformedLog
+	decodeJSON(t, reqs[1].Body, &logs)
+	header := reqs[1].Header.Get("X-Batch-Id")
+	for _, log := range logs {
+		if log.BatchID != header {
+			t.Errorf("logs: cabecera %q, payload %q", header, log.BatchID)
+		}
+	}
+	if header == metrics.BatchID {
+		t.Errorf("dos lotes comparten el ID %q", header)
+	}
+}
+
+func TestNonFiniteHandling(t *testing.T) {
+	values := []float64{math.NaN(), math.Inf(1), math.Inf(-1), 1.5}
+	tests := []struct {
+		mode    string
+		want    []interface{} // valores emitidos, en orden
+		wantErr bool
+	}{
+		{"null", []interface{}{nil, nil, nil, 1.5}, false},
+		{"skip", []interface{}{1.5}, false},
+		{"string", []interface{}{"NaN", "+Inf", "-Inf", 1.5}, false},
+		{"error", nil, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.mode, func(t *testing.T) {
+			md := pmetric.NewMetrics()
+			metric := md.ResourceMetrics().AppendEmpty().ScopeMetrics().AppendEmpty().Metrics().AppendEmpty()
+			metric.SetName("m")
+			dps := metric.SetEmptyGauge().DataPoints()
+			for _, v := range values {
+				dps.AppendEmpty().SetDoubleValue(v)
+			}
+
+			cfg := testConfig(t, "http://localhost")
+			cfg.NonFiniteHandling = tt.mode
+			exp := newTestExporter(t, cfg)
+			records, _, err := exp.transformMetrics(md)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatal("se esperaba un error por el NaN")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(records) != len(tt.want) {
+				t.Fatalf("registros = %d, se esperaban %d", len(records), len(tt.want))
+			}
+			for i, want := range tt.want {
+				if got := records[i].Values["m"]; got != want {
+					t.Errorf("valor %d = %v, se esperaba %v", i, got, want)
+				}
+			}
+			// El payload tiene que seguir siendo JSON válido
+			if _, err := exp.processMetrics(records, ""); err != nil {
+				t.Errorf("processMetrics: %v", err)
+			}
+		})
+	}
+}
+
+func TestNonFiniteHandlingInvalid(t *testing.T) {
+	cfg := testConfig(t, "http://localhost")
+	cfg.NonFiniteHandling = "zero"
+	validateError(t, cfg, "non_finite_handling")
+}
+
+func TestDataPointAttributesKey(t *testing.T) {
+	tests := []struct {
+		name string
+		key  *string // nil = valor por defecto
+		want string  // clave esperada de los atributos ("" = en properties)
+	}{
+		{"por defecto", nil, "attributes"},
+		{"labels", strPtr("labels"), "labels"},
+		{"vacío", strPtr(""), ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			md := gaugeMetrics("requests")
+			md.ResourceMetrics().At(0).ScopeMetrics().At(0).Metrics().At(0).Gauge().DataPoints().At(0).Attributes().PutStr("http.method", "GET")
+
+			cfg := testConfig(t, "http://localhost")
+			if tt.key != nil {
+				cfg.DataPointAttributesKey = *tt.key
+			}
+			exp := newTestExporter(t, cfg)
+			records, _, err := exp.transformMetrics(md)
+			if err != nil {
+				t.Fatal(err)
+			}
+			data, err := json.Marshal(records[0])
+			if err != nil {
+				t.Fatal(err)
+			}
+			var got map[string]interface{}
+			decodeJSON(t, data, &got)
+			props := got["properties"].(map[string]interface{})
+			if props["service_name"] != "svc" || props["name"] != "requests" {
+				t.Errorf("properties = %v, se esperaban el resource y el nombre", props)
+			}
+			if tt.want == "" {
+				if props["http_method"] != "GET" {
+					t.Errorf("properties = %v, se esperaban los atributos del punto", props)
+				}
+				return
+			}
+			if _, ok := props["http_method"]; ok {
+				t.Errorf("los atributos del punto siguen en properties: %v", props)
+			}
+			attrs, ok := got[tt.want].(map[string]interface{})
+			if !ok || attrs["http_method"] != "GET" {
+				t.Errorf("%s = %v, se esperaban los atributos del punto", tt.want, got[tt.want])
+			}
+			if strings.Count(string(data), `"`+tt.want+`"`) != 1 {
+				t.Errorf("la clave %s aparece duplicada: %s", tt.want, data)
+			}
+		})
+	}
+}
+
+func strPtr(s string) *string { return &s }
+
+// methods devuelve el método de cada petición recibida
+func methods(reqs []capturedRequest) []string {
+	var out []string
+	for _, r := range reqs {
+		out = append(out, r.Method)
+	}
+	return out
+}
+
+func TestPreflightGating(t *testing.T) {
+	srv := newCaptureServer(t)
+	srv.respond(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodOptions {
+			w.WriteHeader(http.StatusForbidden)
+		}
+	})
+	cfg := testConfig(t, srv.URL)
+	cfg.Preflight = true
+	exp := newTestExporter(t, cfg)
+
+	url := srv.URL + "/logs"
+	if err := exp.postJSON(context.Background(), url, []byte(`[]`)); err == nil {
+		t.Fatal("se esperaba un error con el preflight rechazado")
+	}
+	if got := methods(srv.received()); len(got) != 1 || got[0] != http.MethodOptions {
+		t.Fatalf("peticiones = %v, el POST no debía enviarse", got)
+	}
+	if h := srv.received()[0].Header.Get("Access-Control-Request-Method"); h != http.MethodPost {
+		t.Errorf("Access-Control-Request-Method = %q", h)
+	}
+}
+
+func TestPreflightTTL(t *testing.T) {
+	srv := newCaptureServer(t)
+	cfg := testConfig(t, srv.URL)
+	cfg.Preflight = true
+	cfg.PreflightTTL = time.Hour
+	exp := newTestExporter(t, cfg)
+
+	ctx := context.Background()
+	url := srv.URL + "/logs"
+	for i := 0; i < 2; i++ {
+		if err := exp.postJSON(ctx, url, []byte(`[]`)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	want := []string{http.MethodOptions, http.MethodPost, http.MethodPost}
+	if got := methods(srv.received()); strings.Join(got, ",") != strings.Join(want, ",") {
+		t.Fatalf("peticiones = %v, se esperaba %v (preflight cacheado)", got, want)
+	}
+
+	// Caducado el TTL se repite el preflight
+	exp.preflightMu.Lock()
+	exp.preflightOK[url] = time.Now().Add(-time.Second)
+	exp.preflightMu.Unlock()
+	if err := exp.postJSON(ctx, url, []byte(`[]`)); err != nil {
+		t.Fatal(err)
+	}
+	want = append(want, http.MethodOptions, http.MethodPost)
+	if got := methods(srv.received()); strings.Join(got, ",") != strings.Join(want, ",") {
+		t.Errorf("peticiones = %v, se esperaba %v", got, want)
+	}
+}
+
+func TestDecimalPlaces(t *testing.T) {
+	tests := []struct {
+		places int
+		in     float64
+		want   float64
+	}{
+		{-1, 3.14159265, 3.14159265},
+		{0, 2.5, 3},
+		{0, -2.5, -3},
+		{2, 3.14159265, 3.14},
+		{2, 0.125, 0.13},
+		{3, 1.0005, 1.001},
+		{6, 1e-7, 0},
+	}
+	for _, tt := range tests {
+		cfg := testConfig(t, "http://localhost")
+		cfg.DecimalPlaces = tt.places
+		exp := newTestExporter(t, cfg)
+
+		dp := pmetric.NewNumberDataPoint()
+		dp.SetDoubleValue(tt.in)
+		got, ok, err := exp.numberValue(dp)
+		if err != nil || !ok || got != tt.want {
+			t.Errorf("decimal_places %d, %v -> %v (ok=%v, err=%v); se esperaba %v", tt.places, tt.in, got, ok, err, tt.want)
+		}
+	}
+
+	// Los enteros no se tocan
+	cfg := testConfig(t, "http://localhost")
+	cfg.DecimalPlaces = 0
+	dp := pmetric.NewNumberDataPoint()
+	dp.SetIntValue(12345)
+	if got, _, _ := newTestExporter(t, cfg).numberValue(dp); got != int64(12345) {
+		t.Errorf("entero = %v (%T), se esperaba 12345 sin cambios", got, got)
+	}
+}
+
+// testTraces devuelve un lote con un resource de service.name svc y un span
+// por nombre, todos de la traza 0102...
+func testTraces(svc string, names ...string) ptrace.Traces {
+	td := ptrace.NewTraces()
+	rs := td.ResourceSpans().AppendEmpty()
+	rs.Resource().Attributes().PutStr("service.name", svc)
+	ss := rs.ScopeSpans().AppendEmpty()
+	for i, name := range names {
+		sp := ss.Spans().AppendEmpty()
+		sp.SetName(name)
+		sp.SetTraceID(pcommon.TraceID{1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12, 13, 14, 15, 16})
+		sp.SetSpanID(pcommon.SpanID{1, 2, 3, 4, 5, 6, 7, byte(i + 1)})
+		sp.SetStartTimestamp(1_000_000_000)
+		sp.SetEndTimestamp(1_500_000_000)
+	}
+	return td
+}
+
+func TestKeyDictionary(t *testing.T) {
+	srv := newCaptureServer(t)
+	cfg := testConfig(t, srv.URL)
+	cfg.KeyDictionary = map[string]string{"http.method": "m", "service.name": "s", "mrid": "r", "ns": "n"}
+	exp := newTestExporter(t, cfg)
+
+	ld := logsWithResources(1, "svc")
+	attrs := ld.ResourceLogs().At(0).ScopeLogs().At(0).LogRecords().At(0).Attributes()
+	attrs.PutStr("http.method", "GET")
+	attrs.PutStr("mrid", "mr-1")
+	attrs.PutStr("ns", "ns-1")
+	attrs.PutStr("user.id", "u1")
+	reqs := pushedLogs(t, exp, srv, ld)
+
+	var logs []transformedLog
+	decodeJSON(t, reqs[0].Body, &logs)
+	props := logs[0].Properties
+	want := map[string]interface{}{"m": "GET", "s": "svc", "user_id": "u1"}
+	if len(props) != len(want) {
+		t.Errorf("properties = %v, se esperaba %v", props, want)
+	}
+	for k, v := range want {
+		if props[k] != v {
+			t.Errorf("properties[%s] = %v, se esperaba %v", k, props[k], v)
+		}
+	}
+	if logs[0].MrId != "mr-1" {
+		t.Errorf("mrid = %q, se esperaba el del atributo", logs[0].MrId)
+	}
+
+	// El diccionario inverso viaja solo en el primer POST a cada URL
+	var inverse map[string]string
+	decodeJSON(t, []byte(reqs[0].Header.Get("X-Key-Dictionary")), &inverse)
+	for k, code := range cfg.KeyDictionary {
+		if inverse[code] != k {
+			t.Errorf("diccionario inverso[%s] = %q, se esperaba %q", code, inverse[code], k)
+		}
+	}
+	reqs = pushedLogs(t, exp, srv, logsWithResources(1, "svc"))
+	if h := reqs[1].Header.Get("X-Key-Dictionary"); h != "" {
+		t.Errorf("el segundo POST repite el diccionario: %s", h)
+	}
+
+	// En los spans los atributos internos también se quitan por su código
+	td := testTraces("svc", "op")
+	td.ResourceSpans().At(0).ScopeSpans().At(0).Spans().At(0).Attributes().PutStr("mrid", "mr-2")
+	td.ResourceSpans().At(0).ScopeSpans().At(0).Spans().At(0).Attributes().PutStr("http.method", "POST")
+	out, _, err := exp.transformTraces(td, transformCfg{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var spans []outSpan
+	decodeJSON(t, out, &spans)
+	if _, ok := spans[0].Properties["r"]; ok || spans[0].Properties["m"] != "POST" || spans[0].MRID != "mr-2" {
+		t.Errorf("span: mrId %q, properties %v", spans[0].MRID, spans[0].Properties)
+	}
+}
+
+func TestRequireLogBody(t *testing.T) {
+	ld := logsWithResources(1, "svc")
+	records := ld.ResourceLogs().At(0).ScopeLogs().At(0).LogRecords()
+	records.AppendEmpty().SetTimestamp(2_000_000_000) // sin body
+	records.AppendEmpty().Body().SetStr("")
+
+	for _, require := range []bool{false, true} {
+		srv := newCaptureServer(t)
+		cfg := testConfig(t, srv.URL)
+		cfg.RequireLogBody = require
+		reqs := pushedLogs(t, newTestExporter(t, cfg), srv, ld)
+
+		var logs []transformedLog
+		decodeJSON(t, reqs[0].Body, &logs)
+		want := 3
+		if require {
+			// Solo se descarta el body ausente; un string vacío es un body
+			want = 2
+		}
+		if len(logs) != want {
+			t.Errorf("require_log_body=%v: %d logs, se esperaban %d", require, len(logs), want)
+		}
+		for _, log := range logs {
+			if require && log.Message != "svc log" && log.Message != "" {
+				t.Errorf("require_log_body: se envió %v", log.Message)
+			}
+		}
+	}
+}
+
+func TestResourceAsString(t *testing.T) {
+	cfg := testConfig(t, "http://localhost")
+	cfg.ResourceAsString = true
+	exp := newTestExporter(t, cfg)
+
+	md := gaugeMetrics("requests")
+	md.ResourceMetrics().At(0).Resource().Attributes().PutStr("host.name", "h1")
+	records, _, err := exp.transformMetrics(md)
+	if err != nil {
+		t.Fatal(err)
+	}
+	body, err := exp.processMetrics(records, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var payload struct {
+		Metrics []map[string]interface{} `json:"metrics"`
+	}
+	decodeJSON(t, body, &payload)
+	resource, ok := payload.Metrics[0]["resource"].(string)
+	if !ok {
+		t.Fatalf("resource = %v (%T), se esperaba un string", payload.Metrics[0]["resource"], payload.Metrics[0]["resource"])
+	}
+	var attrs map[string]interface{}
+	decodeJSON(t, []byte(resource), &attrs)
+	if attrs["service_name"] != "svc" || attrs["host_name"] != "h1" {
+		t.Errorf("resource = %s, se esperaban los atributos saneados", resource)
+	}
+	props := payload.Metrics[0]["properties"].(map[string]interface{})
+	if _, ok := props["service_name"]; ok {
+		t.Errorf("el resource sigue en properties: %v", props)
+	}
+
+	// Spans y logs llevan el mismo string
+	out, _, err := exp.transformTraces(testTraces("svc", "op"), transformCfg{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var spans []outSpan
+	decodeJSON(t, out, &spans)
+	if spans[0].Resource != `{"service_name":"svc"}` {
+		t.Errorf("span resource = %q", spans[0].Resource)
+	}
+	out, _, err = exp.transformLogs(logsWithResources(1, "svc"), transformCfg{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var logs []map[string]interface{}
+	decodeJSON(t, out, &logs)
+	if logs[0]["resource"] != `{"service_name":"svc"}` {
+		t.Errorf("log resource = %v", logs[0]["resource"])
+	}
+
+	exp.resourceAsString = false
+	records, _, _ = exp.transformMetrics(md)
+	if records[0].Resource != "" || records[0].Properties["service_name"] != "svc" {
+		t.Errorf("desactivado: resource %q, properties %v", records[0].Resource, records[0].Properties)
+	}
+}
+
+// fastRetries activa reintentos con esperas de milisegundos
+func fastRetries(cfg *Config) {
+	cfg.RetrySettings.Enabled = true
+	cfg.RetrySettings.InitialInterval = time.Millisecond
+	cfg.RetrySettings.MaxInterval = 5 * time.Millisecond
+	cfg.RetrySettings.MaxElapsedTime = 5 * time.Second
+	cfg.RetrySettings.RandomizationFactor = 0
+}
+
+// failFirst responde 500 a las n primeras peticiones de path y 200 al resto
+func failFirst(n int, path string) http.HandlerFunc {
+	var mu sync.Mutex
+	seen := 0
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != path {
+			return
+		}
+		mu.Lock()
+		defer mu.Unlock()
+		if seen++; seen <= n {
+			w.WriteHeader(http.StatusInternalServerError)
+		}
+	}
+}
+
+// countPath cuenta las peticiones recibidas en path
+func countPath(reqs []capturedRequest, path string) int {
+	n := 0
+	for _, r := range reqs {
+		if r.Path == path {
+			n++
+		}
+	}
+	return n
+}
+
+func TestRetryDisabledPerSignal(t *testing.T) {
+	srv := newCaptureServer(t)
+	cfg := testConfig(t, srv.URL)
+	fastRetries(cfg)
+	cfg.LogsRetryEnabled = false
+	_, metrics, logs := newFactoryExporters(t, cfg)
+	ctx := context.Background()
+
+	srv.respond(failFirst(2, "/logs"))
+	if err := logs.ConsumeLogs(ctx, logsWithResources(1, "svc")); err == nil {
+		t.Error("logs sin reintentos: se esperaba el error del primer fallo")
+	}
+	if n := countPath(srv.received(), "/logs"); n != 1 {
+		t.Errorf("logs sin reintentos: %d peticiones, se esperaba 1", n)
+	}
+
+	srv.respond(failFirst(2, "/metrics"))
+	if err := metrics.ConsumeMetrics(ctx, gaugeMetrics("requests")); err != nil {
+		t.Errorf("metrics con reintentos: %v", err)
+	}
+	if n := countPath(srv.received(), "/metrics"); n != 3 {
+		t.Errorf("metrics con reintentos: %d peticiones, se esperaban 3", n)
+	}
+}
+
+func TestDeterministicAttributeOrder(t *testing.T) {
+	exp := newTestExporter(t, testConfig(t, "http://localhost"))
+	build := func() pmetric.Metrics {
+		md := gaugeMetrics("requests")
+		attrs := md.ResourceMetrics().At(0).ScopeMetrics().At(0).Metrics().At(0).Gauge().DataPoints().At(0).Attributes()
+		for _, k := range []string{"zeta", "alpha", "http.method", "http_method", "mid.key", "b"} {
+			attrs.PutStr(k, k)
+		}
+		return md
+	}
+
+	var first []byte
+	for i := 0; i < 20; i++ {
+		records, _, err := exp.transformMetrics(build())
+		if err != nil {
+			t.Fatal(err)
+		}
+		body, err := exp.processMetrics(records, "")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if first == nil {
+			first = body
+			continue
+		}
+		if string(body) != string(first) {
+			t.Fatalf("ejecución %d distinta:\n%s\n%s", i, first, body)
+		}
+	}
+
+	// Si dos claves colisionan al sanearse gana siempre la última en orden
+	var payload struct {
+		Metrics []struct {
+			Attributes map[string]string `json:"attributes"`
+		} `json:"metrics"`
+	}
+	decodeJSON(t, first, &payload)
+	if got := payload.Metrics[0].Attributes["http_method"]; got != "http_method" {
+		t.Errorf("http_method = %q, se esperaba el valor de la clave http_method", got)
+	}
+	if !strings.Contains(string(first), `"alpha":"alpha","b":"b","http_method"`) {
+		t.Errorf("claves no ordenadas: %s", first)
+	}
+}
+
+func TestIncludeHostname(t *testing.T) {
+	host, err := os.Hostname()
+	if err != nil {
+		t.Skip(err)
+	}
+	srv := newCaptureServer(t)
+	cfg := testConfig(t, srv.URL)
+	cfg.IncludeHostname = true
+	exp := newTestExporter(t, cfg)
+	if exp.collectorHost != host {
+		t.Fatalf("collectorHost = %q, se esperaba %q", exp.collectorHost, host)
+	}
+
+	// Se resuelve al crear el exporter: los envíos usan el valor guardado
+	exp.collectorHost = "cached-host"
+	ctx := context.Background()
+	if err := exp.pushMetrics(ctx, gaugeMetrics("requests")); err != nil {
+		t.Fatal(err)
+	}
+	if err := exp.pushTraces(ctx, testTraces("svc", "op")); err != nil {
+		t.Fatal(err)
+	}
+	if err := exp.pushLogs(ctx, logsWithResources(1, "svc")); err != nil {
+		t.Fatal(err)
+	}
+	for _, req := range srv.received() {
+		if n := strings.Count(string(req.Body), `"collector_host":"cached-host"`); n != 1 {
+			t.Errorf("%s: collector_host aparece %d veces: %s", req.Path, n, req.Body)
+		}
+	}
+
+	exp = newTestExporter(t, testConfig(t, srv.URL))
+	if body, _ := exp.processMetrics(nil, ""); strings.Contains(string(body), "collector_host") {
+		t.Errorf("sin include_hostname: %s", body)
+	}
+}
+
+func TestComposedURLs(t *testing.T) {
+	tests := []struct {
+		name                   string
+		prefix                 string
+		traces, metrics, logs  string // sufijos ("" = por defecto)
+		wantTraces, wantMetric string
+		wantLogs               string
+	}{
+		{
+			name:       "por defecto",
+			wantTraces: "https://rho.r1/v1/ns/ns1/mrs/mr1/spans",
+			wantMetric: "https://mu.r1/v0/ns/ns1/metric-sets/set1:addMeasurements",
+			wantLogs:   "https://omega.r1/v1/ns/ns1/logs",
+		},
+		{
+			name:       "prefijo",
+			prefix:     "/api/v2",
+			wantTraces: "https://rho.r1/api/v2/ns/ns1/mrs/mr1/spans",
+			wantMetric: "https://mu.r1/api/v2/ns/ns1/metric-sets/set1:addMeasurements",
+			wantLogs:   "https://omega.r1/api/v2/ns/ns1/logs",
+		},
+		{
+			name:       "sufijos",
+			prefix:     "/api/v2",
+			traces:     "/traces",
+			metrics:    "/{metricsets}/metrics",
+			logs:       "/logs/{service}",
+			wantTraces: "https://rho.r1/api/v2/traces",
+			wantMetric: "https://mu.r1/api/v2/set1/metrics",
+			wantLogs:   "https://omega.r1/api/v2/logs/svc",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := testConfig(t, "")
+			cfg.TracesURLTemplate, cfg.MetricsURLTemplate, cfg.LogsURLTemplate = "", "", ""
+			cfg.Region, cfg.NS, cfg.MrId, cfg.MetricSets = "r1", "ns1", "mr1", "set1"
+			cfg.PathPrefix = tt.prefix
+			cfg.TracesPathSuffix, cfg.MetricsPathSuffix, cfg.LogsPathSuffix = tt.traces, tt.metrics, tt.logs
+			exp := newTestExporter(t, cfg)
+
+			_, urls, err := exp.transformTraces(testTraces("svc", "op"), transformCfg{})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if urls[0] != tt.wantTraces {
+				t.Errorf("traces = %s, se esperaba %s", urls[0], tt.wantTraces)
+			}
+			res := pcommon.NewMap()
+			res.PutStr("service.name", "svc")
+			if got := exp.metricsURL(res); got != tt.wantMetric {
+				t.Errorf("metrics = %s, se esperaba %s", got, tt.wantMetric)
+			}
+			_, urls, err = exp.transformLogs(logsWithResources(1, "svc"), transformCfg{})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if urls[0] != tt.wantLogs {
+				t.Errorf("logs = %s, se esperaba %s", urls[0], tt.wantLogs)
+			}
+		})
+	}
+}
+
+// transformLogRecords transforma ld y devuelve los registros como mapas JSON
+func transformLogRecords(t *testing.T, exp *monitoringExporter, ld plog.Logs) []map[string]interface{} {
+	t.Helper()
+	out, _, err := exp.transformLogs(ld, transformCfg{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var logs []map[string]interface{}
+	decodeJSON(t, out, &logs)
+	return logs
+}
+
+func TestHoistLogSource(t *testing.T) {
+	ld := logsWithResources(3, "svc")
+	records := ld.ResourceLogs().At(0).ScopeLogs().At(0).LogRecords()
+	// Convenciones actuales
+	records.At(0).Attributes().PutStr("code.file.path", "main.go")
+	records.At(0).Attributes().PutInt("code.line.number", 42)
+	records.At(0).Attributes().PutStr("code.function.name", "main.run")
+	records.At(0).Attributes().PutStr("user.id", "u1")
+	// Convenciones anteriores, sin función
+	records.At(1).Attributes().PutStr("code.filepath", "old.go")
+	records.At(1).Attributes().PutInt("code.lineno", 7)
+
+	cfg := testConfig(t, "http://localhost")
+	cfg.HoistLogSource = true
+	logs := transformLogRecords(t, newTestExporter(t, cfg), ld)
+
+	want := []map[string]interface{}{
+		{"file": "main.go", "line": float64(42), "function": "main.run"},
+		{"file": "old.go", "line": float64(7)},
+		nil,
+	}
+	for i, w := range want {
+		source, _ := logs[i]["source"].(map[string]interface{})
+		if len(source) != len(w) {
+			t.Errorf("log %d: source = %v, se esperaba %v", i, source, w)
+			continue
+		}
+		for k, v := range w {
+			if source[k] != v {
+				t.Errorf("log %d: source.%s = %v, se esperaba %v", i, k, source[k], v)
+			}
+		}
+		props, _ := logs[i]["properties"].(map[string]interface{})
+		for k := range props {
+			if strings.HasPrefix(k, "code_") {
+				t.Errorf("log %d: %s sigue en properties", i, k)
+			}
+		}
+	}
+	if props := logs[0]["properties"].(map[string]interface{}); props["user_id"] != "u1" {
+		t.Errorf("properties = %v, se esperaba el resto de atributos", props)
+	}
+}
+
+func TestSpanTraceState(t *testing.T) {
+	td := testTraces("svc", "with-state", "without-state")
+	td.ResourceSpans().At(0).ScopeSpans().At(0).Spans().At(0).TraceState().FromRaw("rojo=00f067aa0ba902b7,congo=t61rcWkgMzE")
+
+	out, _, err := newTestExporter(t, testConfig(t, "http://localhost")).transformTraces(td, transformCfg{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var spans []map[string]interface{}
+	decodeJSON(t, out, &spans)
+	if got := spans[0]["trace_state"]; got != "rojo=00f067aa0ba902b7,congo=t61rcWkgMzE" {
+		t.Errorf("trace_state = %v", got)
+	}
+	if _, ok := spans[1]["trace_state"]; ok {
+		t.Errorf("un tracestate vacío debe omitirse: %v", spans[1])
+	}
+}
+
+func TestSortLogsByTimestamp(t *testing.T) {
+	build := func() plog.Logs {
+		ld := plog.NewLogs()
+		records := ld.ResourceLogs().AppendEmpty().ScopeLogs().AppendEmpty().LogRecords()
+		for i, ts := range []pcommon.Timestamp{30, 10, 20, 10} {
+			lr := records.AppendEmpty()
+			lr.SetTimestamp(ts * 1_000_000_000)
+			lr.Body().SetStr(fmt.Sprintf("log-%d", i))
+		}
+		return ld
+	}
+	tests := []struct {
+		sort bool
+		want []string
+	}{
+		// Con timestamps iguales se mantiene el orden de llegada
+		{true, []string{"log-1", "log-3", "log-2", "log-0"}},
+		{false, []string{"log-0", "log-1", "log-2", "log-3"}},
+	}
+	for _, tt := range tests {
+		srv := newCaptureServer(t)
+		cfg := testConfig(t, srv.URL)
+		cfg.SortLogsByTimestamp = tt.sort
+		reqs := pushedLogs(t, newTestExporter(t, cfg), srv, build())
+
+		var logs []transformedLog
+		decodeJSON(t, reqs[0].Body, &logs)
+		var got []string
+		for _, log := range logs {
+			got = append(got, log.Message.(string))
+		}
+		if strings.Join(got, ",") != strings.Join(tt.want, ",") {
+			t.Errorf("sort_logs_by_timestamp=%v: orden %v, se esperaba %v", tt.sort, got, tt.want)
+		}
+	}
+}
+
+func TestStatusHeaderOn200(t *testing.T) {
+	tests := []struct {
+		value     string
+		wantErr   bool
+		permanent bool
+	}{
+		{"", false, false},
+		{"0", false, false},
+		{"14", true, false}, // UNAVAILABLE, reintentable por defecto
+		{"3", true, true},   // INVALID_ARGUMENT
+		{"abc", true, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			srv := newCaptureServer(t)
+			srv.respond(func(w http.ResponseWriter, r *http.Request) {
+				if tt.value != "" {
+					w.Header().Set("Grpc-Status", tt.value)
+				}
+			})
+			cfg := testConfig(t, srv.URL)
+			cfg.StatusHeader = "Grpc-Status"
+			err := newTestExporter(t, cfg).postJSON(context.Background(), srv.URL+"/logs", []byte(`[]`))
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("err = %v, se esperaba error: %v", err, tt.wantErr)
+			}
+			if err != nil && consumererror.IsPermanent(err) != tt.permanent {
+				t.Errorf("permanente = %v, se esperaba %v (%v)", consumererror.IsPermanent(err), tt.permanent, err)
+			}
+		})
+	}
+}
+
+func TestMaxLogBodyBytes(t *testing.T) {
+	ld := logsWithResources(0, "svc")
+	records := ld.ResourceLogs().At(0).ScopeLogs().At(0).LogRecords()
+	records.AppendEmpty().Body().SetStr(strings.Repeat("a", 100))
+	records.AppendEmpty().Body().SetStr("corto")
+	// "é" ocupa dos bytes: el corte en el byte 10 cae en medio del carácter
+	records.AppendEmpty().Body().SetStr("aaaaaaaaaé" + strings.Repeat("b", 20))
+	records.AppendEmpty().Body().SetEmptyMap().PutStr("msg", strings.Repeat("c", 50))
+
+	cfg := testConfig(t, "http://localhost")
+	cfg.MaxLogBodyBytes = 10
+	logs := transformLogRecords(t, newTestExporter(t, cfg), ld)
+	tests := []struct {
+		message   string
+		truncated bool
+	}{
+		{"aaaaaaaaaa...<truncated>", true},
+		{"corto", false},
+		{"aaaaaaaaa...<truncated>", true},
+		{`{"msg":"cc...<truncated>`, true}, // el body estructurado se mide en JSON
+	}
+	for i, tt := range tests {
+		if logs[i]["message"] != tt.message {
+			t.Errorf("log %d: message = %q, se esperaba %q", i, logs[i]["message"], tt.message)
+		}
+		if truncated, _ := logs[i]["body_truncated"].(bool); truncated != tt.truncated {
+			t.Errorf("log %d: body_truncated = %v, se esperaba %v", i, truncated, tt.truncated)
+		}
+	}
+}
+
+func TestIncludeSignalType(t *testing.T) {
+	for _, include := range []bool{true, false} {
+		srv := newCaptureServer(t)
+		cfg := testConfig(t, srv.URL)
+		cfg.IncludeSignalType = include
+		exp := newTestExporter(t, cfg)
+
+		ctx := context.Background()
+		if err := exp.pushTraces(ctx, testTraces("svc", "a")); err != nil {
+			t.Fatal(err)
+		}
+		if err := exp.pushMetrics(ctx, gaugeMetrics("m")); err != nil {
+			t.Fatal(err)
+		}
+		if err := exp.pushLogs(ctx, logsWithResources(1, "svc")); err != nil {
+			t.Fatal(err)
+		}
+		reqs := srv.received()
+		if len(reqs) != 3 {
+			t.Fatalf("peticiones = %d, se esperaban 3", len(reqs))
+		}
+
+		var spans, logs []map[string]interface{}
+		var metrics map[string]interface{}
+		decodeJSON(t, reqs[0].Body, &spans)
+		decodeJSON(t, reqs[1].Body, &metrics)
+		decodeJSON(t, reqs[2].Body, &logs)
+		got := map[string]interface{}{
+			"trace":  spans[0]["signal"],
+			"metric": metrics["signal"],
+			"log":    logs[0]["signal"],
+		}
+		for signal, value := range got {
+			if include && value != signal {
+				t.Errorf("%s: signal = %v", signal, value)
+			}
+			if !include && value != nil {
+				t.Errorf("%s: signal = %v sin include_signal_type", signal, value)
+			}
+		}
+	}
+}
+
+func TestRequestTimeoutScalesWithSize(t *testing.T) {
+	cfg := testConfig(t, "http://localhost")
+	cfg.RequestBaseTimeout = time.Second
+	cfg.RequestBytesPerSecond = 1 << 20
+	exp := newTestExporter(t, cfg)
+	tests := []struct {
+		size int
+		want time.Duration
+	}{
+		{0, time.Second},
+		{1 << 19, 1500 * time.Millisecond},
+		{10 << 20, 11 * time.Second},
+	}
+	for _, tt := range tests {
+		if got := exp.requestTimeout(tt.size); got != tt.want {
+			t.Errorf("requestTimeout(%d) = %s, se esperaba %s", tt.size, got, tt.want)
+		}
+	}
+}
+
+func TestRequestTimeoutLargeBodyGetsLongerDeadline(t *testing.T) {
+	srv := newCaptureServer(t)
+	srv.respond(func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(200 * time.Millisecond)
+	})
+	cfg := testConfig(t, srv.URL)
+	cfg.RequestBaseTimeout = 50 * time.Millisecond
+	cfg.RequestBytesPerSecond = 1000
+	exp := newTestExporter(t, cfg)
+
+	// 10 bytes: 60ms, menos de lo que tarda el servidor
+	err := exp.sendPayload(context.Background(), srv.URL+"/metrics", []byte(strings.Repeat("a", 10)), nil)
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("body pequeño: err = %v, se esperaba el plazo agotado", err)
+	}
+	// 1000 bytes: 1,05s, de sobra para la misma respuesta lenta
+	if err := exp.sendPayload(context.Background(), srv.URL+"/metrics", []byte(strings.Repeat("a", 1000)), nil); err != nil {
+		t.Errorf("body grande: %v", err)
+	}
+}
+
+func TestParseJSONLogBodies(t *testing.T) {
+	bodies := []string{
+		`{"user":"ana","status":200}`,
+		` [1, "dos"] `,
+		`{"user":`,         // JSON inválido: se mantiene el texto
+		`"solo un string"`, // JSON válido pero no objeto ni array
+		`texto plano`,
+	}
+	ld := logsWithResources(0, "svc")
+	records := ld.ResourceLogs().At(0).ScopeLogs().At(0).LogRecords()
+	for _, body := range bodies {
+		records.AppendEmpty().Body().SetStr(body)
+	}
+
+	for _, parse := range []bool{true, false} {
+		cfg := testConfig(t, "http://localhost")
+		cfg.ParseJSONLogBodies = parse
+		logs := transformLogRecords(t, newTestExporter(t, cfg), ld)
+		for i, body := range bodies {
+			message := logs[i]["message"]
+			if !parse || i >= 2 {
+				if message != body {
+					t.Errorf("parse=%v, body %q: message = %v, se esperaba el texto", parse, body, message)
+				}
+				continue
+			}
+			switch v := message.(type) {
+			case map[string]interface{}:
+				if v["user"] != "ana" || v["status"] != 200.0 {
+					t.Errorf("objeto parseado = %v", v)
+				}
+			case []interface{}:
+				if len(v) != 2 || v[1] != "dos" {
+					t.Errorf("array parseado = %v", v)
+				}
+			default:
+				t.Errorf("body %q: message = %#v, se esperaba el JSON parseado", body, message)
+			}
+		}
+	}
+}
+
+func TestTooEarlyIsRetryable(t *testing.T) {
+	srv := newCaptureServer(t)
+	srv.respond(func(w http.ResponseWriter, r *http.Request) { w.WriteHeader(http.StatusTooEarly) })
+	cfg := testConfig(t, srv.URL)
+	cfg.TooEarlyRetryDelay = 50 * time.Millisecond
+	err := newTestExporter(t, cfg).pushMetrics(context.Background(), gaugeMetrics("m"))
+	if err == nil || consumererror.IsPermanent(err) {
+		t.Fatalf("err = %v, se esperaba un error reintentable", err)
+	}
+
+	// Con reintentos, el 425 se reintenta tras la espera fija y no con el backoff
+	srv = newCaptureServer(t)
+	var mu sync.Mutex
+	seen := 0
+	srv.respond(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		if seen++; seen == 1 {
+			w.WriteHeader(http.StatusTooEarly)
+		}
+	})
+	cfg = testConfig(t, srv.URL)
+	cfg.TooEarlyRetryDelay = 50 * time.Millisecond
+	fastRetries(cfg)
+	_, metrics, _ := newFactoryExporters(t, cfg)
+	start := time.Now()
+	if err := metrics.ConsumeMetrics(context.Background(), gaugeMetrics("m")); err != nil {
+		t.Fatal(err)
+	}
+	if elapsed := time.Since(start); elapsed < cfg.TooEarlyRetryDelay {
+		t.Errorf("el reintento llegó a los %s, antes de too_early_retry_delay", elapsed)
+	}
+	if got := len(srv.received()); got != 2 {
+		t.Errorf("peticiones = %d, se esperaban 2", got)
+	}
+}
+
+func TestLogSeverityRoutes(t *testing.T) {
+	ld := logsWithResources(0, "svc")
+	records := ld.ResourceLogs().At(0).ScopeLogs().At(0).LogRecords()
+	for _, severity := range []plog.SeverityNumber{
+		plog.SeverityNumberInfo, plog.SeverityNumberError, plog.SeverityNumberDebug,
+		plog.SeverityNumberFatal, plog.SeverityNumberWarn, plog.SeverityNumberUnspecified,
+	} {
+		lr := records.AppendEmpty()
+		lr.SetSeverityNumber(severity)
+		lr.Body().SetStr(severity.String())
+	}
+
+	srv := newCaptureServer(t)
+	cfg := testConfig(t, srv.URL)
+	cfg.LogSeverityRoutes = []LogSeverityRoute{
+		{MinSeverity: int(plog.SeverityNumberError), Endpoint: srv.URL + "/incidents"},
+		{MinSeverity: int(plog.SeverityNumberTrace), MaxSeverity: int(plog.SeverityNumberInfo4), Endpoint: srv.URL + "/bulk"},
+	}
+	reqs := pushedLogs(t, newTestExporter(t, cfg), srv, ld)
+
+	got := map[string][]string{}
+	for _, req := range reqs {
+		var logs []transformedLog
+		decodeJSON(t, req.Body, &logs)
+		for _, log := range logs {
+			got[req.Path] = append(got[req.Path], log.Message.(string))
+		}
+	}
+	// Los que no entran en ninguna ruta (WARN y sin severidad) van a logs_url_template
+	want := map[string][]string{
+		"/incidents": {"Error", "Fatal"},
+		"/bulk":      {"Info", "Debug"},
+		"/logs":      {"Warn", "Unspecified"},
+	}
+	if len(got) != len(want) {
+		t.Fatalf("rutas = %v, se esperaba %v", got, want)
+	}
+	for path, messages := range want {
+		if strings.Join(got[path], ",") != strings.Join(messages, ",") {
+			t.Errorf("%s recibió %v, se esperaba %v", path, got[path], messages)
+		}
+	}
+}
+
+func TestSeverityNumberOnly(t *testing.T) {
+	ld := logsWithResources(0, "svc")
+	records := ld.ResourceLogs().At(0).ScopeLogs().At(0).LogRecords()
+	records.AppendEmpty().SetSeverityNumber(plog.SeverityNumberWarn2)
+	text := records.AppendEmpty()
+	text.SetSeverityNumber(plog.SeverityNumberError)
+	text.SetSeverityText("crit")
+	records.AppendEmpty()
+
+	logs := transformLogRecords(t, newTestExporter(t, testConfig(t, "http://localhost")), ld)
+	want := []struct {
+		level  string
+		number float64
+	}{{"WARN", 14}, {"crit", 17}, {"", 0}}
+	for i, w := range want {
+		if logs[i]["level"] != w.level || logs[i]["severity_number"] != w.number {
+			t.Errorf("log %d: level = %v, severity_number = %v; se esperaba %q y %v",
+				i, logs[i]["level"], logs[i]["severity_number"], w.level, w.number)
+		}
+	}
+}
+
+func TestLogTraceContextHex(t *testing.T) {
+	ld := logsWithResources(2, "svc")
+	lr := ld.ResourceLogs().At(0).ScopeLogs().At(0).LogRecords().At(0)
+	lr.SetTraceID(pcommon.TraceID{0x4b, 0xf9, 0x2f, 0x35, 0x77, 0xb3, 0x4d, 0xa6, 0xa3, 0xce, 0x92, 0x9d, 0x0e, 0x0e, 0x47, 0x36})
+	lr.SetSpanID(pcommon.SpanID{0x00, 0xf0, 0x67, 0xaa, 0x0b, 0xa9, 0x02, 0xb7})
+
+	logs := transformLogRecords(t, newTestExporter(t, testConfig(t, "http://localhost")), ld)
+	if got := logs[0]["trace_id"]; got != "4bf92f3577b34da6a3ce929d0e0e4736" {
+		t.Errorf("trace_id = %v", got)
+	}
+	if got := logs[0]["span_id"]; got != "00f067aa0ba902b7" {
+		t.Errorf("span_id = %v", got)
+	}
+	for _, key := range []string{"trace_id", "span_id"} {
+		if _, ok := logs[1][key]; ok {
+			t.Errorf("sin contexto de traza %s debe omitirse: %v", key, logs[1])
+		}
+	}
+}
+
+func TestDroppedAttributesCount(t *testing.T) {
+	srv := newCaptureServer(t)
+	exp := newTestExporter(t, testConfig(t, srv.URL))
+	td := testTraces("svc", "a", "b")
+	td.ResourceSpans().At(0).ScopeSpans().At(0).Spans().At(0).SetDroppedAttributesCount(3)
+	if err := exp.pushTraces(context.Background(), td); err != nil {
+		t.Fatal(err)
+	}
+	var spans []map[string]interface{}
+	decodeJSON(t, srv.received()[0].Body, &spans)
+	if got := spans[0]["dropped_attributes_count"]; got != float64(3) {
+		t.Errorf("span dropped_attributes_count = %v, se esperaba 3", got)
+	}
+	if _, ok := spans[1]["dropped_attributes_count"]; ok {
+		t.Errorf("con 0 el campo debe omitirse: %v", spans[1])
+	}
+
+	ld := logsWithResources(2, "svc")
+	ld.ResourceLogs().At(0).ScopeLogs().At(0).LogRecords().At(0).SetDroppedAttributesCount(2)
+	logs := transformLogRecords(t, exp, ld)
+	if got := logs[0]["dropped_attributes_count"]; got != float64(2) {
+		t.Errorf("log dropped_attributes_count = %v, se esperaba 2", got)
+	}
+	if _, ok := logs[1]["dropped_attributes_count"]; ok {
+		t.Errorf("con 0 el campo debe omitirse: %v", logs[1])
+	}
+}
+
+func TestTraceSpanCounts(t *testing.T) {
+	// Dos trazas en un resource (3 + 1 spans) y la primera continúa en otro
+	td := testTraces("svc", "a", "b", "c")
+	other := td.ResourceSpans().At(0).ScopeSpans().At(0).Spans().AppendEmpty()
+	other.SetName("d")
+	other.SetTraceID(pcommon.TraceID{9})
+	other.SetSpanID(pcommon.SpanID{9})
+	testTraces("svc2", "e").ResourceSpans().MoveAndAppendTo(td.ResourceSpans())
+
+	srv := newCaptureServer(t)
+	cfg := testConfig(t, srv.URL)
+	cfg.IncludeTraceSpanCounts = true
+	if err := newTestExporter(t, cfg).pushTraces(context.Background(), td); err != nil {
+		t.Fatal(err)
+	}
+	reqs := srv.received()
+	if len(reqs) != 1 {
+		t.Fatalf("peticiones = %d, se esperaba 1", len(reqs))
+	}
+	var payload struct {
+		Spans           []outSpan      `json:"spans"`
+		TraceSpanCounts map[string]int `json:"trace_span_counts"`
+	}
+	decodeJSON(t, reqs[0].Body, &payload)
+	if len(payload.Spans) != 5 {
+		t.Fatalf("%d spans, se esperaban 5", len(payload.Spans))
+	}
+	first := spanHexToUUID(pcommon.TraceID{1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12, 13, 14, 15, 16}.String())
+	second := spanHexToUUID(pcommon.TraceID{9}.String())
+	want := map[string]int{first: 4, second: 1}
+	if len(payload.TraceSpanCounts) != len(want) {
+		t.Fatalf("trace_span_counts = %v, se esperaba %v", payload.TraceSpanCounts, want)
+	}
+	for id, n := range want {
+		if payload.TraceSpanCounts[id] != n {
+			t.Errorf("traza %s: %d spans, se esperaban %d", id, payload.TraceSpanCounts[id], n)
+		}
+	}
+}
+
+func TestSeriesHashStable(t *testing.T) {
+	a := pcommon.NewMap()
+	a.PutStr("host", "h1")
+	a.PutInt("port", 8080)
+	a.PutStr("region", "eu")
+	b := pcommon.NewMap()
+	b.PutStr("region", "eu")
+	b.PutInt("port", 8080)
+	b.PutStr("host", "h1")
+
+	got := seriesHash("cpu.usage", a)
+	if other := seriesHash("cpu.usage", b); got != other {
+		t.Errorf("el hash depende del orden de los atributos: %s != %s", got, other)
+	}
+	// Valor fijo: el hash no puede cambiar entre ejecuciones ni versiones
+	if want := "d85425e991bac9a2"; got != want {
+		t.Errorf("seriesHash = %s, se esperaba %s", got, want)
+	}
+	if seriesHash("cpu.idle", a) == got {
+		t.Error("el nombre de la métrica debe formar parte del hash")
+	}
+	b.PutStr("host", "h2")
+	if seriesHash("cpu.usage", b) == got {
+		t.Error("el valor de los atributos debe formar parte del hash")
+	}
+}
+
+// writeCertPEM guarda un certificado DER como CA en PEM
+func writeCertPEM(t *testing.T, der []byte) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "ca.pem")
+	data := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})
+	if err := os.WriteFile(path, data, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+// otherCA genera una CA autofirmada que no firma el certificado de httptest
+func otherCA(t *testing.T) []byte {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tmpl := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "otra CA"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+		KeyUsage:              x509.KeyUsageCertSign,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return der
+}
+
+func TestTLSCAPinning(t *testing.T) {
+	var received atomic.Int32
+	srv := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) { received.Add(1) }))
+	// Los handshakes rechazados son lo esperado; no ensuciar la salida
+	srv.Config.ErrorLog = log.New(io.Discard, "", 0)
+	srv.StartTLS()
+	defer srv.Close()
+
+	tests := []struct {
+		name    string
+		tls     configtls.ClientConfig
+		wantErr bool
+	}{
+		{"CA del servidor", configtls.ClientConfig{Config: configtls.Config{CAFile: writeCertPEM(t, srv.Certificate().Raw)}}, false},
+		{"otra CA", configtls.ClientConfig{Config: configtls.Config{CAFile: writeCertPEM(t, otherCA(t))}}, true},
+		{"sin CA", configtls.ClientConfig{}, true},
+		{"insecure_skip_verify", configtls.ClientConfig{InsecureSkipVerify: true}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			received.Store(0)
+			cfg := testConfig(t, srv.URL)
+			cfg.TLS = &tt.tls
+			err := newTestExporter(t, cfg).pushMetrics(context.Background(), gaugeMetrics("m"))
+			if tt.wantErr {
+				var certErr *tls.CertificateVerificationError
+				if !errors.As(err, &certErr) {
+					t.Errorf("err = %v, se esperaba un error de verificación del certificado", err)
+				}
+				if received.Load() != 0 {
+					t.Error("la petición llegó al servidor con una CA que no lo firma")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if received.Load() != 1 {
+				t.Errorf("peticiones = %d, se esperaba 1", received.Load())
+			}
+		})
+	}
+}
+
+func TestProxyURLRoutesThroughProxy(t *testing.T) {
+	var mu sync.Mutex
+	var proxied []string
+	proxy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// Un proxy HTTP recibe la URL absoluta del destino
+		mu.Lock()
+		proxied = append(proxied, r.URL.String())
+		mu.Unlock()
+	}))
+	defer proxy.Close()
+
+	tests := []struct {
+		name string
+		tls  *configtls.ClientConfig
+	}{
+		{"transporte por defecto", nil},
+		{"transporte con TLS propio", &configtls.ClientConfig{InsecureSkipVerify: true}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mu.Lock()
+			proxied = nil
+			mu.Unlock()
+			// backend.invalid no resuelve: solo llega si pasa por el proxy
+			cfg := testConfig(t, "http://backend.invalid")
+			cfg.ProxyURL = proxy.URL
+			cfg.TLS = tt.tls
+			if err := newTestExporter(t, cfg).pushMetrics(context.Background(), gaugeMetrics("m")); err != nil {
+				t.Fatal(err)
+			}
+			mu.Lock()
+			defer mu.Unlock()
+			if len(proxied) != 1 || proxied[0] != "http://backend.invalid/metrics" {
+				t.Errorf("peticiones en el proxy = %v, se esperaba http://backend.invalid/metrics", proxied)
+			}
+		})
+	}
+}
+
+func TestPermanentAndRetryableStatus(t *testing.T) {
+	tests := []struct {
+		status    int
+		permanent bool
+	}{
+		{http.StatusBadRequest, true},
+		{http.StatusUnauthorized, true},
+		{http.StatusRequestEntityTooLarge, true},
+		{http.StatusRequestTimeout, false},
+		{http.StatusTooManyRequests, false},
+		{http.StatusInternalServerError, false},
+		{http.StatusServiceUnavailable, false},
+	}
+	for _, tt := range tests {
+		t.Run(http.StatusText(tt.status), func(t *testing.T) {
+			// failOnce responde tt.status a la primera petición y 200 al resto
+			failOnce := func() http.HandlerFunc {
+				var mu sync.Mutex
+				seen := 0
+				return func(w http.ResponseWriter, r *http.Request) {
+					mu.Lock()
+					defer mu.Unlock()
+					if seen++; seen == 1 {
+						w.WriteHeader(tt.status)
+					}
+				}
+			}
+			srv := newCaptureServer(t)
+			srv.respond(failOnce())
+			cfg := testConfig(t, srv.URL)
+			err := newTestExporter(t, cfg).pushMetrics(context.Background(), gaugeMetrics("m"))
+			if err == nil || consumererror.IsPermanent(err) != tt.permanent {
+				t.Fatalf("err = %v, se esperaba permanente = %v", err, tt.permanent)
+			}
+
+			// Con reintentos, solo los errores no permanentes vuelven a enviarse
+			srv = newCaptureServer(t)
+			srv.respond(failOnce())
+			cfg = testConfig(t, srv.URL)
+			fastRetries(cfg)
+			_, metrics, _ := newFactoryExporters(t, cfg)
+			err = metrics.ConsumeMetrics(context.Background(), gaugeMetrics("m"))
+			want := 2
+			if tt.permanent {
+				want = 1
+			}
+			if got := len(srv.received()); got != want {
+				t.Errorf("peticiones = %d, se esperaban %d", got, want)
+			}
+			if (err != nil) != tt.permanent {
+				t.Errorf("err = %v", err)
+			}
+		})
+	}
+}
+
+func TestNetworkErrorIsRetryable(t *testing.T) {
+	srv := httptest.NewServer(http.NotFoundHandler())
+	url := srv.URL
+	srv.Close() // nadie escucha en url
+	err := newTestExporter(t, testConfig(t, url)).pushMetrics(context.Background(), gaugeMetrics("m"))
+	if err == nil || consumererror.IsPermanent(err) {
+		t.Errorf("err = %v, un error de red debe ser reintentable", err)
+	}
+}
@@ -0,0 +1,33 @@
+package opentelemetryexportermonitoring
+
+import "go.uber.org/zap"
+
+// splitPayload serializa records con encode y, si el body supera
+// max_payload_bytes, parte los registros por la mitad hasta que cada trozo
+// quepa. Un registro que por sí solo supera el límite se envía igualmente
+// con un aviso
+func splitPayload[T any](m *monitoringExporter, records []T, encode func([]T) ([]byte, error)) ([][]byte, error) {
+	body, err := encode(records)
+	if err != nil {
+		return nil, err
+	}
+	if m.maxPayloadBytes <= 0 || len(body) <= m.maxPayloadBytes {
+		return [][]byte{body}, nil
+	}
+	if len(records) <= 1 {
+		m.logger.Warn("un único registro supera max_payload_bytes, se envía igualmente",
+			zap.Int("bytes", len(body)), zap.Int("max_payload_bytes", m.maxPayloadBytes))
+		return [][]byte{body}, nil
+	}
+
+	half := len(records) / 2
+	left, err := splitPayload(m, records[:half], encode)
+	if err != nil {
+		return nil, err
+	}
+	right, err := splitPayload(m, records[half:], encode)
+	if err != nil {
+		return nil, err
+	}
+	return append(left, right...), nil
+}
@@ -0,0 +1,188 @@
+package opentelemetryexportermonitoring
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/golang/snappy"
+	"go.opentelemetry.io/collector/pdata/plog"
+	"go.opentelemetry.io/collector/pdata/pmetric"
+	"go.opentelemetry.io/collector/pdata/ptrace"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+// splitServices son los resources de los lotes de los tests de
+// max_payload_bytes, con dos registros cada uno
+var splitServices = []string{"svc-a", "svc-b", "svc-c", "svc-d"}
+
+func splitTraces() ptrace.Traces {
+	td := ptrace.NewTraces()
+	for _, svc := range splitServices {
+		testTraces(svc, "get", "put").ResourceSpans().MoveAndAppendTo(td.ResourceSpans())
+	}
+	return td
+}
+
+func splitMetrics() pmetric.Metrics {
+	md := pmetric.NewMetrics()
+	for _, svc := range splitServices {
+		batch := gaugeMetrics("requests", "errors")
+		batch.ResourceMetrics().At(0).Resource().Attributes().PutStr("service.name", svc)
+		batch.ResourceMetrics().MoveAndAppendTo(md.ResourceMetrics())
+	}
+	return md
+}
+
+func TestMaxPayloadBytesSplits(t *testing.T) {
+	decodeArray := func(t *testing.T, body []byte) int {
+		var records []json.RawMessage
+		decodeJSON(t, body, &records)
+		return len(records)
+	}
+	tests := []struct {
+		name     string
+		signal   string
+		encoding string
+		count    func(t *testing.T, body []byte) int
+	}{
+		{"traces custom", "traces", encodingCustom, decodeArray},
+		{"traces otlp_json", "traces", encodingOTLPJSON, func(t *testing.T, body []byte) int {
+			td, err := (&ptrace.JSONUnmarshaler{}).UnmarshalTraces(body)
+			if err != nil {
+				t.Fatal(err)
+			}
+			return td.SpanCount()
+		}},
+		{"traces zipkin", "traces", encodingZipkin, decodeArray},
+		{"metrics custom", "metrics", encodingCustom, func(t *testing.T, body []byte) int {
+			var payload struct {
+				Metrics []json.RawMessage `json:"metrics"`
+			}
+			decodeJSON(t, body, &payload)
+			return len(payload.Metrics)
+		}},
+		{"metrics otlp_proto", "metrics", encodingOTLPProto, func(t *testing.T, body []byte) int {
+			md, err := (&pmetric.ProtoUnmarshaler{}).UnmarshalMetrics(body)
+			if err != nil {
+				t.Fatal(err)
+			}
+			return md.DataPointCount()
+		}},
+		{"metrics prometheus_remote_write", "metrics", encodingPromRW, func(t *testing.T, body []byte) int {
+			data, err := snappy.Decode(nil, body)
+			if err != nil {
+				t.Fatal(err)
+			}
+			return len(decodeWriteRequest(t, data))
+		}},
+		{"logs custom", "logs", encodingCustom, decodeArray},
+		{"logs otlp_json", "logs", encodingOTLPJSON, func(t *testing.T, body []byte) int {
+			ld, err := (&plog.JSONUnmarshaler{}).UnmarshalLogs(body)
+			if err != nil {
+				t.Fatal(err)
+			}
+			return ld.LogRecordCount()
+		}},
+		{"logs loki", "logs", encodingLoki, func(t *testing.T, body []byte) int {
+			var payload struct {
+				Streams []lokiStream `json:"streams"`
+			}
+			decodeJSON(t, body, &payload)
+			n := 0
+			for _, s := range payload.Streams {
+				n += len(s.Values)
+			}
+			return n
+		}},
+		{"logs ndjson", "logs", encodingNDJSON, func(t *testing.T, body []byte) int {
+			return bytes.Count(body, []byte("\n"))
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Sin límite primero para conocer el tamaño del lote completo
+			push := func(maxPayloadBytes int) [][]byte {
+				t.Helper()
+				srv := newCaptureServer(t)
+				cfg := testConfig(t, srv.URL)
+				cfg.MaxPayloadBytes = maxPayloadBytes
+				switch tt.signal {
+				case "traces":
+					cfg.TracesEncoding = tt.encoding
+				case "metrics":
+					cfg.MetricsEncoding = tt.encoding
+				case "logs":
+					cfg.LogsEncoding = tt.encoding
+				}
+				traces, metrics, logs := newFactoryExporters(t, cfg)
+				ctx := context.Background()
+				var err error
+				switch tt.signal {
+				case "traces":
+					err = traces.ConsumeTraces(ctx, splitTraces())
+				case "metrics":
+					err = metrics.ConsumeMetrics(ctx, splitMetrics())
+				case "logs":
+					err = logs.ConsumeLogs(ctx, logsWithResources(2, splitServices...))
+				}
+				if err != nil {
+					t.Fatal(err)
+				}
+				var bodies [][]byte
+				for _, req := range srv.received() {
+					if req.Method == http.MethodPost {
+						bodies = append(bodies, req.Body)
+					}
+				}
+				return bodies
+			}
+
+			whole := push(0)
+			if len(whole) != 1 || tt.count(t, whole[0]) != 2*len(splitServices) {
+				t.Fatalf("sin límite se esperaba un único body con %d registros", 2*len(splitServices))
+			}
+			max := len(whole[0]) / 2
+			parts := push(max)
+			if len(parts) < 2 {
+				t.Fatalf("partes = %d con max_payload_bytes %d y un lote de %d bytes", len(parts), max, len(whole[0]))
+			}
+			total := 0
+			for i, body := range parts {
+				if len(body) > max {
+					t.Errorf("parte %d: %d bytes, supera max_payload_bytes %d", i, len(body), max)
+				}
+				total += tt.count(t, body)
+			}
+			if total != 2*len(splitServices) {
+				t.Errorf("registros = %d entre las partes, se esperaban %d", total, 2*len(splitServices))
+			}
+		})
+	}
+}
+
+func TestMaxPayloadBytesSingleRecord(t *testing.T) {
+	srv := newCaptureServer(t)
+	cfg := testConfig(t, srv.URL)
+	cfg.MaxPayloadBytes = 100
+	core, warnings := observer.New(zapcore.WarnLevel)
+	exp, err := newMonitoringExporter(cfg.withFormat(), zap.New(core))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ld := logsWithResources(1, "svc")
+	ld.ResourceLogs().At(0).ScopeLogs().At(0).LogRecords().At(0).Body().SetStr(strings.Repeat("x", 1000))
+	reqs := pushedLogs(t, exp, srv, ld)
+	if len(reqs) != 1 || len(reqs[0].Body) <= cfg.MaxPayloadBytes {
+		t.Fatalf("peticiones = %d; el registro que no cabe debía enviarse entero", len(reqs))
+	}
+	if warnings.FilterMessageSnippet("max_payload_bytes").Len() != 1 {
+		t.Errorf("avisos = %v, se esperaba uno por el registro que no cabe", warnings.All())
+	}
+}
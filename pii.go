@@ -0,0 +1,53 @@
+package opentelemetryexportermonitoring
+
+import (
+	"fmt"
+	"regexp"
+
+	"go.opentelemetry.io/collector/pdata/pcommon"
+)
+
+// defaultPIIPatterns detectan emails y SSN de EE. UU. (123-45-6789)
+var defaultPIIPatterns = []string{
+	`[A-Za-z0-9._%+-]+@[A-Za-z0-9.-]+\.[A-Za-z]{2,}`,
+	`\b\d{3}-\d{2}-\d{4}\b`,
+}
+
+// compilePIIPatterns compila pii_patterns o los patrones por defecto
+func compilePIIPatterns(patterns []string) ([]*regexp.Regexp, error) {
+	if len(patterns) == 0 {
+		patterns = defaultPIIPatterns
+	}
+	out := make([]*regexp.Regexp, 0, len(patterns))
+	for _, p := range patterns {
+		re, err := regexp.Compile(p)
+		if err != nil {
+			return nil, fmt.Errorf("pii_patterns: patrón inválido %q: %w", p, err)
+		}
+		out = append(out, re)
+	}
+	return out, nil
+}
+
+// containsPII indica si algún patrón aparece en el texto
+func (m *monitoringExporter) containsPII(text string) bool {
+	for _, re := range m.piiPatterns {
+		if re.MatchString(text) {
+			return true
+		}
+	}
+	return false
+}
+
+// logContainsPII revisa el body y los valores de los atributos del registro
+func (m *monitoringExporter) logContainsPII(body string, attrs pcommon.Map) bool {
+	if m.containsPII(body) {
+		return true
+	}
+	found := false
+	attrs.Range(func(_ string, v pcommon.Value) bool {
+		found = m.containsPII(v.AsString())
+		return !found
+	})
+	return found
+}
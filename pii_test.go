@@ -0,0 +1,63 @@
+package opentelemetryexportermonitoring
+
+import (
+	"testing"
+
+	"go.opentelemetry.io/collector/pdata/plog"
+)
+
+func TestDetectPIIBodyAndAttributes(t *testing.T) {
+	tests := []struct {
+		name     string
+		patterns []string
+		build    func(plog.LogRecord)
+		want     bool
+	}{
+		{"email en el body", nil, func(r plog.LogRecord) { r.Body().SetStr("alta de ana@example.com") }, true},
+		{"SSN en el body", nil, func(r plog.LogRecord) { r.Body().SetStr("ssn 123-45-6789 recibido") }, true},
+		{"email en un atributo", nil, func(r plog.LogRecord) {
+			r.Body().SetStr("usuario creado")
+			r.Attributes().PutStr("user.email", "ana@example.com")
+		}, true},
+		{"SSN en un atributo que no es texto", nil, func(r plog.LogRecord) {
+			r.Body().SetStr("usuario creado")
+			r.Attributes().PutEmptySlice("ids").AppendEmpty().SetStr("123-45-6789")
+		}, true},
+		{"sin PII", nil, func(r plog.LogRecord) {
+			r.Body().SetStr("pedido 12345 servido")
+			r.Attributes().PutStr("order.id", "12-345")
+		}, false},
+		{"patrón propio", []string{`tarjeta-\d{4}`}, func(r plog.LogRecord) {
+			r.Body().SetStr("ana@example.com")
+			r.Attributes().PutStr("pago", "tarjeta-4242")
+		}, true},
+		{"los patrones propios sustituyen a los de por defecto", []string{`tarjeta-\d{4}`}, func(r plog.LogRecord) {
+			r.Body().SetStr("ana@example.com")
+		}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ld := plog.NewLogs()
+			tt.build(ld.ResourceLogs().AppendEmpty().ScopeLogs().AppendEmpty().LogRecords().AppendEmpty())
+			cfg := testConfig(t, "http://localhost")
+			cfg.DetectPII = true
+			cfg.PIIPatterns = tt.patterns
+			logs := transformLogRecords(t, newTestExporter(t, cfg), ld)
+			if len(logs) != 1 {
+				t.Fatalf("registros = %d, se esperaba 1", len(logs))
+			}
+			if got := logs[0]["contains_pii"] == true; got != tt.want {
+				t.Errorf("contains_pii = %v, se esperaba %v", logs[0]["contains_pii"], tt.want)
+			}
+		})
+	}
+}
+
+func TestDetectPIIDisabled(t *testing.T) {
+	ld := plog.NewLogs()
+	ld.ResourceLogs().AppendEmpty().ScopeLogs().AppendEmpty().LogRecords().AppendEmpty().Body().SetStr("ana@example.com")
+	logs := transformLogRecords(t, newTestExporter(t, testConfig(t, "http://localhost")), ld)
+	if _, ok := logs[0]["contains_pii"]; ok {
+		t.Errorf("contains_pii sin detect_pii: %v", logs[0])
+	}
+}
@@ -0,0 +1,63 @@
+package opentelemetryexportermonitoring
+
+import (
+	"context"
+	"errors"
+
+	"go.opentelemetry.io/collector/exporter"
+	"go.opentelemetry.io/collector/exporter/exporterhelper"
+	"go.opentelemetry.io/collector/pdata/plog"
+	"go.opentelemetry.io/collector/pdata/pmetric"
+	"go.opentelemetry.io/collector/pdata/ptrace"
+	"go.uber.org/zap"
+)
+
+// Políticas de cola llena. No hay drop_oldest: la cola del exporterhelper no
+// expone cómo descartar el lote más antiguo
+const (
+	queueFullBlock      = "block"       // espera a que haya hueco
+	queueFullDropNewest = "drop_newest" // descarta el lote entrante
+)
+
+// recordQueueDrop cuenta un lote descartado por cola llena. Con drop_newest el
+// error no se propaga: el lote se da por consumido para no frenar el pipeline
+func (m *monitoringExporter) recordQueueDrop(err error) error {
+	if m.queueFullPolicy != queueFullDropNewest || !errors.Is(err, exporterhelper.ErrQueueIsFull) {
+		return err
+	}
+	dropped := m.queueDropped.Add(1)
+	if m.connStats != nil {
+		m.connStats.queueDropped.Add(1)
+	}
+	m.logger.Warn("cola de envío llena, lote descartado", zap.Int64("dropped_total", dropped))
+	return nil
+}
+
+// queueDropTraces, queueDropMetrics y queueDropLogs aplican recordQueueDrop
+// al exporter creado por el exporterhelper
+type queueDropTraces struct {
+	exporter.Traces
+	exp *monitoringExporter
+}
+
+func (q queueDropTraces) ConsumeTraces(ctx context.Context, td ptrace.Traces) error {
+	return q.exp.recordQueueDrop(q.Traces.ConsumeTraces(ctx, td))
+}
+
+type queueDropMetrics struct {
+	exporter.Metrics
+	exp *monitoringExporter
+}
+
+func (q queueDropMetrics) ConsumeMetrics(ctx context.Context, md pmetric.Metrics) error {
+	return q.exp.recordQueueDrop(q.Metrics.ConsumeMetrics(ctx, md))
+}
+
+type queueDropLogs struct {
+	exporter.Logs
+	exp *monitoringExporter
+}
+
+func (q queueDropLogs) ConsumeLogs(ctx context.Context, ld plog.Logs) error {
+	return q.exp.recordQueueDrop(q.Logs.ConsumeLogs(ctx, ld))
+}
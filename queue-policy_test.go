@@ -0,0 +1,80 @@
+package opentelemetryexportermonitoring
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"go.opentelemetry.io/collector/exporter/exporterhelper"
+	"go.opentelemetry.io/collector/exporter/exportertest"
+)
+
+func TestQueueFullDropNewest(t *testing.T) {
+	tests := []struct {
+		policy  string
+		wantErr bool
+	}{
+		{queueFullDropNewest, false},
+		{"", true}, // sin política: el error de cola llena llega al pipeline
+	}
+	for _, tt := range tests {
+		t.Run(tt.policy, func(t *testing.T) {
+			// El servidor retiene la primera petición para que la cola se llene
+			arrived := make(chan struct{}, 4)
+			release := make(chan struct{})
+			srv := newCaptureServer(t)
+			srv.respond(func(w http.ResponseWriter, r *http.Request) {
+				arrived <- struct{}{}
+				<-release
+			})
+			cfg := testConfig(t, srv.URL)
+			cfg.QueueFullPolicy = tt.policy
+			cfg.QueueSettings.Enabled = true
+			cfg.QueueSettings.QueueSize = 2
+			cfg.QueueSettings.NumConsumers = 1
+
+			traces, err := createTracesExporter(context.Background(), exportertest.NewNopSettings(typeStr), cfg)
+			if err != nil {
+				t.Fatal(err)
+			}
+			startComponent(t, traces)
+			t.Cleanup(func() { close(release) })
+
+			ctx := context.Background()
+			if err := traces.ConsumeTraces(ctx, testTraces("svc", "a")); err != nil {
+				t.Fatal(err)
+			}
+			select {
+			case <-arrived:
+			case <-time.After(2 * time.Second):
+				t.Fatal("el primer lote no llegó al servidor")
+			}
+			// El lote en curso sigue ocupando su hueco hasta terminar: el
+			// segundo llena la cola y el tercero no cabe
+			if err := traces.ConsumeTraces(ctx, testTraces("svc", "b")); err != nil {
+				t.Fatal(err)
+			}
+			err = traces.ConsumeTraces(ctx, testTraces("svc", "c"))
+			if tt.wantErr {
+				if !errors.Is(err, exporterhelper.ErrQueueIsFull) {
+					t.Errorf("err = %v, se esperaba cola llena", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Errorf("drop_newest devolvió %v, el lote debía descartarse sin error", err)
+			}
+			if got := traces.(queueDropTraces).exp.queueDropped.Load(); got != 1 {
+				t.Errorf("lotes descartados = %d, se esperaba 1", got)
+			}
+		})
+	}
+}
+
+func TestQueueFullDropOldestUnsupported(t *testing.T) {
+	cfg := testConfig(t, "http://localhost")
+	cfg.QueueFullPolicy = "drop_oldest"
+	validateError(t, cfg, "queue_full_policy")
+}
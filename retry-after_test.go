@@ -0,0 +1,68 @@
+package opentelemetryexportermonitoring
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestRetryAfterFormats(t *testing.T) {
+	now := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name   string
+		header string
+		want   time.Duration
+	}{
+		{"segundos", "120", 2 * time.Minute},
+		{"segundos con espacios", " 3 ", 3 * time.Second},
+		{"fecha HTTP", "Wed, 01 May 2024 12:00:30 GMT", 30 * time.Second},
+		{"fecha RFC 850", "Wednesday, 01-May-24 12:01:00 GMT", time.Minute},
+		{"fecha pasada", "Wed, 01 May 2024 11:59:00 GMT", 0},
+		{"cero", "0", 0},
+		{"negativo", "-5", 0},
+		{"vacía", "", 0},
+		{"ilegible", "pronto", 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := retryAfter(tt.header, now); got != tt.want {
+				t.Errorf("retryAfter(%q) = %s, se esperaba %s", tt.header, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRetryAfterDelaysRetry(t *testing.T) {
+	for _, status := range []int{http.StatusTooManyRequests, http.StatusServiceUnavailable} {
+		t.Run(http.StatusText(status), func(t *testing.T) {
+			srv := newCaptureServer(t)
+			var mu sync.Mutex
+			var times []time.Time
+			srv.respond(func(w http.ResponseWriter, r *http.Request) {
+				mu.Lock()
+				defer mu.Unlock()
+				if times = append(times, time.Now()); len(times) == 1 {
+					w.Header().Set("Retry-After", "1")
+					w.WriteHeader(status)
+				}
+			})
+			cfg := testConfig(t, srv.URL)
+			fastRetries(cfg)
+			_, metrics, _ := newFactoryExporters(t, cfg)
+			if err := metrics.ConsumeMetrics(context.Background(), gaugeMetrics("m")); err != nil {
+				t.Fatal(err)
+			}
+			mu.Lock()
+			defer mu.Unlock()
+			if len(times) != 2 {
+				t.Fatalf("peticiones = %d, se esperaban 2", len(times))
+			}
+			// El backoff de fastRetries es de milisegundos: la espera es la del servidor
+			if wait := times[1].Sub(times[0]); wait < time.Second {
+				t.Errorf("el reintento llegó a los %s, antes de Retry-After", wait)
+			}
+		})
+	}
+}
@@ -0,0 +1,76 @@
+package opentelemetryexportermonitoring
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"go.opentelemetry.io/collector/consumer/consumererror"
+)
+
+// maxClassifiedBody limita los bytes de respuesta leídos para clasificarla
+const maxClassifiedBody = 1 << 20
+
+// RetryClassificationRule clasifica una respuesta según un campo de su body
+// JSON. Path es una ruta con puntos ("error.code"); si Values está vacío basta
+// con que el campo exista. Se aplica a cualquier código HTTP, incluido 2xx
+type RetryClassificationRule struct {
+	Path      string   `mapstructure:"path"`
+	Values    []string `mapstructure:"values"`
+	Retryable bool     `mapstructure:"retryable"`
+}
+
+// classifyResponse evalúa retry_classification sobre data, el body ya leído
+// de la respuesta. Devuelve nil si ninguna regla coincide; si no, un error
+// reintentable o permanente según la primera regla que coincida
+func (m *monitoringExporter) classifyResponse(status int, data []byte, url string) error {
+	if len(m.retryClassification) == 0 || len(data) == 0 {
+		return nil
+	}
+	var body interface{}
+	if err := json.Unmarshal(data, &body); err != nil {
+		return nil
+	}
+
+	for _, rule := range m.retryClassification {
+		value, ok := jsonPath(body, rule.Path)
+		if !ok || !matchesRuleValue(value, rule.Values) {
+			continue
+		}
+		err := fmt.Errorf("monitoring exporter: %s -> HTTP %d, %s: %v", url, status, rule.Path, value)
+		if rule.Retryable {
+			return err
+		}
+		return consumererror.NewPermanent(err)
+	}
+	return nil
+}
+
+// jsonPath recorre un JSON decodificado siguiendo las claves separadas por puntos
+func jsonPath(v interface{}, path string) (interface{}, bool) {
+	for _, key := range strings.Split(path, ".") {
+		obj, ok := v.(map[string]interface{})
+		if !ok {
+			return nil, false
+		}
+		if v, ok = obj[key]; !ok {
+			return nil, false
+		}
+	}
+	return v, true
+}
+
+// matchesRuleValue compara el valor como texto (los números sin decimales
+// superfluos: 500, no 500.000000)
+func matchesRuleValue(value interface{}, values []string) bool {
+	if len(values) == 0 {
+		return true
+	}
+	text := fmt.Sprint(value)
+	for _, want := range values {
+		if text == want {
+			return true
+		}
+	}
+	return false
+}
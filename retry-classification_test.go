@@ -0,0 +1,69 @@
+package opentelemetryexportermonitoring
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+
+	"go.opentelemetry.io/collector/consumer/consumererror"
+)
+
+func TestRetryClassificationJSONCode(t *testing.T) {
+	rules := []RetryClassificationRule{
+		{Path: "error.code", Values: []string{"RATE_LIMITED", "503"}, Retryable: true},
+		{Path: "error.code", Values: []string{"INVALID"}},
+	}
+	tests := []struct {
+		name      string
+		status    int
+		body      string
+		wantErr   bool
+		permanent bool
+	}{
+		{"2xx con código reintentable", http.StatusOK, `{"error":{"code":"RATE_LIMITED"}}`, true, false},
+		{"código numérico", http.StatusOK, `{"error":{"code":503}}`, true, false},
+		{"4xx reclasificado como reintentable", http.StatusBadRequest, `{"error":{"code":"RATE_LIMITED"}}`, true, false},
+		{"2xx con código permanente", http.StatusOK, `{"error":{"code":"INVALID"}}`, true, true},
+		{"2xx sin regla que coincida", http.StatusOK, `{"error":{"code":"OTHER"}}`, false, false},
+		{"body no JSON", http.StatusOK, `ok`, false, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := newCaptureServer(t)
+			srv.respond(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(tt.status)
+				w.Write([]byte(tt.body))
+			})
+			cfg := testConfig(t, srv.URL)
+			cfg.RetryClassification = rules
+			err := newTestExporter(t, cfg).pushMetrics(context.Background(), gaugeMetrics("m"))
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("err = %v, se esperaba error: %v", err, tt.wantErr)
+			}
+			if err != nil && consumererror.IsPermanent(err) != tt.permanent {
+				t.Errorf("err = %v, permanente = %v, se esperaba %v", err, consumererror.IsPermanent(err), tt.permanent)
+			}
+		})
+	}
+}
+
+func TestRetryClassificationKeepsBodyForStatusError(t *testing.T) {
+	srv := newCaptureServer(t)
+	srv.respond(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusUnprocessableEntity)
+		w.Write([]byte(`{"error":{"code":"SCHEMA","message":"campo desconocido"}}`))
+	})
+	cfg := testConfig(t, srv.URL)
+	cfg.RetryClassification = []RetryClassificationRule{{Path: "error.code", Values: []string{"RATE_LIMITED"}, Retryable: true}}
+	err := newTestExporter(t, cfg).pushMetrics(context.Background(), gaugeMetrics("m"))
+
+	// Ninguna regla coincide: el body ya leído debe seguir en el error HTTP
+	var statusErr *httpStatusError
+	if !errors.As(err, &statusErr) {
+		t.Fatalf("err = %v, se esperaba un httpStatusError", err)
+	}
+	if statusErr.body != `{"error":{"code":"SCHEMA","message":"campo desconocido"}}` {
+		t.Errorf("body del error = %q", statusErr.body)
+	}
+}
@@ -0,0 +1,18 @@
+package opentelemetryexportermonitoring
+
+import "go.opentelemetry.io/collector/pdata/pcommon"
+
+// outScope es el instrumentation scope (librería) que generó el registro
+type outScope struct {
+	Name    string `json:"name,omitempty"`
+	Version string `json:"version,omitempty"`
+}
+
+// newOutScope devuelve nil si el scope no trae nombre ni versión, para que el
+// campo se omita
+func newOutScope(scope pcommon.InstrumentationScope) *outScope {
+	if scope.Name() == "" && scope.Version() == "" {
+		return nil
+	}
+	return &outScope{Name: scope.Name(), Version: scope.Version()}
+}
@@ -0,0 +1,71 @@
+package opentelemetryexportermonitoring
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+)
+
+func TestScopeFields(t *testing.T) {
+	srv := newCaptureServer(t)
+	exp := newTestExporter(t, testConfig(t, srv.URL))
+	ctx := context.Background()
+
+	td := testTraces("svc", "op")
+	td.ResourceSpans().At(0).ScopeSpans().At(0).Scope().SetName("io.opentelemetry.http")
+	td.ResourceSpans().At(0).ScopeSpans().At(0).Scope().SetVersion("1.2.0")
+	md := gaugeMetrics("m")
+	md.ResourceMetrics().At(0).ScopeMetrics().At(0).Scope().SetName("io.opentelemetry.http")
+	md.ResourceMetrics().At(0).ScopeMetrics().At(0).Scope().SetVersion("1.2.0")
+	ld := logsWithResources(1, "svc")
+	ld.ResourceLogs().At(0).ScopeLogs().At(0).Scope().SetName("io.opentelemetry.http")
+	ld.ResourceLogs().At(0).ScopeLogs().At(0).Scope().SetVersion("1.2.0")
+
+	if err := exp.pushTraces(ctx, td); err != nil {
+		t.Fatal(err)
+	}
+	if err := exp.pushMetrics(ctx, md); err != nil {
+		t.Fatal(err)
+	}
+	if err := exp.pushLogs(ctx, ld); err != nil {
+		t.Fatal(err)
+	}
+	bodies := map[string][]byte{}
+	for _, req := range srv.received() {
+		bodies[req.Path] = req.Body
+	}
+
+	type scoped struct {
+		Scope *outScope `json:"scope"`
+	}
+	var spans []scoped
+	decodeJSON(t, bodies["/traces"], &spans)
+	var metrics struct {
+		Metrics []scoped `json:"metrics"`
+	}
+	decodeJSON(t, bodies["/metrics"], &metrics)
+	var logs []scoped
+	decodeJSON(t, bodies["/logs"], &logs)
+
+	want := outScope{Name: "io.opentelemetry.http", Version: "1.2.0"}
+	for signal, records := range map[string][]scoped{"traces": spans, "metrics": metrics.Metrics, "logs": logs} {
+		if len(records) != 1 {
+			t.Fatalf("%s: registros = %d, se esperaba 1", signal, len(records))
+		}
+		if records[0].Scope == nil || *records[0].Scope != want {
+			t.Errorf("%s: scope = %+v, se esperaba %+v", signal, records[0].Scope, want)
+		}
+	}
+}
+
+func TestScopeOmittedWhenEmpty(t *testing.T) {
+	srv := newCaptureServer(t)
+	if err := newTestExporter(t, testConfig(t, srv.URL)).pushLogs(context.Background(), logsWithResources(1, "svc")); err != nil {
+		t.Fatal(err)
+	}
+	var logs []map[string]json.RawMessage
+	decodeJSON(t, srv.received()[0].Body, &logs)
+	if scope, ok := logs[0]["scope"]; ok {
+		t.Errorf("un scope vacío no debe emitirse: %s", scope)
+	}
+}
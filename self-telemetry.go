@@ -0,0 +1,59 @@
+package opentelemetryexportermonitoring
+
+import (
+	"context"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+)
+
+// selfTelemetryScope es el nombre del meter de las métricas propias
+const selfTelemetryScope = "github.com/wexmaster/opentelemetryexportermonitoring"
+
+// selfTelemetry son las métricas del propio exporter (peticiones, fallos,
+// bytes y latencia) emitidas con el MeterProvider del collector y el
+// atributo signal
+type selfTelemetry struct {
+	requests metric.Int64Counter
+	failures metric.Int64Counter
+	duration metric.Float64Histogram
+	size     metric.Int64Histogram
+	attrs    metric.MeasurementOption
+}
+
+func newSelfTelemetry(mp metric.MeterProvider, signal string) (*selfTelemetry, error) {
+	meter := mp.Meter(selfTelemetryScope)
+	t := &selfTelemetry{attrs: metric.WithAttributeSet(attribute.NewSet(attribute.String("signal", signal)))}
+	var err error
+	if t.requests, err = meter.Int64Counter("monitoring_exporter.request_count",
+		metric.WithDescription("Peticiones HTTP enviadas al backend"), metric.WithUnit("{request}")); err != nil {
+		return nil, err
+	}
+	if t.failures, err = meter.Int64Counter("monitoring_exporter.failure_count",
+		metric.WithDescription("Peticiones HTTP fallidas (error de red o respuesta no 2xx)"), metric.WithUnit("{request}")); err != nil {
+		return nil, err
+	}
+	if t.duration, err = meter.Float64Histogram("monitoring_exporter.request_duration",
+		metric.WithDescription("Duración de cada petición HTTP"), metric.WithUnit("s")); err != nil {
+		return nil, err
+	}
+	if t.size, err = meter.Int64Histogram("monitoring_exporter.request_size_bytes",
+		metric.WithDescription("Tamaño del body antes de comprimir y cifrar"), metric.WithUnit("By")); err != nil {
+		return nil, err
+	}
+	return t, nil
+}
+
+// record anota una petición; no hace nada si la telemetría no está creada
+func (t *selfTelemetry) record(ctx context.Context, elapsed time.Duration, bytes int, err error) {
+	if t == nil {
+		return
+	}
+	t.requests.Add(ctx, 1, t.attrs)
+	t.duration.Record(ctx, elapsed.Seconds(), t.attrs)
+	t.size.Record(ctx, int64(bytes), t.attrs)
+	if err != nil {
+		t.failures.Add(ctx, 1, t.attrs)
+	}
+}
@@ -0,0 +1,75 @@
+package opentelemetryexportermonitoring
+
+import (
+	"context"
+	"net/http"
+	"sync/atomic"
+	"testing"
+
+	"go.opentelemetry.io/collector/exporter/exportertest"
+	"go.opentelemetry.io/otel/attribute"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+)
+
+// selfCounter devuelve el valor del contador name con signal=signal
+func selfCounter(t *testing.T, reader sdkmetric.Reader, name, signal string) int64 {
+	t.Helper()
+	var rm metricdata.ResourceMetrics
+	if err := reader.Collect(context.Background(), &rm); err != nil {
+		t.Fatal(err)
+	}
+	for _, sm := range rm.ScopeMetrics {
+		if sm.Scope.Name != selfTelemetryScope {
+			continue
+		}
+		for _, m := range sm.Metrics {
+			if m.Name != name {
+				continue
+			}
+			sum, ok := m.Data.(metricdata.Sum[int64])
+			if !ok {
+				t.Fatalf("%s no es un contador: %T", name, m.Data)
+			}
+			for _, dp := range sum.DataPoints {
+				if v, _ := dp.Attributes.Value(attribute.Key("signal")); v.AsString() == signal {
+					return dp.Value
+				}
+			}
+		}
+	}
+	return 0
+}
+
+func TestSelfTelemetryRecordsRequests(t *testing.T) {
+	srv := newCaptureServer(t)
+	var fail atomic.Bool
+	srv.respond(func(w http.ResponseWriter, r *http.Request) {
+		if fail.Load() {
+			w.WriteHeader(http.StatusBadRequest)
+		}
+	})
+	reader := sdkmetric.NewManualReader()
+	set := exportertest.NewNopSettings(typeStr)
+	set.MeterProvider = sdkmetric.NewMeterProvider(sdkmetric.WithReader(reader))
+
+	exp, err := createMetricsExporter(context.Background(), set, testConfig(t, srv.URL))
+	if err != nil {
+		t.Fatal(err)
+	}
+	startComponent(t, exp)
+	if err := exp.ConsumeMetrics(context.Background(), gaugeMetrics("m")); err != nil {
+		t.Fatal(err)
+	}
+	fail.Store(true)
+	if err := exp.ConsumeMetrics(context.Background(), gaugeMetrics("m")); err == nil {
+		t.Fatal("se esperaba el error del 400")
+	}
+
+	if got := selfCounter(t, reader, "monitoring_exporter.request_count", "metrics"); got != 2 {
+		t.Errorf("request_count = %d, se esperaba 2", got)
+	}
+	if got := selfCounter(t, reader, "monitoring_exporter.failure_count", "metrics"); got != 1 {
+		t.Errorf("failure_count = %d, se esperaba 1", got)
+	}
+}
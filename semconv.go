@@ -0,0 +1,60 @@
+package opentelemetryexportermonitoring
+
+// semconvRenames traduce claves de las convenciones semánticas de OTel a los
+// nombres canónicos del sink (apply_semconv_mapping). attribute_renames tiene
+// prioridad sobre esta tabla.
+//
+// Varias claves se traducen al mismo nombre (deployment.environment y
+// deployment.environment.name, container.name y k8s.container.name). Si un
+// resource trae las dos gana la última en orden alfabético, porque putAttrs
+// recorre las claves ordenadas: la clave actual deployment.environment.name
+// frente a la obsoleta y la de Kubernetes k8s.container.name frente a la del
+// runtime
+var semconvRenames = map[string]string{
+	"service.name":                "service",
+	"service.namespace":           "service_namespace",
+	"service.version":             "version",
+	"service.instance.id":         "instance",
+	"deployment.environment":      "environment",
+	"deployment.environment.name": "environment",
+	"host.name":                   "host",
+	"host.id":                     "host_id",
+	"host.arch":                   "arch",
+	"os.type":                     "os",
+	"process.pid":                 "pid",
+	"process.executable.name":     "process",
+	"container.name":              "container",
+	"container.id":                "container_id",
+	"container.image.name":        "image",
+	"container.image.tag":         "image_tag",
+	"k8s.cluster.name":            "cluster",
+	"k8s.namespace.name":          "namespace",
+	"k8s.node.name":               "node",
+	"k8s.pod.name":                "pod",
+	"k8s.pod.uid":                 "pod_uid",
+	"k8s.container.name":          "container",
+	"k8s.deployment.name":         "deployment",
+	"k8s.statefulset.name":        "statefulset",
+	"k8s.daemonset.name":          "daemonset",
+	"k8s.job.name":                "job",
+	"cloud.provider":              "cloud",
+	"cloud.region":                "cloud_region",
+	"cloud.availability_zone":     "zone",
+	"cloud.account.id":            "account",
+	"telemetry.sdk.language":      "sdk_language",
+}
+
+// buildAttributeRenames combina la tabla semconv (si está activa) con los
+// renombrados del usuario, que prevalecen
+func buildAttributeRenames(applySemconv bool, renames map[string]string) map[string]string {
+	out := make(map[string]string, len(renames)+len(semconvRenames))
+	if applySemconv {
+		for k, v := range semconvRenames {
+			out[k] = v
+		}
+	}
+	for k, v := range renames {
+		out[k] = v
+	}
+	return out
+}
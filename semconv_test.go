@@ -0,0 +1,47 @@
+package opentelemetryexportermonitoring
+
+import "testing"
+
+func TestSemconvMapping(t *testing.T) {
+	cfg := testConfig(t, "http://localhost")
+	cfg.ApplySemconvMapping = true
+	cfg.AttributeRenames = map[string]string{"host.name": "hostname"}
+	exp := newTestExporter(t, cfg)
+
+	got := map[string]interface{}{}
+	exp.putAttrs(got, map[string]interface{}{
+		"service.name":                "svc",
+		"k8s.pod.name":                "pod-1",
+		"host.name":                   "h1",
+		"deployment.environment":      "old",
+		"deployment.environment.name": "prod",
+		"container.name":              "runtime",
+		"k8s.container.name":          "app",
+		"custom.key":                  "x",
+	})
+	want := map[string]interface{}{
+		"service":     "svc",
+		"pod":         "pod-1",
+		"hostname":    "h1",   // attribute_renames prevalece sobre la tabla
+		"environment": "prod", // deployment.environment.name sobre la obsoleta
+		"container":   "app",  // k8s.container.name sobre container.name
+		"custom_key":  "x",
+	}
+	if len(got) != len(want) {
+		t.Fatalf("atributos = %v, se esperaba %v", got, want)
+	}
+	for k, v := range want {
+		if got[k] != v {
+			t.Errorf("%s = %v, se esperaba %v", k, got[k], v)
+		}
+	}
+}
+
+func TestSemconvMappingDisabled(t *testing.T) {
+	exp := newTestExporter(t, testConfig(t, "http://localhost"))
+	got := map[string]interface{}{}
+	exp.putAttrs(got, map[string]interface{}{"k8s.pod.name": "pod-1"})
+	if got["k8s_pod_name"] != "pod-1" || len(got) != 1 {
+		t.Errorf("sin apply_semconv_mapping no se renombra: %v", got)
+	}
+}
@@ -0,0 +1,52 @@
+package opentelemetryexportermonitoring
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"go.opentelemetry.io/collector/component/componenttest"
+	"go.opentelemetry.io/collector/exporter/exportertest"
+)
+
+func TestShutdownDrainsQueueAndIsIdempotent(t *testing.T) {
+	srv := newCaptureServer(t)
+	cfg := testConfig(t, srv.URL)
+	cfg.QueueSettings = createDefaultConfig().(*Config).QueueSettings
+	metrics, err := createMetricsExporter(context.Background(), exportertest.NewNopSettings(typeStr), cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := metrics.Start(context.Background(), componenttest.NewNopHost()); err != nil {
+		t.Fatal(err)
+	}
+	if err := metrics.ConsumeMetrics(context.Background(), gaugeMetrics("m")); err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	start := time.Now()
+	for i := 0; i < 2; i++ {
+		if err := metrics.Shutdown(ctx); err != nil {
+			t.Fatalf("Shutdown %d: %v", i+1, err)
+		}
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("Shutdown tardó %s", elapsed)
+	}
+	if got := countPath(srv.received(), "/metrics"); got != 1 {
+		t.Errorf("peticiones = %d, el lote encolado debía enviarse al apagar", got)
+	}
+}
+
+func TestShutdownKeepsDefaultTransport(t *testing.T) {
+	exp := newTestExporter(t, testConfig(t, "http://localhost"))
+	if exp.client.Transport == http.DefaultTransport {
+		t.Error("el exporter no debe compartir http.DefaultTransport")
+	}
+	if err := exp.shutdown(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,69 @@
+package opentelemetryexportermonitoring
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/hex"
+	"fmt"
+	"hash"
+	"net/http"
+
+	"go.opentelemetry.io/collector/config/configopaque"
+)
+
+// SigningConfig firma el body de cada petición con HMAC y un secreto
+// compartido. Se activa al indicar Secret, que puede venir de una variable de
+// entorno con ${env:NOMBRE} y nunca se registra
+type SigningConfig struct {
+	Secret configopaque.String `mapstructure:"secret"`
+	// Cabecera con la firma en hex (por defecto X-Signature)
+	Header string `mapstructure:"header"`
+	// "sha256" (por defecto) o "sha512"
+	Algorithm string `mapstructure:"algorithm"`
+}
+
+// signingHashes son los algoritmos HMAC soportados
+var signingHashes = map[string]func() hash.Hash{
+	"sha256": sha256.New,
+	"sha512": sha512.New,
+}
+
+// validate comprueba el bloque signing; los errores no incluyen el secreto
+func (s SigningConfig) validate() error {
+	if s.Secret == "" {
+		return nil
+	}
+	if _, ok := signingHashes[s.Algorithm]; !ok {
+		return fmt.Errorf("signing.algorithm: no soportado: %q (sha256 o sha512)", s.Algorithm)
+	}
+	if s.Header == "" {
+		return fmt.Errorf("signing.header: no puede estar vacío")
+	}
+	return nil
+}
+
+// hmacSigner calcula la firma HMAC del payload final (ya comprimido y cifrado)
+type hmacSigner struct {
+	secret []byte
+	header string
+	hash   func() hash.Hash
+}
+
+// newHMACSigner devuelve nil si signing no está configurado. Vuelve a validar
+// el bloque: con un algoritmo desconocido hmac.New recibiría una función nil
+func newHMACSigner(cfg SigningConfig) (*hmacSigner, error) {
+	if cfg.Secret == "" {
+		return nil, nil
+	}
+	if err := cfg.validate(); err != nil {
+		return nil, err
+	}
+	return &hmacSigner{secret: []byte(string(cfg.Secret)), header: cfg.Header, hash: signingHashes[cfg.Algorithm]}, nil
+}
+
+func (s *hmacSigner) sign(req *http.Request, payload []byte) {
+	mac := hmac.New(s.hash, s.secret)
+	mac.Write(payload)
+	req.Header.Set(s.header, hex.EncodeToString(mac.Sum(nil)))
+}
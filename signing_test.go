@@ -0,0 +1,62 @@
+package opentelemetryexportermonitoring
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/hex"
+	"hash"
+	"strings"
+	"testing"
+
+	"go.opentelemetry.io/collector/config/configopaque"
+)
+
+func TestHMACSignatureMatchesIndependentHMAC(t *testing.T) {
+	const secret = "shared-secret"
+	tests := []struct {
+		algorithm   string
+		compression string
+		hash        func() hash.Hash
+	}{
+		{"sha256", compressionNone, sha256.New},
+		{"sha256", compressionGzip, sha256.New},
+		{"sha512", compressionNone, sha512.New},
+	}
+	for _, tt := range tests {
+		t.Run(tt.algorithm+"/"+orDefault(tt.compression, "none"), func(t *testing.T) {
+			srv := newCaptureServer(t)
+			cfg := testConfig(t, srv.URL)
+			cfg.Compression = tt.compression
+			cfg.Signing = SigningConfig{Secret: configopaque.String(secret), Header: "X-Signature", Algorithm: tt.algorithm}
+			if err := newTestExporter(t, cfg).pushMetrics(context.Background(), gaugeMetrics("m")); err != nil {
+				t.Fatal(err)
+			}
+			reqs := srv.received()
+			if len(reqs) != 1 {
+				t.Fatalf("peticiones = %d, se esperaba 1", len(reqs))
+			}
+			// La firma cubre el body tal como viaja, ya comprimido
+			mac := hmac.New(tt.hash, []byte(secret))
+			mac.Write(reqs[0].Body)
+			if got, want := reqs[0].Header.Get("X-Signature"), hex.EncodeToString(mac.Sum(nil)); got != want {
+				t.Errorf("X-Signature = %s, se esperaba %s", got, want)
+			}
+		})
+	}
+}
+
+func TestHMACSignerRejectsUnknownAlgorithm(t *testing.T) {
+	cfg := testConfig(t, "http://localhost")
+	cfg.Signing = SigningConfig{Secret: "s3cr3t", Header: "X-Signature", Algorithm: "md5"}
+	validateError(t, cfg, "signing.algorithm")
+	// Sin pasar por Validate tampoco debe llegar a hmac.New
+	_, err := newMonitoringExporter(cfg, nil)
+	if err == nil {
+		t.Fatal("se esperaba un error con un algoritmo no soportado")
+	}
+	if strings.Contains(err.Error(), "s3cr3t") {
+		t.Errorf("el error incluye el secreto: %v", err)
+	}
+}
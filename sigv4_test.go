@@ -0,0 +1,172 @@
+package opentelemetryexportermonitoring
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+// Credenciales y fecha de la suite de pruebas de AWS Signature V4
+const (
+	sigv4TestKeyID  = "AKIDEXAMPLE"
+	sigv4TestSecret = "wJalrXUtnFEMI/K7MDENG+bPxRfiCYEXAMPLEKEY"
+)
+
+var sigv4TestTime = time.Date(2015, 8, 30, 12, 36, 0, 0, time.UTC)
+
+func hmacSHA256(key []byte, data string) []byte {
+	h := hmac.New(sha256.New, key)
+	h.Write([]byte(data))
+	return h.Sum(nil)
+}
+
+func sha256Hex(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+// referenceSignature calcula la firma SigV4 según la especificación, firmando
+// las cabeceras headers (en minúsculas) de req
+func referenceSignature(req *http.Request, headers []string, payloadHash, region, service string) string {
+	sort.Strings(headers)
+	var canonicalHeaders strings.Builder
+	for _, h := range headers {
+		value := req.Header.Get(h)
+		switch h {
+		case "host":
+			value = req.Host
+		case "content-length":
+			value = strconv.FormatInt(req.ContentLength, 10)
+		}
+		canonicalHeaders.WriteString(h + ":" + strings.TrimSpace(value) + "\n")
+	}
+	path := req.URL.EscapedPath()
+	if path == "" {
+		path = "/"
+	}
+	canonicalRequest := strings.Join([]string{
+		req.Method, path, req.URL.RawQuery, canonicalHeaders.String(), strings.Join(headers, ";"), payloadHash,
+	}, "\n")
+
+	date := sigv4TestTime.Format("20060102")
+	scope := date + "/" + region + "/" + service + "/aws4_request"
+	stringToSign := strings.Join([]string{
+		"AWS4-HMAC-SHA256", sigv4TestTime.Format("20060102T150405Z"), scope, sha256Hex([]byte(canonicalRequest)),
+	}, "\n")
+
+	key := hmacSHA256([]byte("AWS4"+sigv4TestSecret), date)
+	key = hmacSHA256(key, region)
+	key = hmacSHA256(key, service)
+	key = hmacSHA256(key, "aws4_request")
+	return hex.EncodeToString(hmacSHA256(key, stringToSign))
+}
+
+func TestReferenceSignatureMatchesAWSVector(t *testing.T) {
+	// get-vanilla de la suite de AWS
+	req, _ := http.NewRequest(http.MethodGet, "https://example.amazonaws.com/", nil)
+	req.Header.Set("X-Amz-Date", "20150830T123600Z")
+	got := referenceSignature(req, []string{"host", "x-amz-date"}, sha256Hex(nil), "us-east-1", "service")
+	if want := "5fa00fa31553b73ebf1942676e86291e8372ff2a2260956d9b8aae1d763fbf31"; got != want {
+		t.Fatalf("firma de referencia = %s, se esperaba %s", got, want)
+	}
+}
+
+func TestSigV4KnownVector(t *testing.T) {
+	signer, err := newSigV4Signer(SigV4Config{
+		Region:          "us-east-1",
+		Service:         "service",
+		AccessKeyID:     sigv4TestKeyID,
+		SecretAccessKey: sigv4TestSecret,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	signer.now = func() time.Time { return sigv4TestTime }
+
+	body := []byte(`{"metrics":[]}`)
+	req, _ := http.NewRequest(http.MethodPost, "https://example.amazonaws.com/v0/ns/a/metrics", bytes.NewReader(body))
+	if err := signer.sign(context.Background(), req, body); err != nil {
+		t.Fatal(err)
+	}
+
+	if got := req.Header.Get("X-Amz-Content-Sha256"); got != sha256Hex(body) {
+		t.Errorf("X-Amz-Content-Sha256 = %s", got)
+	}
+	if got := req.Header.Get("X-Amz-Date"); got != "20150830T123600Z" {
+		t.Errorf("X-Amz-Date = %s", got)
+	}
+	signed := []string{"content-length", "host", "x-amz-content-sha256", "x-amz-date"}
+	want := "AWS4-HMAC-SHA256 Credential=AKIDEXAMPLE/20150830/us-east-1/service/aws4_request, " +
+		"SignedHeaders=content-length;host;x-amz-content-sha256;x-amz-date, " +
+		"Signature=" + referenceSignature(req, signed, sha256Hex(body), "us-east-1", "service")
+	if got := req.Header.Get("Authorization"); got != want {
+		t.Errorf("Authorization =\n%s\nse esperaba\n%s", got, want)
+	}
+}
+
+func TestSigV4SessionToken(t *testing.T) {
+	signer, err := newSigV4Signer(SigV4Config{
+		Region: "eu-west-1", Service: "execute-api",
+		AccessKeyID: sigv4TestKeyID, SecretAccessKey: sigv4TestSecret, SessionToken: "token",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	req, _ := http.NewRequest(http.MethodPost, "https://example.amazonaws.com/", nil)
+	if err := signer.sign(context.Background(), req, nil); err != nil {
+		t.Fatal(err)
+	}
+	if req.Header.Get("X-Amz-Security-Token") != "token" {
+		t.Error("falta X-Amz-Security-Token con credenciales temporales")
+	}
+	if !strings.Contains(req.Header.Get("Authorization"), "x-amz-security-token") {
+		t.Errorf("el token no está firmado: %s", req.Header.Get("Authorization"))
+	}
+}
+
+func TestSigV4ConfigErrors(t *testing.T) {
+	if _, err := newSigV4Signer(SigV4Config{Service: "execute-api"}); err == nil {
+		t.Error("se esperaba un error sin region")
+	}
+	if _, err := newSigV4Signer(SigV4Config{Region: "eu-west-1", Service: "s", AccessKeyID: "a"}); err == nil {
+		t.Error("se esperaba un error con access_key_id sin secret_access_key")
+	}
+}
+
+func TestSigV4SecretsRedacted(t *testing.T) {
+	cfg := testConfig(t, "http://localhost")
+	cfg.SigV4 = SigV4Config{Region: "eu-west-1", Service: "execute-api", AccessKeyID: sigv4TestKeyID, SecretAccessKey: sigv4TestSecret, SessionToken: "token-secreto"}
+	data, err := json.Marshal(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, secret := range []string{sigv4TestSecret, "token-secreto"} {
+		for _, out := range []string{fmt.Sprintf("%v", cfg.SigV4), fmt.Sprintf("%#v", cfg.SigV4), string(data)} {
+			if strings.Contains(out, secret) {
+				t.Errorf("la config muestra un secreto de sigv4: %s", out)
+			}
+		}
+	}
+	// El firmante sigue recibiendo los valores reales
+	signer, err := newSigV4Signer(cfg.SigV4)
+	if err != nil {
+		t.Fatal(err)
+	}
+	creds, err := signer.credentials.Retrieve(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if creds.SecretAccessKey != sigv4TestSecret || creds.SessionToken != "token-secreto" {
+		t.Errorf("credenciales = %+v", creds)
+	}
+}
@@ -0,0 +1,131 @@
+package opentelemetryexportermonitoring
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"go.opentelemetry.io/collector/exporter"
+	"go.uber.org/zap"
+)
+
+// SinkConfig es un destino adicional con su propio endpoint, codificación y
+// cabeceras. Con sinks configurados cada push se envía a todos los que
+// acepten la señal, en lugar de a las URLs por defecto
+type SinkConfig struct {
+	// Nombre del sink para logs y errores
+	Name string `mapstructure:"name"`
+	// Endpoint (admite los marcadores de las *_url_template)
+	Endpoint string `mapstructure:"endpoint"`
+	// Codificación para todas las señales del sink (por defecto la de cada señal)
+	Encoding string `mapstructure:"encoding"`
+	// Cabeceras que se añaden a (o sustituyen en) headers
+	Headers map[string]string `mapstructure:"headers"`
+	// Señales que acepta ("traces", "metrics", "logs"); vacío = todas
+	Signals []string `mapstructure:"signals"`
+}
+
+// accepts indica si el sink recibe la señal
+func (s SinkConfig) accepts(signal string) bool {
+	if len(s.Signals) == 0 {
+		return true
+	}
+	for _, sig := range s.Signals {
+		if sig == signal {
+			return true
+		}
+	}
+	return false
+}
+
+// sinkExporter es el exporter de un sink, construido con la configuración
+// principal más las sustituciones del sink
+type sinkExporter[T any] struct {
+	name string
+	push func(context.Context, T) error
+}
+
+// sinkPipeline prepara el exporter y la función de envío de una señal
+// (newTracesPipeline, newMetricsPipeline o newLogsPipeline)
+type sinkPipeline[T any] func(*Config, exporter.Settings) (*monitoringExporter, func(context.Context, T) error, error)
+
+// newSinks crea un exporter por cada sink que acepta signal. Devuelve el
+// exporter principal, que solo lleva la autotelemetría y el ciclo de vida de
+// los sinks, y la función que reparte cada push entre todos ellos
+func newSinks[T any](c *Config, set exporter.Settings, signal string, pipeline sinkPipeline[T]) (*monitoringExporter, func(context.Context, T) error, error) {
+	exp, err := newMonitoringExporter(c, set.Logger)
+	if err != nil {
+		return nil, nil, err
+	}
+	if exp.telemetry, err = newSelfTelemetry(set.MeterProvider, signal); err != nil {
+		return nil, nil, err
+	}
+
+	var sinks []sinkExporter[T]
+	for i, sink := range c.Sinks {
+		name := sink.Name
+		if name == "" {
+			name = fmt.Sprintf("sink[%d]", i)
+		}
+		if !sink.accepts(signal) {
+			continue
+		}
+		if sink.Endpoint == "" {
+			return nil, nil, fmt.Errorf("sinks %s: endpoint es obligatorio", name)
+		}
+
+		sinkSet := set
+		sinkSet.Logger = set.Logger.With(zap.String("sink", name))
+		sinkExp, push, err := pipeline(sinkConfig(c, sink), sinkSet)
+		if err != nil {
+			return nil, nil, fmt.Errorf("sinks %s: %w", name, err)
+		}
+		// Las paradas del sink vacían sus buffers y su coalescer y cierran
+		// sus conexiones
+		exp.addLifecycle(sinkExp.start, sinkExp.shutdown)
+		sinks = append(sinks, sinkExporter[T]{name: name, push: push})
+	}
+	return exp, fanOut(sinks), nil
+}
+
+// sinkConfig es la configuración principal con el endpoint, la codificación y
+// las cabeceras del sink. Las cabeceras del sink prevalecen sobre las
+// globales y sobre las de cada señal
+func sinkConfig(c *Config, sink SinkConfig) *Config {
+	sinkCfg := *c
+	sinkCfg.Sinks = nil
+	sinkCfg.TracesURLTemplate = sink.Endpoint
+	sinkCfg.MetricsURLTemplate = sink.Endpoint
+	sinkCfg.LogsURLTemplate = sink.Endpoint
+	// Las codificaciones propias de una señal (loki, zipkin_json...) solo
+	// se aplican a esa señal
+	if validEncoding("traces", sink.Encoding) && sink.Encoding != "" {
+		sinkCfg.TracesEncoding = sink.Encoding
+	}
+	if validEncoding("metrics", sink.Encoding) && sink.Encoding != "" {
+		sinkCfg.MetricsEncoding = sink.Encoding
+	}
+	if validEncoding("logs", sink.Encoding) && sink.Encoding != "" {
+		sinkCfg.LogsEncoding = sink.Encoding
+	}
+	sinkCfg.Headers = mergeHeaders(c.Headers, sink.Headers)
+	sinkCfg.TracesHeaders = mergeHeaders(c.TracesHeaders, sink.Headers)
+	sinkCfg.MetricsHeaders = mergeHeaders(c.MetricsHeaders, sink.Headers)
+	sinkCfg.LogsHeaders = mergeHeaders(c.LogsHeaders, sink.Headers)
+	return &sinkCfg
+}
+
+// fanOut envía los datos a todos los sinks; un sink que falla no impide el
+// envío a los demás y los errores se devuelven juntos. Al reintentar el
+// exporterhelper se reenvía también a los sinks que ya lo recibieron
+func fanOut[T any](sinks []sinkExporter[T]) func(context.Context, T) error {
+	return func(ctx context.Context, data T) error {
+		var errs []error
+		for _, s := range sinks {
+			if err := s.push(ctx, data); err != nil {
+				errs = append(errs, fmt.Errorf("sink %s: %w", s.name, err))
+			}
+		}
+		return errors.Join(errs...)
+	}
+}
@@ -0,0 +1,91 @@
+package opentelemetryexportermonitoring
+
+import (
+	"context"
+	"net/http"
+	"testing"
+)
+
+func TestSinksFanOutTwoEncodings(t *testing.T) {
+	otlpSink, jsonSink := newCaptureServer(t), newCaptureServer(t)
+	cfg := testConfig(t, "http://localhost")
+	cfg.MetricsMethod = http.MethodPut
+	cfg.MetricsHeaders = map[string]string{"X-Signal": "metrics", "X-Team": "global"}
+	cfg.StartupProbe = true
+	cfg.Sinks = []SinkConfig{
+		{Name: "analytics", Endpoint: otlpSink.URL + "/otlp", Encoding: encodingOTLPJSON, Headers: map[string]string{"X-Team": "analytics"}},
+		{Name: "ops", Endpoint: jsonSink.URL + "/ops", Signals: []string{"metrics"}},
+	}
+	_, metrics, _ := newFactoryExporters(t, cfg)
+
+	// El arranque de cada exporter arranca también sus sinks: analytics
+	// acepta las tres señales y ops solo las métricas
+	probes := map[*captureServer]int{otlpSink: 3, jsonSink: 1}
+	for srv, want := range probes {
+		reqs := srv.received()
+		if len(reqs) != want {
+			t.Fatalf("%s: %d peticiones al arrancar, se esperaban %d", srv.URL, len(reqs), want)
+		}
+		for _, req := range reqs {
+			if req.Method != http.MethodHead {
+				t.Errorf("%s: %s al arrancar, se esperaba el HEAD del startup probe", srv.URL, req.Method)
+			}
+		}
+	}
+
+	if err := metrics.ConsumeMetrics(context.Background(), gaugeMetrics("cpu.usage")); err != nil {
+		t.Fatal(err)
+	}
+
+	otlpReqs, jsonReqs := otlpSink.received()[probes[otlpSink]:], jsonSink.received()[probes[jsonSink]:]
+	if len(otlpReqs) != 1 || len(jsonReqs) != 1 {
+		t.Fatalf("peticiones = %d y %d, se esperaba una por sink", len(otlpReqs), len(jsonReqs))
+	}
+	var otlp struct {
+		ResourceMetrics []interface{} `json:"resourceMetrics"`
+	}
+	decodeJSON(t, otlpReqs[0].Body, &otlp)
+	if len(otlp.ResourceMetrics) != 1 {
+		t.Errorf("el sink otlp_json no recibió OTLP/JSON: %s", otlpReqs[0].Body)
+	}
+	var records struct {
+		Metrics []transformedMetric `json:"metrics"`
+	}
+	decodeJSON(t, jsonReqs[0].Body, &records)
+	if len(records.Metrics) != 1 || records.Metrics[0].Values["cpu.usage"] == nil {
+		t.Errorf("el sink json no recibió los registros propios: %s", jsonReqs[0].Body)
+	}
+
+	// Cada sink hereda el método y las cabeceras de la señal; las del sink prevalecen
+	for _, tt := range []struct {
+		req  capturedRequest
+		team string
+	}{{otlpReqs[0], "analytics"}, {jsonReqs[0], "global"}} {
+		if tt.req.Method != http.MethodPut {
+			t.Errorf("%s: método = %s, se esperaba metrics_method", tt.req.Path, tt.req.Method)
+		}
+		if got := tt.req.Header.Get("X-Signal"); got != "metrics" {
+			t.Errorf("%s: X-Signal = %q", tt.req.Path, got)
+		}
+		if got := tt.req.Header.Get("X-Team"); got != tt.team {
+			t.Errorf("%s: X-Team = %q, se esperaba %q", tt.req.Path, got, tt.team)
+		}
+	}
+}
+
+func TestSinksFailIndependently(t *testing.T) {
+	failing, ok := newCaptureServer(t), newCaptureServer(t)
+	failing.respond(func(w http.ResponseWriter, r *http.Request) { w.WriteHeader(http.StatusBadGateway) })
+	cfg := testConfig(t, "http://localhost")
+	cfg.Sinks = []SinkConfig{
+		{Name: "roto", Endpoint: failing.URL + "/a"},
+		{Name: "sano", Endpoint: ok.URL + "/b"},
+	}
+	_, metrics, _ := newFactoryExporters(t, cfg)
+	if err := metrics.ConsumeMetrics(context.Background(), gaugeMetrics("m")); err == nil {
+		t.Error("se esperaba el error del sink roto")
+	}
+	if got := len(ok.received()); got != 1 {
+		t.Errorf("el sink sano recibió %d peticiones, se esperaba 1", got)
+	}
+}
@@ -0,0 +1,47 @@
+package opentelemetryexportermonitoring
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"go.opentelemetry.io/collector/component"
+	"go.uber.org/zap"
+)
+
+// startupProbeTimeout limita lo que el probe puede retrasar el arranque
+const startupProbeTimeout = 5 * time.Second
+
+// startupProbe devuelve el arranque que comprueba con un HEAD que el host de
+// endpoint responde. Cualquier respuesta HTTP cuenta como alcanzable; si no
+// hay conexión solo se avisa, el arranque no falla
+func (m *monitoringExporter) startupProbe(endpoint string) component.StartFunc {
+	return func(ctx context.Context, _ component.Host) error {
+		u, err := url.Parse(endpoint)
+		if err != nil || u.Host == "" || strings.HasSuffix(u.Host, ".") {
+			// Sin endpoint configurado (p. ej. region vacía) no hay nada que comprobar
+			return nil
+		}
+		base := u.Scheme + "://" + u.Host + "/"
+
+		ctx, cancel := context.WithTimeout(ctx, startupProbeTimeout)
+		defer cancel()
+		req, err := http.NewRequestWithContext(ctx, http.MethodHead, base, nil)
+		if err != nil {
+			return nil
+		}
+		for k, v := range m.headers {
+			req.Header.Set(k, v)
+		}
+		resp, err := m.client.Do(req)
+		if err != nil {
+			m.logger.Warn("endpoint no alcanzable al arrancar", zap.String("url", base), zap.Error(err))
+			return nil
+		}
+		resp.Body.Close()
+		m.logger.Debug("startup probe OK", zap.String("url", base), zap.Int("status", resp.StatusCode))
+		return nil
+	}
+}
@@ -0,0 +1,101 @@
+package opentelemetryexportermonitoring
+
+import (
+	"bytes"
+	"encoding/json"
+	"strconv"
+	"time"
+)
+
+// Formatos de timestamp del JSON propio
+const (
+	timestampUnixNano   = "unix_nano" // por defecto
+	timestampUnixMillis = "unix_millis"
+	timestampRFC3339    = "rfc3339"
+)
+
+// timestampKeys son los campos en nanosegundos de los registros propios
+// (spans, métricas, logs, exemplars y eventos)
+var timestampKeys = map[string]bool{
+	"startDate":    true,
+	"finishDate":   true,
+	"timestamp":    true,
+	"creationDate": true,
+}
+
+// timestampContainerKeys son los campos del esquema propio que contienen
+// registros con timestamps. Solo se baja por ellos: el resto (properties,
+// data_point_attributes_key, bodies JSON...) son datos del usuario y no se
+// tocan aunque tengan claves como "timestamp"
+var timestampContainerKeys = map[string]bool{
+	"traces":        true,
+	"metrics":       true,
+	"spans":         true,
+	"logs":          true,
+	"events":        true,
+	"exemplars":     true,
+	"log_templates": true,
+	"examples":      true,
+}
+
+// formatTimestamps reescribe los timestamps de body según timestamp_format.
+// Con unix_nano el body no cambia; un timestamp 0 pasa a null
+func (m *monitoringExporter) formatTimestamps(body []byte) ([]byte, error) {
+	if m.timestampFormat == "" || m.timestampFormat == timestampUnixNano {
+		return body, nil
+	}
+	dec := json.NewDecoder(bytes.NewReader(body))
+	dec.UseNumber()
+	var v interface{}
+	if err := dec.Decode(&v); err != nil {
+		return nil, err
+	}
+	return json.Marshal(m.rewriteTimestamps(v))
+}
+
+// rewriteTimestamps reescribe un registro, un array de registros o un
+// payload que los envuelve
+func (m *monitoringExporter) rewriteTimestamps(v interface{}) interface{} {
+	switch t := v.(type) {
+	case map[string]interface{}:
+		for k, child := range t {
+			switch {
+			case timestampKeys[k]:
+				if n, ok := child.(json.Number); ok {
+					t[k] = m.formatTimestamp(n)
+				}
+			case timestampContainerKeys[k]:
+				t[k] = m.rewriteTimestamps(child)
+			}
+		}
+	case []interface{}:
+		for i, child := range t {
+			t[i] = m.rewriteTimestamps(child)
+		}
+	}
+	return v
+}
+
+// formatTimestamp convierte un timestamp en ns al formato configurado
+func (m *monitoringExporter) formatTimestamp(n json.Number) interface{} {
+	ns, err := strconv.ParseInt(string(n), 10, 64)
+	if err != nil {
+		return n
+	}
+	if ns == 0 {
+		return nil
+	}
+	if m.timestampFormat == timestampUnixMillis {
+		return ns / int64(time.Millisecond)
+	}
+	return time.Unix(0, ns).In(m.location).Format(time.RFC3339Nano)
+}
+
+// marshalRecords serializa un payload propio aplicando timestamp_format
+func (m *monitoringExporter) marshalRecords(v interface{}) ([]byte, error) {
+	body, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	return m.formatTimestamps(body)
+}
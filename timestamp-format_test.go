@@ -0,0 +1,139 @@
+package opentelemetryexportermonitoring
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestRFC3339TimestampTimezone(t *testing.T) {
+	ts := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	tests := []struct {
+		timezone string
+		want     string
+	}{
+		{"", "2024-01-02T03:04:05Z"},
+		{"UTC", "2024-01-02T03:04:05Z"},
+		{"Asia/Kolkata", "2024-01-02T08:34:05+05:30"},
+		{"America/New_York", "2024-01-01T22:04:05-05:00"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.timezone, func(t *testing.T) {
+			cfg := testConfig(t, "http://localhost")
+			cfg.TimestampFormat = timestampRFC3339
+			cfg.TimestampTimezone = tt.timezone
+			exp := newTestExporter(t, cfg)
+
+			body, err := exp.marshalRecords(map[string]interface{}{"timestamp": ts.UnixNano()})
+			if err != nil {
+				t.Fatal(err)
+			}
+			var got struct {
+				Timestamp string `json:"timestamp"`
+			}
+			decodeJSON(t, body, &got)
+			if got.Timestamp != tt.want {
+				t.Errorf("timestamp = %s, se esperaba %s", got.Timestamp, tt.want)
+			}
+			if parsed, err := time.Parse(time.RFC3339Nano, got.Timestamp); err != nil || !parsed.Equal(ts) {
+				t.Errorf("%s no representa el mismo instante (%v)", got.Timestamp, err)
+			}
+		})
+	}
+}
+
+func TestTimestampTimezoneInvalid(t *testing.T) {
+	cfg := testConfig(t, "http://localhost")
+	cfg.TimestampTimezone = "Mars/Olympus"
+	validateError(t, cfg, "timestamp_timezone")
+	if _, err := newMonitoringExporter(cfg, nil); err == nil {
+		t.Error("se esperaba un error con una zona horaria inexistente")
+	}
+}
+
+func TestTimestampFormats(t *testing.T) {
+	const ns = 1_700_000_000_123_456_789
+	tests := []struct {
+		format string
+		want   interface{}
+	}{
+		{timestampUnixNano, float64(ns)},
+		{timestampUnixMillis, float64(ns / int64(time.Millisecond))},
+		{timestampRFC3339, "2023-11-14T22:13:20.123456789Z"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.format, func(t *testing.T) {
+			srv := newCaptureServer(t)
+			cfg := testConfig(t, srv.URL)
+			cfg.TimestampFormat = tt.format
+			cfg.DataPointAttributesKey = "labels"
+			cfg.ParseJSONLogBodies = true
+			exp := newTestExporter(t, cfg)
+			ctx := context.Background()
+
+			td := testTraces("svc", "op")
+			sp := td.ResourceSpans().At(0).ScopeSpans().At(0).Spans().At(0)
+			sp.SetStartTimestamp(ns)
+			sp.SetEndTimestamp(0)
+			sp.Attributes().PutInt("timestamp", 5)
+			md := gaugeMetrics("m")
+			dp := md.ResourceMetrics().At(0).ScopeMetrics().At(0).Metrics().At(0).Gauge().DataPoints().At(0)
+			dp.SetTimestamp(ns)
+			dp.Attributes().PutInt("timestamp", 5)
+			ld := logsWithResources(1, "svc")
+			lr := ld.ResourceLogs().At(0).ScopeLogs().At(0).LogRecords().At(0)
+			lr.SetTimestamp(ns)
+			lr.Body().SetStr(`{"timestamp":5}`)
+
+			if err := exp.pushTraces(ctx, td); err != nil {
+				t.Fatal(err)
+			}
+			if err := exp.pushMetrics(ctx, md); err != nil {
+				t.Fatal(err)
+			}
+			if err := exp.pushLogs(ctx, ld); err != nil {
+				t.Fatal(err)
+			}
+			bodies := map[string][]byte{}
+			for _, req := range srv.received() {
+				bodies[req.Path] = req.Body
+			}
+
+			var spans []map[string]interface{}
+			decodeJSON(t, bodies["/traces"], &spans)
+			if spans[0]["startDate"] != tt.want {
+				t.Errorf("startDate = %v, se esperaba %v", spans[0]["startDate"], tt.want)
+			}
+			wantZero := interface{}(nil)
+			if tt.format == timestampUnixNano {
+				wantZero = float64(0)
+			}
+			if spans[0]["finishDate"] != wantZero {
+				t.Errorf("finishDate 0 = %v, se esperaba %v", spans[0]["finishDate"], wantZero)
+			}
+			if props := spans[0]["properties"].(map[string]interface{}); props["timestamp"] != float64(5) {
+				t.Errorf("se reescribió un atributo del span: %v", props)
+			}
+
+			var metrics struct {
+				Metrics []map[string]interface{} `json:"metrics"`
+			}
+			decodeJSON(t, bodies["/metrics"], &metrics)
+			if metrics.Metrics[0]["timestamp"] != tt.want {
+				t.Errorf("timestamp = %v, se esperaba %v", metrics.Metrics[0]["timestamp"], tt.want)
+			}
+			if labels := metrics.Metrics[0]["labels"].(map[string]interface{}); labels["timestamp"] != float64(5) {
+				t.Errorf("se reescribió un atributo bajo data_point_attributes_key: %v", labels)
+			}
+
+			var logs []map[string]interface{}
+			decodeJSON(t, bodies["/logs"], &logs)
+			if logs[0]["creationDate"] != tt.want {
+				t.Errorf("creationDate = %v, se esperaba %v", logs[0]["creationDate"], tt.want)
+			}
+			if message, _ := logs[0]["message"].(map[string]interface{}); message["timestamp"] != float64(5) {
+				t.Errorf("se reescribió el body JSON del log: %v", logs[0]["message"])
+			}
+		})
+	}
+}
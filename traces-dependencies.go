@@ -0,0 +1,143 @@
+package opentelemetryexportermonitoring
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	"go.opentelemetry.io/collector/pdata/ptrace"
+)
+
+// peerServiceKeys son los atributos de un span cliente que nombran al
+// servicio llamado cuando su span servidor no viene en el lote
+var peerServiceKeys = []string{"peer.service", "server.address", "net.peer.name"}
+
+// dependencyEdge es una arista caller -> callee del mapa de servicios
+type dependencyEdge struct {
+	Caller string `json:"caller"`
+	Callee string `json:"callee"`
+	Calls  int64  `json:"calls"`
+	Errors int64  `json:"errors"`
+}
+
+// edgeSpan es lo que se recuerda de cada span para enlazarlo con sus hijos
+type edgeSpan struct {
+	service string
+	kind    ptrace.SpanKind
+	isError bool
+	peer    string
+	matched bool
+}
+
+// isCaller indica si el span representa una llamada saliente
+func (s *edgeSpan) isCaller() bool {
+	return s.kind == ptrace.SpanKindClient || s.kind == ptrace.SpanKindProducer
+}
+
+// transformDependencyEdges deriva las aristas del lote: un span servidor o
+// consumidor cuyo padre es un span cliente o productor de otro servicio da
+// la arista (servicio del padre, servicio del hijo). Los spans cliente sin
+// hijo en el lote usan peer.service, server.address o net.peer.name
+func transformDependencyEdges(td ptrace.Traces) []dependencyEdge {
+	spansByID := make(map[pcommon.SpanID]*edgeSpan)
+	type child struct {
+		parent pcommon.SpanID
+		span   *edgeSpan
+	}
+	var callees []child
+	var callers []*edgeSpan
+
+	resourceSpans := td.ResourceSpans()
+	for i := 0; i < resourceSpans.Len(); i++ {
+		service := getAttrString(resourceSpans.At(i).Resource().Attributes(), "service.name")
+		scopeSpans := resourceSpans.At(i).ScopeSpans()
+		for j := 0; j < scopeSpans.Len(); j++ {
+			spans := scopeSpans.At(j).Spans()
+			for k := 0; k < spans.Len(); k++ {
+				span := spans.At(k)
+				s := &edgeSpan{
+					service: service,
+					kind:    span.Kind(),
+					isError: span.Status().Code() == ptrace.StatusCodeError,
+				}
+				for _, key := range peerServiceKeys {
+					if s.peer = getAttrString(span.Attributes(), key); s.peer != "" {
+						break
+					}
+				}
+				spansByID[span.SpanID()] = s
+				switch {
+				case s.isCaller():
+					callers = append(callers, s)
+				case s.kind == ptrace.SpanKindServer || s.kind == ptrace.SpanKindConsumer:
+					if !span.ParentSpanID().IsEmpty() {
+						callees = append(callees, child{parent: span.ParentSpanID(), span: s})
+					}
+				}
+			}
+		}
+	}
+
+	var edges []dependencyEdge
+	index := make(map[[2]string]int)
+	add := func(caller, callee string, isError bool) {
+		if caller == "" || callee == "" || caller == callee {
+			return
+		}
+		key := [2]string{caller, callee}
+		i, ok := index[key]
+		if !ok {
+			i = len(edges)
+			index[key] = i
+			edges = append(edges, dependencyEdge{Caller: caller, Callee: callee})
+		}
+		edges[i].Calls++
+		if isError {
+			edges[i].Errors++
+		}
+	}
+
+	for _, c := range callees {
+		parent, ok := spansByID[c.parent]
+		if !ok || !parent.isCaller() {
+			continue
+		}
+		parent.matched = true
+		add(parent.service, c.span.service, c.span.isError)
+	}
+	for _, s := range callers {
+		if !s.matched {
+			add(s.service, s.peer, s.isError)
+		}
+	}
+	return edges
+}
+
+// pushDependencyEdges envía las aristas del lote a dependency_endpoint
+func (m *monitoringExporter) pushDependencyEdges(ctx context.Context, td ptrace.Traces) error {
+	edges := transformDependencyEdges(td)
+	if len(edges) == 0 {
+		return nil
+	}
+	ctx, batchID := m.withBatchID(ctx)
+	bodies, err := splitPayload(m, edges, func(edges []dependencyEdge) ([]byte, error) {
+		payload := map[string]interface{}{"edges": edges}
+		if batchID != "" {
+			payload["batchId"] = batchID
+		}
+		if m.collectorHost != "" {
+			payload["collector_host"] = m.collectorHost
+		}
+		return json.Marshal(payload)
+	})
+	if err != nil {
+		return fmt.Errorf("error marshaling dependency edges: %w", err)
+	}
+	for _, body := range bodies {
+		if err := m.postJSON(ctx, m.dependencyEndpoint, body); err != nil {
+			return fmt.Errorf("error sending dependency edges to URL %s: %w", m.dependencyEndpoint, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,143 @@
+package opentelemetryexportermonitoring
+
+import (
+	"context"
+	"net/http"
+	"reflect"
+	"testing"
+
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	"go.opentelemetry.io/collector/pdata/ptrace"
+)
+
+// dependencyTraces tiene una llamada frontend -> checkout con su span servidor
+// (con error) y otra de checkout a "payments" que solo conoce peer.service
+func dependencyTraces() ptrace.Traces {
+	td := ptrace.NewTraces()
+	addSpan := func(service string, kind ptrace.SpanKind, id, parent byte) ptrace.Span {
+		rs := td.ResourceSpans().AppendEmpty()
+		rs.Resource().Attributes().PutStr("service.name", service)
+		span := rs.ScopeSpans().AppendEmpty().Spans().AppendEmpty()
+		span.SetName(service)
+		span.SetKind(kind)
+		span.SetTraceID(pcommon.TraceID{1})
+		span.SetSpanID(pcommon.SpanID{id})
+		if parent != 0 {
+			span.SetParentSpanID(pcommon.SpanID{parent})
+		}
+		return span
+	}
+	addSpan("frontend", ptrace.SpanKindClient, 1, 0)
+	addSpan("checkout", ptrace.SpanKindServer, 2, 1).Status().SetCode(ptrace.StatusCodeError)
+	addSpan("checkout", ptrace.SpanKindClient, 3, 2).Attributes().PutStr("peer.service", "payments")
+	return td
+}
+
+var wantDependencyEdges = []dependencyEdge{
+	{Caller: "frontend", Callee: "checkout", Calls: 1, Errors: 1},
+	{Caller: "checkout", Callee: "payments", Calls: 1},
+}
+
+func TestDependencyEdgesClientServerPair(t *testing.T) {
+	if got := transformDependencyEdges(dependencyTraces()); !reflect.DeepEqual(got, wantDependencyEdges) {
+		t.Errorf("aristas = %+v, se esperaba %+v", got, wantDependencyEdges)
+	}
+}
+
+// receivedEdges devuelve las aristas de cada POST a /deps
+func receivedEdges(t *testing.T, srv *captureServer) [][]dependencyEdge {
+	t.Helper()
+	var out [][]dependencyEdge
+	for _, req := range srv.received() {
+		if req.Path != "/deps" {
+			continue
+		}
+		var body struct {
+			Edges []dependencyEdge `json:"edges"`
+		}
+		decodeJSON(t, req.Body, &body)
+		out = append(out, body.Edges)
+	}
+	return out
+}
+
+func TestDependencyEdgesSentOnceAfterSpans(t *testing.T) {
+	for _, encoding := range []string{encodingCustom, encodingOTLPJSON, encodingZipkin} {
+		t.Run(encoding, func(t *testing.T) {
+			srv := newCaptureServer(t)
+			srv.respond(failFirst(1, "/traces"))
+			cfg := testConfig(t, srv.URL)
+			cfg.TracesEncoding = encoding
+			cfg.DependencyEndpoint = srv.URL + "/deps"
+			fastRetries(cfg)
+			traces, _, _ := newFactoryExporters(t, cfg)
+			if err := traces.ConsumeTraces(context.Background(), dependencyTraces()); err != nil {
+				t.Fatal(err)
+			}
+
+			if got := countPath(srv.received(), "/traces"); got != 2 {
+				t.Fatalf("POST de spans = %d, se esperaba el fallido y el reintento", got)
+			}
+			// El reintento de los spans no reenvía las aristas
+			edges := receivedEdges(t, srv)
+			if len(edges) != 1 || !reflect.DeepEqual(edges[0], wantDependencyEdges) {
+				t.Errorf("aristas recibidas = %+v, se esperaba un único envío", edges)
+			}
+		})
+	}
+}
+
+func TestDerivedPostsNotRepeatedOnRetry(t *testing.T) {
+	// Cada envío derivado sale una sola vez aunque el otro falle: las aristas
+	// ya aceptadas no se reenvían por un fallo de RED, ni RED por uno de las
+	// aristas. El fallo se registra en lugar de reintentar el push
+	tests := []struct {
+		name     string
+		failPath string
+	}{
+		{"falla RED", "/metrics"},
+		{"fallan las aristas", "/deps"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := newCaptureServer(t)
+			srv.respond(failFirst(1, tt.failPath))
+			cfg := testConfig(t, srv.URL)
+			cfg.Traces = false
+			cfg.TracesToMetrics = true
+			cfg.DependencyEndpoint = srv.URL + "/deps"
+			fastRetries(cfg)
+			traces, _, _ := newFactoryExporters(t, cfg)
+			if err := traces.ConsumeTraces(context.Background(), dependencyTraces()); err != nil {
+				t.Fatal(err)
+			}
+			reqs := srv.received()
+			if deps, red := countPath(reqs, "/deps"), countPath(reqs, "/metrics"); deps != 1 || red != 1 {
+				t.Errorf("POST a /deps = %d y a /metrics = %d; se esperaba uno de cada", deps, red)
+			}
+		})
+	}
+}
+
+func TestDerivedPostsRetriedWhenNothingSent(t *testing.T) {
+	srv := newCaptureServer(t)
+	// Los dos envíos derivados fallan la primera vez
+	failDeps, failRED := failFirst(1, "/deps"), failFirst(1, "/metrics")
+	srv.respond(func(w http.ResponseWriter, r *http.Request) {
+		failDeps(w, r)
+		failRED(w, r)
+	})
+	cfg := testConfig(t, srv.URL)
+	cfg.Traces = false
+	cfg.TracesToMetrics = true
+	cfg.DependencyEndpoint = srv.URL + "/deps"
+	fastRetries(cfg)
+	traces, _, _ := newFactoryExporters(t, cfg)
+	if err := traces.ConsumeTraces(context.Background(), dependencyTraces()); err != nil {
+		t.Fatal(err)
+	}
+	reqs := srv.received()
+	if deps, red := countPath(reqs, "/deps"), countPath(reqs, "/metrics"); deps != 2 || red != 2 {
+		t.Errorf("POST a /deps = %d y a /metrics = %d; sin nada enviado se esperaba un reintento de ambos", deps, red)
+	}
+}
@@ -0,0 +1,56 @@
+package opentelemetryexportermonitoring
+
+import (
+	"go.opentelemetry.io/collector/pdata/ptrace"
+)
+
+// outSpanEvent es un evento del span (p. ej. una excepción)
+type outSpanEvent struct {
+	Name                   string                 `json:"name"`
+	Timestamp              uint64                 `json:"timestamp"` // ns
+	Attributes             map[string]interface{} `json:"attributes,omitempty"`
+	DroppedAttributesCount uint32                 `json:"dropped_attributes_count,omitempty"`
+}
+
+// outSpanLink es un enlace a otro span
+type outSpanLink struct {
+	TraceID                string                 `json:"traceId"`
+	SpanID                 string                 `json:"spanId"`
+	TraceState             string                 `json:"trace_state,omitempty"`
+	Attributes             map[string]interface{} `json:"attributes,omitempty"`
+	DroppedAttributesCount uint32                 `json:"dropped_attributes_count,omitempty"`
+}
+
+// addSpanEventsAndLinks añade los eventos y enlaces del span con sus IDs en
+// hex, y cuántos descartó el SDK
+func (m *monitoringExporter) addSpanEventsAndLinks(item *outSpan, sp ptrace.Span) {
+	for i := 0; i < sp.Events().Len(); i++ {
+		event := sp.Events().At(i)
+		out := outSpanEvent{
+			Name:                   event.Name(),
+			Timestamp:              uint64(event.Timestamp()),
+			DroppedAttributesCount: event.DroppedAttributesCount(),
+		}
+		if event.Attributes().Len() > 0 {
+			out.Attributes = make(map[string]interface{}, event.Attributes().Len())
+			m.putAttrs(out.Attributes, event.Attributes().AsRaw())
+		}
+		item.Events = append(item.Events, out)
+	}
+	for i := 0; i < sp.Links().Len(); i++ {
+		link := sp.Links().At(i)
+		out := outSpanLink{
+			TraceID:                link.TraceID().String(),
+			SpanID:                 link.SpanID().String(),
+			TraceState:             link.TraceState().AsRaw(),
+			DroppedAttributesCount: link.DroppedAttributesCount(),
+		}
+		if link.Attributes().Len() > 0 {
+			out.Attributes = make(map[string]interface{}, link.Attributes().Len())
+			m.putAttrs(out.Attributes, link.Attributes().AsRaw())
+		}
+		item.Links = append(item.Links, out)
+	}
+	item.DroppedEventsCount = sp.DroppedEventsCount()
+	item.DroppedLinksCount = sp.DroppedLinksCount()
+}
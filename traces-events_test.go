@@ -0,0 +1,63 @@
+package opentelemetryexportermonitoring
+
+import (
+	"testing"
+
+	"go.opentelemetry.io/collector/pdata/pcommon"
+)
+
+func TestSpanEventsAndLinks(t *testing.T) {
+	td := testTraces("svc", "op", "plain")
+	sp := td.ResourceSpans().At(0).ScopeSpans().At(0).Spans().At(0)
+	retry := sp.Events().AppendEmpty()
+	retry.SetName("retry")
+	retry.SetTimestamp(1_100_000_000)
+	retry.Attributes().PutInt("attempt", 2)
+	exception := sp.Events().AppendEmpty()
+	exception.SetName("exception")
+	exception.SetTimestamp(1_200_000_000)
+	link := sp.Links().AppendEmpty()
+	link.SetTraceID(pcommon.TraceID{0xaa, 15: 0x01})
+	link.SetSpanID(pcommon.SpanID{0xbb, 7: 0x02})
+	link.Attributes().PutStr("relation", "batch")
+	sp.SetDroppedEventsCount(3)
+	sp.SetDroppedLinksCount(1)
+
+	cfg := testConfig(t, "http://localhost")
+	cfg.IncludeSpanDetails = true
+	out, _, err := newTestExporter(t, cfg).transformTraces(td, transformCfg{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var spans []outSpan
+	decodeJSON(t, out, &spans)
+
+	events := spans[0].Events
+	if len(events) != 2 || events[0].Name != "retry" || events[1].Name != "exception" {
+		t.Fatalf("events = %+v, se esperaban retry y exception en orden", events)
+	}
+	if events[0].Timestamp != 1_100_000_000 || events[0].Attributes["attempt"] != float64(2) {
+		t.Errorf("retry = %+v", events[0])
+	}
+	if events[1].Attributes != nil {
+		t.Errorf("un evento sin atributos no debe llevarlos: %+v", events[1])
+	}
+
+	links := spans[0].Links
+	if len(links) != 1 {
+		t.Fatalf("links = %+v, se esperaba uno", links)
+	}
+	if links[0].TraceID != "aa000000000000000000000000000001" || links[0].SpanID != "bb00000000000002" {
+		t.Errorf("link = %s/%s", links[0].TraceID, links[0].SpanID)
+	}
+	if links[0].Attributes["relation"] != "batch" {
+		t.Errorf("atributos del link = %v", links[0].Attributes)
+	}
+	if spans[0].DroppedEventsCount != 3 || spans[0].DroppedLinksCount != 1 {
+		t.Errorf("dropped_events_count = %d, dropped_links_count = %d", spans[0].DroppedEventsCount, spans[0].DroppedLinksCount)
+	}
+
+	if len(spans[1].Events) != 0 || len(spans[1].Links) != 0 || spans[1].DroppedEventsCount != 0 {
+		t.Errorf("span sin eventos ni links: %+v", spans[1])
+	}
+}
@@ -0,0 +1,189 @@
+package opentelemetryexportermonitoring
+
+import (
+	"context"
+	"math/rand"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	"go.opentelemetry.io/collector/pdata/ptrace"
+	"go.uber.org/zap"
+)
+
+// latencyOp acumula las duraciones de una operación durante la ventana
+type latencyOp struct {
+	url     string
+	res     metricResource
+	service string
+	name    string
+	seen    int
+	samples []float64 // ms
+}
+
+// latencyAggregator calcula percentiles de duración por operación (servicio +
+// nombre de span) entre lotes y los envía como métricas al final de cada
+// span_latency_window. Por operación se guardan como máximo maxSamples
+// duraciones (muestreo reservoir), así que con más spans los percentiles son
+// aproximados
+type latencyAggregator struct {
+	exp         *monitoringExporter
+	window      time.Duration
+	percentiles []float64
+	maxSamples  int
+
+	mu  sync.Mutex
+	ops map[string]*latencyOp
+
+	stopCh chan struct{}
+	wg     sync.WaitGroup
+}
+
+func newLatencyAggregator(exp *monitoringExporter, c *Config) *latencyAggregator {
+	percentiles := c.SpanLatencyPercentiles
+	if len(percentiles) == 0 {
+		percentiles = []float64{50, 90, 99}
+	}
+	return &latencyAggregator{
+		exp:         exp,
+		window:      c.SpanLatencyWindow,
+		percentiles: percentiles,
+		maxSamples:  c.SpanLatencyMaxSamples,
+		ops:         make(map[string]*latencyOp),
+	}
+}
+
+// record añade las duraciones de los spans del lote
+func (a *latencyAggregator) record(td ptrace.Traces) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	rss := td.ResourceSpans()
+	for i := 0; i < rss.Len(); i++ {
+		resAttrs := rss.At(i).Resource().Attributes()
+		service := getAttrString(resAttrs, "service.name")
+		url := a.exp.metricsURL(resAttrs)
+		sss := rss.At(i).ScopeSpans()
+		for j := 0; j < sss.Len(); j++ {
+			spans := sss.At(j).Spans()
+			for k := 0; k < spans.Len(); k++ {
+				span := spans.At(k)
+				key := url + "\x00" + service + "\x00" + span.Name()
+				op, ok := a.ops[key]
+				if !ok {
+					op = &latencyOp{url: url, res: metricResource{attrs: resAttrs.AsRaw()}, service: service, name: span.Name()}
+					a.ops[key] = op
+				}
+				// Restar los uint64 daría la vuelta con fin < inicio
+				d := span.EndTimestamp().AsTime().Sub(span.StartTimestamp().AsTime())
+				if d < 0 {
+					d = 0
+				}
+				ms := float64(d) / float64(time.Millisecond)
+				op.seen++
+				if a.maxSamples <= 0 || len(op.samples) < a.maxSamples {
+					op.samples = append(op.samples, ms)
+				} else if r := rand.Intn(op.seen); r < a.maxSamples {
+					op.samples[r] = ms
+				}
+			}
+		}
+	}
+}
+
+// flush envía los percentiles de la ventana y la reinicia
+func (a *latencyAggregator) flush(ctx context.Context) {
+	a.mu.Lock()
+	ops := a.ops
+	a.ops = make(map[string]*latencyOp)
+	a.mu.Unlock()
+	if len(ops) == 0 {
+		return
+	}
+
+	now := pcommon.NewTimestampFromTime(time.Now())
+	urlToBody := make(map[string][]transformedMetric)
+	var urls []string
+	for _, op := range ops {
+		sort.Float64s(op.samples)
+		values := make(map[string]interface{}, len(a.percentiles)+1)
+		for _, p := range a.percentiles {
+			values[a.exp.metricKey("duration_ms_p"+strconv.FormatFloat(p, 'f', -1, 64))] = samplePercentile(op.samples, p/100)
+		}
+		values[a.exp.metricKey("count")] = op.seen
+
+		record := a.exp.newMetricRecord("span.latency", op.res, now, pcommon.NewMap(), nil)
+		record.Properties["service_name"] = op.service
+		record.Properties["span_name"] = op.name
+		record.Values = values
+		if _, ok := urlToBody[op.url]; !ok {
+			urls = append(urls, op.url)
+		}
+		urlToBody[op.url] = append(urlToBody[op.url], record)
+	}
+	sort.Strings(urls)
+
+	ctx, batchID := a.exp.withBatchID(ctx)
+	encode := func(records []transformedMetric) ([]byte, error) {
+		return a.exp.processMetrics(records, batchID)
+	}
+	for _, url := range urls {
+		bodies, err := splitPayload(a.exp, urlToBody[url], encode)
+		for _, data := range bodies {
+			if err = a.exp.postJSON(ctx, url, data); err != nil {
+				break
+			}
+		}
+		if err != nil {
+			a.exp.logger.Error("error al enviar los percentiles de latencia", zap.String("url", url), zap.Error(err))
+		}
+	}
+}
+
+// samplePercentile es el percentil q (0-1] por rango más cercano sobre
+// muestras ordenadas
+func samplePercentile(sorted []float64, q float64) float64 {
+	if len(sorted) == 0 {
+		return 0
+	}
+	idx := int(q*float64(len(sorted))+0.5) - 1
+	if idx < 0 {
+		idx = 0
+	}
+	if idx >= len(sorted) {
+		idx = len(sorted) - 1
+	}
+	return sorted[idx]
+}
+
+func (a *latencyAggregator) start(_ context.Context, _ component.Host) error {
+	a.stopCh = make(chan struct{})
+	a.wg.Add(1)
+	go func() {
+		defer a.wg.Done()
+		ticker := time.NewTicker(a.window)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				a.flush(context.Background())
+			case <-a.stopCh:
+				return
+			}
+		}
+	}()
+	return nil
+}
+
+// shutdown para el temporizador y envía la ventana en curso
+func (a *latencyAggregator) shutdown(ctx context.Context) error {
+	if a.stopCh != nil {
+		close(a.stopCh)
+		a.wg.Wait()
+		a.stopCh = nil
+	}
+	a.flush(ctx)
+	return nil
+}
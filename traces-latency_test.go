@@ -0,0 +1,152 @@
+package opentelemetryexportermonitoring
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"go.opentelemetry.io/collector/component/componenttest"
+	"go.opentelemetry.io/collector/exporter/exportertest"
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	"go.opentelemetry.io/collector/pdata/ptrace"
+)
+
+// latencyValues devuelve los values de span.latency recibidos en /metrics,
+// por nombre de span
+func latencyValues(t *testing.T, srv *captureServer) map[string]map[string]interface{} {
+	t.Helper()
+	out := make(map[string]map[string]interface{})
+	for _, req := range srv.received() {
+		if req.Path != "/metrics" {
+			continue
+		}
+		var body struct {
+			Metrics []transformedMetric `json:"metrics"`
+		}
+		decodeJSON(t, req.Body, &body)
+		for _, record := range body.Metrics {
+			if record.Properties["name"] == "span.latency" {
+				out[record.Properties["span_name"].(string)] = record.Values
+			}
+		}
+	}
+	return out
+}
+
+// checkLatency comprueba el recuento y los percentiles de "GET /a" en redTraces
+func checkLatency(t *testing.T, values map[string]map[string]interface{}) {
+	t.Helper()
+	get := values["GET /a"]
+	if get == nil {
+		t.Fatalf("falta span.latency de GET /a: %v", values)
+	}
+	for key, want := range map[string]float64{"count": 3, "duration_ms_p50": 20, "duration_ms_p99": 300} {
+		if get[key] != want {
+			t.Errorf("GET /a %s = %v, se esperaba %v", key, get[key], want)
+		}
+	}
+	if db := values["db"]; db == nil || db["count"] != 1.0 {
+		t.Errorf("db = %v, se esperaba count 1", db)
+	}
+}
+
+func TestSpanLatencyWindowFlush(t *testing.T) {
+	for _, encoding := range []string{encodingCustom, encodingOTLPJSON, encodingZipkin} {
+		t.Run(encoding, func(t *testing.T) {
+			srv := newCaptureServer(t)
+			cfg := testConfig(t, srv.URL)
+			cfg.TracesEncoding = encoding
+			cfg.SpanLatencyWindow = 20 * time.Millisecond
+			traces, err := createTracesExporter(context.Background(), exportertest.NewNopSettings(typeStr), cfg)
+			if err != nil {
+				t.Fatal(err)
+			}
+			startComponent(t, traces)
+			if err := traces.ConsumeTraces(context.Background(), redTraces()); err != nil {
+				t.Fatal(err)
+			}
+
+			// Los percentiles llegan al cerrar la ventana, sin esperar al Shutdown
+			deadline := time.Now().Add(2 * time.Second)
+			for len(latencyValues(t, srv)) == 0 && time.Now().Before(deadline) {
+				time.Sleep(5 * time.Millisecond)
+			}
+			checkLatency(t, latencyValues(t, srv))
+		})
+	}
+}
+
+func TestSpanLatencyNotCountedOnRetry(t *testing.T) {
+	srv := newCaptureServer(t)
+	srv.respond(failFirst(1, "/traces"))
+	cfg := testConfig(t, srv.URL)
+	cfg.SpanLatencyWindow = time.Hour
+	fastRetries(cfg)
+	ctx := context.Background()
+	traces, err := createTracesExporter(ctx, exportertest.NewNopSettings(typeStr), cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := traces.Start(ctx, componenttest.NewNopHost()); err != nil {
+		t.Fatal(err)
+	}
+	if err := traces.ConsumeTraces(ctx, redTraces()); err != nil {
+		t.Fatal(err)
+	}
+	// La ventana en curso se envía al parar
+	if err := traces.Shutdown(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if got := countPath(srv.received(), "/traces"); got != 2 {
+		t.Fatalf("POST de spans = %d, se esperaba el fallido y el reintento", got)
+	}
+	checkLatency(t, latencyValues(t, srv))
+}
+
+func TestSpanLatencyNotCountedOnDerivedRetry(t *testing.T) {
+	// Sin spans el fallo de las métricas RED reintenta el push completo
+	srv := newCaptureServer(t)
+	srv.respond(failFirst(1, "/metrics"))
+	cfg := testConfig(t, srv.URL)
+	cfg.Traces = false
+	cfg.TracesToMetrics = true
+	cfg.SpanLatencyWindow = time.Hour
+	fastRetries(cfg)
+	ctx := context.Background()
+	traces, err := createTracesExporter(ctx, exportertest.NewNopSettings(typeStr), cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := traces.Start(ctx, componenttest.NewNopHost()); err != nil {
+		t.Fatal(err)
+	}
+	if err := traces.ConsumeTraces(ctx, redTraces()); err != nil {
+		t.Fatal(err)
+	}
+	if err := traces.Shutdown(ctx); err != nil {
+		t.Fatal(err)
+	}
+	// RED fallido, RED reintentado y la ventana de latencias al parar
+	if got := countPath(srv.received(), "/metrics"); got != 3 {
+		t.Fatalf("POST a /metrics = %d, se esperaban 3", got)
+	}
+	checkLatency(t, latencyValues(t, srv))
+}
+
+func TestSpanLatencyEndBeforeStart(t *testing.T) {
+	td := ptrace.NewTraces()
+	span := td.ResourceSpans().AppendEmpty().ScopeSpans().AppendEmpty().Spans().AppendEmpty()
+	span.SetName("reloj")
+	span.SetStartTimestamp(pcommon.Timestamp(2 * time.Second))
+	span.SetEndTimestamp(pcommon.Timestamp(time.Second))
+
+	cfg := testConfig(t, "http://localhost")
+	cfg.SpanLatencyWindow = time.Minute
+	a := newLatencyAggregator(newTestExporter(t, cfg), cfg)
+	a.record(td)
+	for _, op := range a.ops {
+		if len(op.samples) != 1 || op.samples[0] != 0 {
+			t.Errorf("muestras = %v, un fin anterior al inicio debe contar 0 ms", op.samples)
+		}
+	}
+}
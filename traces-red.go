@@ -0,0 +1,139 @@
+package opentelemetryexportermonitoring
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	"go.opentelemetry.io/collector/pdata/ptrace"
+)
+
+// defaultRedBuckets son los límites de latencia por defecto de traces_to_metrics
+var defaultRedBuckets = []time.Duration{
+	5 * time.Millisecond, 10 * time.Millisecond, 25 * time.Millisecond,
+	50 * time.Millisecond, 100 * time.Millisecond, 250 * time.Millisecond,
+	500 * time.Millisecond, time.Second, 2500 * time.Millisecond,
+	5 * time.Second, 10 * time.Second,
+}
+
+// redKey agrupa los spans por servicio, nombre y tipo
+type redKey struct {
+	url     string
+	service string
+	name    string
+	kind    string
+}
+
+// redAggregate son las métricas RED (rate, errors, duration) de un grupo
+type redAggregate struct {
+	res     metricResource
+	calls   int64
+	errors  int64
+	sumMs   float64
+	buckets []int64 // acumulados: buckets[i] = spans con duración <= límite i
+	lastTS  pcommon.Timestamp
+}
+
+// transformSpanMetrics calcula por (servicio, span, kind) las llamadas, los
+// errores y el histograma de latencias, uno por registro de métrica
+func (m *monitoringExporter) transformSpanMetrics(td ptrace.Traces) ([]transformedMetric, []string) {
+	aggs := make(map[redKey]*redAggregate)
+	var order []redKey
+
+	resourceSpans := td.ResourceSpans()
+	for i := 0; i < resourceSpans.Len(); i++ {
+		resource := resourceSpans.At(i).Resource()
+		url := m.metricsURL(resource.Attributes())
+		service := getAttrString(resource.Attributes(), "service.name")
+		res := metricResource{attrs: resource.Attributes().AsRaw()}
+
+		scopeSpans := resourceSpans.At(i).ScopeSpans()
+		for j := 0; j < scopeSpans.Len(); j++ {
+			spans := scopeSpans.At(j).Spans()
+			for k := 0; k < spans.Len(); k++ {
+				span := spans.At(k)
+				key := redKey{url: url, service: service, name: span.Name(), kind: span.Kind().String()}
+				agg, ok := aggs[key]
+				if !ok {
+					agg = &redAggregate{res: res, buckets: make([]int64, len(m.redBuckets))}
+					aggs[key] = agg
+					order = append(order, key)
+				}
+
+				duration := span.EndTimestamp().AsTime().Sub(span.StartTimestamp().AsTime())
+				agg.calls++
+				if span.Status().Code() == ptrace.StatusCodeError {
+					agg.errors++
+				}
+				agg.sumMs += float64(duration) / float64(time.Millisecond)
+				for b, bound := range m.redBuckets {
+					if duration <= bound {
+						agg.buckets[b]++
+					}
+				}
+				if span.EndTimestamp() > agg.lastTS {
+					agg.lastTS = span.EndTimestamp()
+				}
+			}
+		}
+	}
+
+	records := make([]transformedMetric, 0, len(order))
+	urls := make([]string, 0, len(order))
+	for _, key := range order {
+		agg := aggs[key]
+		record := m.newMetricRecord("span.metrics", agg.res, agg.lastTS, pcommon.NewMap(), agg.calls)
+		record.Properties["service_name"] = key.service
+		record.Properties["span_name"] = key.name
+		record.Properties["span_kind"] = key.kind
+		// Las claves son nombres de métrica: llevan metric_name_prefix y se sanean
+		record.Values = map[string]interface{}{
+			m.metricKey("calls"):              agg.calls,
+			m.metricKey("errors"):             agg.errors,
+			m.metricKey("duration_ms_sum"):    agg.sumMs,
+			m.metricKey("duration_ms_le_inf"): agg.calls,
+		}
+		for b, bound := range m.redBuckets {
+			le := strconv.FormatFloat(float64(bound)/float64(time.Millisecond), 'f', -1, 64)
+			record.Values[m.metricKey("duration_ms_le_"+le)] = agg.buckets[b]
+		}
+		records = append(records, record)
+		urls = append(urls, key.url)
+	}
+	return records, urls
+}
+
+// pushSpanMetrics envía las métricas RED derivadas de las trazas al endpoint de métricas
+func (m *monitoringExporter) pushSpanMetrics(ctx context.Context, td ptrace.Traces) error {
+	records, createUrls := m.transformSpanMetrics(td)
+	if len(records) == 0 {
+		return nil
+	}
+	ctx, batchID := m.withBatchID(ctx)
+
+	urlToBody := make(map[string][]transformedMetric)
+	var urls []string
+	for i, url := range createUrls {
+		if _, ok := urlToBody[url]; !ok {
+			urls = append(urls, url)
+		}
+		urlToBody[url] = append(urlToBody[url], records[i])
+	}
+	encode := func(records []transformedMetric) ([]byte, error) {
+		return m.processMetrics(records, batchID)
+	}
+	for _, url := range urls {
+		bodies, err := splitPayload(m, urlToBody[url], encode)
+		if err != nil {
+			return err
+		}
+		for _, data := range bodies {
+			if err := m.postJSON(ctx, url, data); err != nil {
+				return fmt.Errorf("error sending span metrics to URL %s: %w", url, err)
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,145 @@
+package opentelemetryexportermonitoring
+
+import (
+	"context"
+	"math"
+	"net/http"
+	"testing"
+	"time"
+
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	"go.opentelemetry.io/collector/pdata/ptrace"
+)
+
+// redTraces devuelve tres spans de servidor "GET /a" (4ms, 20ms y 300ms, el
+// último con error) y un span cliente "db" de 1s
+func redTraces() ptrace.Traces {
+	td := ptrace.NewTraces()
+	rs := td.ResourceSpans().AppendEmpty()
+	rs.Resource().Attributes().PutStr("service.name", "svc")
+	spans := rs.ScopeSpans().AppendEmpty().Spans()
+	add := func(name string, kind ptrace.SpanKind, duration time.Duration, failed bool) {
+		span := spans.AppendEmpty()
+		span.SetName(name)
+		span.SetKind(kind)
+		span.SetStartTimestamp(pcommon.Timestamp(time.Second))
+		span.SetEndTimestamp(pcommon.Timestamp(time.Second + duration))
+		if failed {
+			span.Status().SetCode(ptrace.StatusCodeError)
+		}
+	}
+	add("GET /a", ptrace.SpanKindServer, 4*time.Millisecond, false)
+	add("GET /a", ptrace.SpanKindServer, 20*time.Millisecond, false)
+	add("GET /a", ptrace.SpanKindServer, 300*time.Millisecond, true)
+	add("db", ptrace.SpanKindClient, time.Second, false)
+	return td
+}
+
+func TestSpanMetricsAggregates(t *testing.T) {
+	cfg := testConfig(t, "http://localhost")
+	cfg.TracesToMetrics = true
+	cfg.TracesToMetricsBuckets = []time.Duration{5 * time.Millisecond, 25 * time.Millisecond, 500 * time.Millisecond}
+	records, urls := newTestExporter(t, cfg).transformSpanMetrics(redTraces())
+	if len(records) != 2 || len(urls) != 2 {
+		t.Fatalf("%d registros, se esperaba uno por (servicio, span, kind)", len(records))
+	}
+
+	server := records[0]
+	if server.Properties["span_name"] != "GET /a" || server.Properties["span_kind"] != "Server" || server.Properties["service_name"] != "svc" {
+		t.Errorf("properties = %v", server.Properties)
+	}
+	want := map[string]interface{}{
+		"calls":              int64(3),
+		"errors":             int64(1),
+		"duration_ms_le_5":   int64(1),
+		"duration_ms_le_25":  int64(2),
+		"duration_ms_le_500": int64(3),
+		"duration_ms_le_inf": int64(3),
+	}
+	for k, v := range want {
+		if server.Values[k] != v {
+			t.Errorf("GET /a: %s = %v, se esperaba %v", k, server.Values[k], v)
+		}
+	}
+	if sum := server.Values["duration_ms_sum"].(float64); math.Abs(sum-324) > 1e-9 {
+		t.Errorf("GET /a: duration_ms_sum = %v, se esperaba 324", sum)
+	}
+
+	client := records[1]
+	if client.Properties["span_name"] != "db" || client.Values["calls"] != int64(1) || client.Values["duration_ms_le_500"] != int64(0) {
+		t.Errorf("db: properties %v, values %v", client.Properties, client.Values)
+	}
+}
+
+func TestSpanMetricsNamePrefix(t *testing.T) {
+	cfg := testConfig(t, "http://localhost")
+	cfg.TracesToMetrics = true
+	cfg.TracesToMetricsBuckets = []time.Duration{5 * time.Millisecond}
+	cfg.MetricNamePrefix = "myprefix."
+	records, _ := newTestExporter(t, cfg).transformSpanMetrics(redTraces())
+	for _, k := range []string{"calls", "errors", "duration_ms_sum", "duration_ms_le_5", "duration_ms_le_inf"} {
+		if _, ok := records[0].Values["myprefix."+k]; !ok {
+			t.Errorf("falta myprefix.%s en %v", k, records[0].Values)
+		}
+	}
+	if len(records[0].Values) != 5 {
+		t.Errorf("values = %v, todas las claves debían llevar el prefijo", records[0].Values)
+	}
+}
+
+func TestSpanMetricsPostedAfterSpans(t *testing.T) {
+	srv := newCaptureServer(t)
+	srv.respond(failFirst(1, "/traces"))
+	cfg := testConfig(t, srv.URL)
+	cfg.TracesToMetrics = true
+	fastRetries(cfg)
+	traces, _, _ := newFactoryExporters(t, cfg)
+
+	// El primer envío de spans falla y el exporterhelper reintenta el lote
+	if err := traces.ConsumeTraces(context.Background(), redTraces()); err != nil {
+		t.Fatal(err)
+	}
+	reqs := srv.received()
+	if got := countPath(reqs, "/traces"); got != 2 {
+		t.Errorf("%d envíos de spans, se esperaban 2", got)
+	}
+	if got := countPath(reqs, "/metrics"); got != 1 {
+		t.Errorf("métricas RED enviadas %d veces, se esperaba una", got)
+	}
+	if reqs[len(reqs)-1].Path != "/metrics" {
+		t.Errorf("la última petición fue a %s, las métricas RED van después de los spans", reqs[len(reqs)-1].Path)
+	}
+}
+
+func TestSpanMetricsErrorAfterSpansIsNotRetried(t *testing.T) {
+	srv := newCaptureServer(t)
+	srv.respond(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/metrics" {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}
+	})
+	cfg := testConfig(t, srv.URL)
+	cfg.TracesToMetrics = true
+	if err := newTestExporter(t, cfg).pushTraces(context.Background(), redTraces()); err != nil {
+		t.Errorf("el fallo de las métricas RED no debe reintentar spans ya enviados: %v", err)
+	}
+
+	// Sin spans que enviar el error sí se devuelve
+	cfg.Traces = false
+	if err := newTestExporter(t, cfg).pushTraces(context.Background(), redTraces()); err == nil {
+		t.Error("se esperaba el error de las métricas RED con traces desactivado")
+	}
+}
+
+func TestValidateTracesToMetricsEncoding(t *testing.T) {
+	for _, enc := range []string{encodingOTLPJSON, encodingOTLPProto, encodingZipkin} {
+		cfg := testConfig(t, "http://localhost")
+		cfg.TracesToMetrics = true
+		cfg.TracesEncoding = enc
+		validateError(t, cfg, "traces_to_metrics")
+	}
+	cfg := testConfig(t, "http://localhost")
+	cfg.TracesToMetrics = true
+	cfg.Format = encodingOTLPJSON
+	validateError(t, cfg, "traces_to_metrics")
+}
@@ -0,0 +1,83 @@
+package opentelemetryexportermonitoring
+
+import (
+	"hash/fnv"
+	"math"
+
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	"go.opentelemetry.io/collector/pdata/ptrace"
+)
+
+// sampleTrace decide de forma determinista si se conserva la traza: el
+// hash FNV-1a del trace ID se compara con rate, así que todos los spans de
+// una traza (y todas las réplicas del collector) toman la misma decisión.
+// FNV-1a apenas mueve los bits altos con los últimos bytes, así que trace IDs
+// que solo difieren al final darían todos la misma decisión; el mezclado
+// final de murmur3 (fmix64) los reparte
+func sampleTrace(traceID pcommon.TraceID, rate float64) bool {
+	h := fnv.New64a()
+	h.Write(traceID[:])
+	x := h.Sum64()
+	x ^= x >> 33
+	x *= 0xff51afd7ed558ccd
+	x ^= x >> 33
+	x *= 0xc4ceb9fe1a85ec53
+	x ^= x >> 33
+	return float64(x) < rate*math.MaxUint64
+}
+
+// sampleTraces devuelve los spans de las trazas muestreadas según
+// trace_sample_rate. Con trace_sample_keep_errors se conservan enteras las
+// trazas con algún span en error. Los datos de entrada no se modifican
+func (m *monitoringExporter) sampleTraces(td ptrace.Traces) ptrace.Traces {
+	if m.traceSampleRate >= 1 {
+		return td
+	}
+
+	keep := make(map[pcommon.TraceID]bool)
+	rss := td.ResourceSpans()
+	for i := 0; i < rss.Len(); i++ {
+		sss := rss.At(i).ScopeSpans()
+		for j := 0; j < sss.Len(); j++ {
+			spans := sss.At(j).Spans()
+			for k := 0; k < spans.Len(); k++ {
+				span := spans.At(k)
+				if _, ok := keep[span.TraceID()]; !ok {
+					keep[span.TraceID()] = sampleTrace(span.TraceID(), m.traceSampleRate)
+				}
+				if m.traceSampleKeepErrors && span.Status().Code() == ptrace.StatusCodeError {
+					keep[span.TraceID()] = true
+				}
+			}
+		}
+	}
+
+	out := ptrace.NewTraces()
+	for i := 0; i < rss.Len(); i++ {
+		rs := rss.At(i)
+		var outRS ptrace.ResourceSpans
+		sss := rs.ScopeSpans()
+		for j := 0; j < sss.Len(); j++ {
+			ss := sss.At(j)
+			var outSS ptrace.ScopeSpans
+			spans := ss.Spans()
+			for k := 0; k < spans.Len(); k++ {
+				if !keep[spans.At(k).TraceID()] {
+					continue
+				}
+				if outSS == (ptrace.ScopeSpans{}) {
+					if outRS == (ptrace.ResourceSpans{}) {
+						outRS = out.ResourceSpans().AppendEmpty()
+						rs.Resource().CopyTo(outRS.Resource())
+						outRS.SetSchemaUrl(rs.SchemaUrl())
+					}
+					outSS = outRS.ScopeSpans().AppendEmpty()
+					ss.Scope().CopyTo(outSS.Scope())
+					outSS.SetSchemaUrl(ss.SchemaUrl())
+				}
+				spans.At(k).CopyTo(outSS.Spans().AppendEmpty())
+			}
+		}
+	}
+	return out
+}
@@ -0,0 +1,90 @@
+package opentelemetryexportermonitoring
+
+import (
+	"encoding/binary"
+	"reflect"
+	"testing"
+
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	"go.opentelemetry.io/collector/pdata/ptrace"
+)
+
+// sampledTraces tiene n trazas con tres spans cada una, repartidos entre dos
+// resources para que la decisión deba coincidir entre lotes de spans distintos
+func sampledTraces(n int) ptrace.Traces {
+	td := ptrace.NewTraces()
+	resources := []ptrace.SpanSlice{
+		td.ResourceSpans().AppendEmpty().ScopeSpans().AppendEmpty().Spans(),
+		td.ResourceSpans().AppendEmpty().ScopeSpans().AppendEmpty().Spans(),
+	}
+	for i := 0; i < n; i++ {
+		var traceID pcommon.TraceID
+		binary.BigEndian.PutUint64(traceID[8:], uint64(i+1))
+		for s := 0; s < 3; s++ {
+			span := resources[s%2].AppendEmpty()
+			span.SetTraceID(traceID)
+			span.SetSpanID(pcommon.SpanID{byte(s + 1)})
+		}
+	}
+	return td
+}
+
+// spansPerTrace cuenta los spans que quedan de cada traza
+func spansPerTrace(td ptrace.Traces) map[pcommon.TraceID]int {
+	counts := make(map[pcommon.TraceID]int)
+	rss := td.ResourceSpans()
+	for i := 0; i < rss.Len(); i++ {
+		sss := rss.At(i).ScopeSpans()
+		for j := 0; j < sss.Len(); j++ {
+			spans := sss.At(j).Spans()
+			for k := 0; k < spans.Len(); k++ {
+				counts[spans.At(k).TraceID()]++
+			}
+		}
+	}
+	return counts
+}
+
+func TestTraceSamplingConsistentPerTrace(t *testing.T) {
+	const traces, rate = 2000, 0.3
+	cfg := testConfig(t, "http://localhost")
+	cfg.TraceSampleRate = rate
+	exp := newTestExporter(t, cfg)
+
+	kept := spansPerTrace(exp.sampleTraces(sampledTraces(traces)))
+	for traceID, n := range kept {
+		if n != 3 {
+			t.Errorf("traza %s: %d de 3 spans, la decisión debe ser la misma para toda la traza", traceID, n)
+		}
+	}
+	if got := float64(len(kept)) / traces; got < rate-0.05 || got > rate+0.05 {
+		t.Errorf("se conservó el %.1f%% de las trazas, se esperaba ~%.0f%%", got*100, rate*100)
+	}
+
+	// Otro exporter (otra réplica del collector) toma las mismas decisiones
+	again := spansPerTrace(newTestExporter(t, cfg).sampleTraces(sampledTraces(traces)))
+	if !reflect.DeepEqual(kept, again) {
+		t.Error("el muestreo no es determinista entre exporters")
+	}
+}
+
+func TestTraceSamplingKeepErrors(t *testing.T) {
+	td := sampledTraces(200)
+	// Un span en error en cada traza
+	spans := td.ResourceSpans().At(1).ScopeSpans().At(0).Spans()
+	for k := 0; k < spans.Len(); k++ {
+		spans.At(k).Status().SetCode(ptrace.StatusCodeError)
+	}
+	cfg := testConfig(t, "http://localhost")
+	cfg.TraceSampleRate = 0.1
+	cfg.TraceSampleKeepErrors = true
+	kept := spansPerTrace(newTestExporter(t, cfg).sampleTraces(td))
+	if len(kept) != 200 {
+		t.Errorf("trazas conservadas = %d, las trazas con error se conservan todas", len(kept))
+	}
+	for traceID, n := range kept {
+		if n != 3 {
+			t.Errorf("traza %s: %d de 3 spans, se conserva entera", traceID, n)
+		}
+	}
+}
@@ -0,0 +1,265 @@
+package opentelemetryexportermonitoring
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/consumer/consumererror"
+	"go.opentelemetry.io/collector/pdata/plog"
+	"go.opentelemetry.io/collector/pdata/pmetric"
+	"go.opentelemetry.io/collector/pdata/ptrace"
+	"go.uber.org/zap"
+)
+
+// windowFlushAttempts es el número de envíos de una ventana antes de descartarla
+// y maxFailedWindows el de ventanas fallidas que se conservan a la vez
+const (
+	windowFlushAttempts = 3
+	maxFailedWindows    = 4
+)
+
+// windowBuffer acumula los lotes recibidos durante batch_window y los envía
+// juntos en un único push. El push original devuelve nil en cuanto el lote
+// queda en el buffer, así que el exporterhelper ya no puede reintentarlo: una
+// ventana que falla con un error no permanente se conserva y se reenvía al
+// final de las ventanas siguientes, hasta windowFlushAttempts envíos y como
+// mucho maxFailedWindows ventanas. Si el buffer supera batch_window_max_bytes
+// (tamaño protobuf) se vacía antes de que acabe la ventana
+type windowBuffer[T any] struct {
+	window   time.Duration
+	maxBytes int
+	adaptive *adaptiveBatchSize
+	logger   *zap.Logger
+
+	newData func() T
+	merge   func(src, dst T)
+	size    func(T) int
+	flushFn func(context.Context, T) error
+
+	mu      sync.Mutex
+	pending T
+	bytes   int
+	failed  []failedWindow[T]
+
+	stopCh chan struct{}
+	wg     sync.WaitGroup
+}
+
+// failedWindow es una ventana cuyo envío falló y que se reenviará
+type failedWindow[T any] struct {
+	data     T
+	attempts int
+}
+
+func newWindowBuffer[T any](c *Config, logger *zap.Logger, flush func(context.Context, T) error,
+	newData func() T, merge func(src, dst T), size func(T) int) *windowBuffer[T] {
+	return &windowBuffer[T]{
+		window:   c.BatchWindow,
+		maxBytes: c.BatchWindowMaxBytes,
+		adaptive: newAdaptiveBatchSize(c),
+		logger:   logger,
+		newData:  newData,
+		merge:    merge,
+		size:     size,
+		flushFn:  flush,
+		pending:  newData(),
+	}
+}
+
+// push copia el lote al buffer (los datos pueden ser compartidos y de solo lectura)
+func (b *windowBuffer[T]) push(ctx context.Context, data T) error {
+	size := b.size(data)
+
+	maxBytes := b.maxBytes
+	if b.adaptive != nil {
+		maxBytes = b.adaptive.limit()
+	}
+
+	b.mu.Lock()
+	if maxBytes > 0 && b.bytes > 0 && b.bytes+size > maxBytes {
+		full := b.takeLocked()
+		b.mu.Unlock()
+		b.flush(ctx, full, 0)
+		b.mu.Lock()
+	}
+	b.merge(data, b.pending)
+	b.bytes += size
+	b.mu.Unlock()
+	return nil
+}
+
+// takeLocked devuelve el contenido del buffer y lo reinicia; requiere b.mu
+func (b *windowBuffer[T]) takeLocked() T {
+	data := b.pending
+	b.pending = b.newData()
+	b.bytes = 0
+	return data
+}
+
+// flush envía una ventana que ya lleva attempts envíos fallidos y la conserva
+// para reenviarla si vuelve a fallar y le quedan intentos
+func (b *windowBuffer[T]) flush(ctx context.Context, data T, attempts int) error {
+	started := time.Now()
+	err := b.flushFn(ctx, data)
+	if b.adaptive != nil {
+		b.adaptive.observe(time.Since(started))
+	}
+	if err == nil {
+		return nil
+	}
+	attempts++
+	if consumererror.IsPermanent(err) || attempts >= windowFlushAttempts {
+		b.logger.Error("error al enviar la ventana de datos acumulados, se descarta",
+			zap.Int("attempts", attempts), zap.Error(err))
+		return err
+	}
+	b.logger.Warn("error al enviar la ventana de datos acumulados, se reenviará",
+		zap.Int("attempts", attempts), zap.Error(err))
+	b.mu.Lock()
+	if len(b.failed) >= maxFailedWindows {
+		b.logger.Error("demasiadas ventanas pendientes de reenvío, se descarta la más antigua")
+		b.failed = b.failed[1:]
+	}
+	b.failed = append(b.failed, failedWindow[T]{data: data, attempts: attempts})
+	b.mu.Unlock()
+	return err
+}
+
+// flushPending reenvía las ventanas fallidas y vacía el buffer si tiene datos
+func (b *windowBuffer[T]) flushPending(ctx context.Context) error {
+	b.mu.Lock()
+	failed := b.failed
+	b.failed = nil
+	var data T
+	hasData := b.bytes > 0
+	if hasData {
+		data = b.takeLocked()
+	}
+	b.mu.Unlock()
+
+	var errs []error
+	for _, w := range failed {
+		errs = append(errs, b.flush(ctx, w.data, w.attempts))
+	}
+	if hasData {
+		errs = append(errs, b.flush(ctx, data, 0))
+	}
+	return errors.Join(errs...)
+}
+
+// start arranca el temporizador que vacía el buffer al final de cada ventana
+func (b *windowBuffer[T]) start(_ context.Context, _ component.Host) error {
+	b.stopCh = make(chan struct{})
+	b.wg.Add(1)
+	go func() {
+		defer b.wg.Done()
+		ticker := time.NewTicker(b.window)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				_ = b.flushPending(context.Background())
+			case <-b.stopCh:
+				return
+			}
+		}
+	}()
+	return nil
+}
+
+// shutdown para el temporizador y envía lo pendiente dentro del plazo de ctx;
+// devuelve el error de los envíos que fallan en este último intento
+func (b *windowBuffer[T]) shutdown(ctx context.Context) error {
+	if b.stopCh != nil {
+		close(b.stopCh)
+		b.wg.Wait()
+		b.stopCh = nil
+	}
+	err := b.flushPending(ctx)
+	b.mu.Lock()
+	b.failed = nil
+	b.mu.Unlock()
+	return err
+}
+
+func newTracesWindowBuffer(c *Config, logger *zap.Logger, flush func(context.Context, ptrace.Traces) error) *windowBuffer[ptrace.Traces] {
+	sizer := &ptrace.ProtoMarshaler{}
+	return newWindowBuffer(c, logger, flush, ptrace.NewTraces,
+		func(src, dst ptrace.Traces) {
+			for i := 0; i < src.ResourceSpans().Len(); i++ {
+				src.ResourceSpans().At(i).CopyTo(dst.ResourceSpans().AppendEmpty())
+			}
+		},
+		sizer.TracesSize)
+}
+
+func newMetricsWindowBuffer(c *Config, logger *zap.Logger, flush func(context.Context, pmetric.Metrics) error) *windowBuffer[pmetric.Metrics] {
+	sizer := &pmetric.ProtoMarshaler{}
+	return newWindowBuffer(c, logger, flush, pmetric.NewMetrics,
+		func(src, dst pmetric.Metrics) {
+			for i := 0; i < src.ResourceMetrics().Len(); i++ {
+				src.ResourceMetrics().At(i).CopyTo(dst.ResourceMetrics().AppendEmpty())
+			}
+		},
+		sizer.MetricsSize)
+}
+
+func newLogsWindowBuffer(c *Config, logger *zap.Logger, flush func(context.Context, plog.Logs) error) *windowBuffer[plog.Logs] {
+	sizer := &plog.ProtoMarshaler{}
+	return newWindowBuffer(c, logger, flush, plog.NewLogs,
+		func(src, dst plog.Logs) {
+			for i := 0; i < src.ResourceLogs().Len(); i++ {
+				src.ResourceLogs().At(i).CopyTo(dst.ResourceLogs().AppendEmpty())
+			}
+		},
+		sizer.LogsSize)
+}
+
+// adaptiveBatchSize ajusta el tamaño máximo del buffer según la latencia de
+// los envíos: si un envío tarda más que adaptive_batch_target_latency el
+// tamaño se duplica (menos POST, más grandes) y si tarda menos de la mitad se
+// reduce a la mitad (menos latencia), siempre entre el mínimo y el máximo
+type adaptiveBatchSize struct {
+	minBytes int
+	maxBytes int
+	target   time.Duration
+
+	mu      sync.Mutex
+	current int
+}
+
+// newAdaptiveBatchSize devuelve nil si el ajuste no está configurado
+func newAdaptiveBatchSize(c *Config) *adaptiveBatchSize {
+	if c.AdaptiveBatchTargetLatency <= 0 {
+		return nil
+	}
+	current := c.BatchWindowMaxBytes
+	current = max(current, c.AdaptiveBatchMinBytes)
+	current = min(current, c.AdaptiveBatchMaxBytes)
+	return &adaptiveBatchSize{
+		minBytes: c.AdaptiveBatchMinBytes,
+		maxBytes: c.AdaptiveBatchMaxBytes,
+		target:   c.AdaptiveBatchTargetLatency,
+		current:  current,
+	}
+}
+
+func (a *adaptiveBatchSize) limit() int {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return a.current
+}
+
+func (a *adaptiveBatchSize) observe(latency time.Duration) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	switch {
+	case latency > a.target:
+		a.current = min(a.current*2, a.maxBytes)
+	case latency < a.target/2:
+		a.current = max(a.current/2, a.minBytes)
+	}
+}
@@ -0,0 +1,202 @@
+package opentelemetryexportermonitoring
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"go.opentelemetry.io/collector/component/componenttest"
+	"go.opentelemetry.io/collector/consumer/consumererror"
+	"go.opentelemetry.io/collector/pdata/pmetric"
+	"go.uber.org/zap"
+)
+
+// windowFlushes registra lo que recibe la función de envío de un windowBuffer
+// y falla mientras queden errores en errs
+type windowFlushes struct {
+	mu      sync.Mutex
+	batches [][]string
+	errs    []error
+	ch      chan struct{}
+}
+
+func (f *windowFlushes) flush(_ context.Context, md pmetric.Metrics) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.ch != nil {
+		defer func() { f.ch <- struct{}{} }()
+	}
+	if len(f.errs) > 0 {
+		err := f.errs[0]
+		f.errs = f.errs[1:]
+		return err
+	}
+	var names []string
+	for i := 0; i < md.ResourceMetrics().Len(); i++ {
+		names = append(names, metricNames(md.ResourceMetrics().At(i))...)
+	}
+	f.batches = append(f.batches, names)
+	return nil
+}
+
+// metricNames devuelve los nombres de las métricas de un resource
+func metricNames(rm pmetric.ResourceMetrics) []string {
+	var names []string
+	metrics := rm.ScopeMetrics().At(0).Metrics()
+	for i := 0; i < metrics.Len(); i++ {
+		names = append(names, metrics.At(i).Name())
+	}
+	return names
+}
+
+func TestWindowBufferFlushesAtWindowEnd(t *testing.T) {
+	cfg := testConfig(t, "http://localhost")
+	cfg.BatchWindow = 20 * time.Millisecond
+	f := &windowFlushes{ch: make(chan struct{}, 4)}
+	buf := newMetricsWindowBuffer(cfg, zap.NewNop(), f.flush)
+	if err := buf.start(context.Background(), componenttest.NewNopHost()); err != nil {
+		t.Fatal(err)
+	}
+	defer buf.shutdown(context.Background())
+
+	for _, name := range []string{"a", "b"} {
+		if err := buf.push(context.Background(), gaugeMetrics(name)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// Las dos métricas llegan sin shutdown (un tick entre los dos push las separa)
+	for sent := 0; sent < 2; {
+		select {
+		case <-f.ch:
+		case <-time.After(2 * time.Second):
+			t.Fatal("la ventana no se vació")
+		}
+		f.mu.Lock()
+		sent = 0
+		for _, batch := range f.batches {
+			sent += len(batch)
+		}
+		f.mu.Unlock()
+	}
+}
+
+func TestWindowBufferFlushesWhenFull(t *testing.T) {
+	cfg := testConfig(t, "http://localhost")
+	cfg.BatchWindow = time.Hour
+	cfg.BatchWindowMaxBytes = 1
+	f := &windowFlushes{}
+	buf := newMetricsWindowBuffer(cfg, zap.NewNop(), f.flush)
+	for _, name := range []string{"a", "b", "c"} {
+		if err := buf.push(context.Background(), gaugeMetrics(name)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if len(f.batches) != 2 {
+		t.Fatalf("envíos anticipados = %v, se esperaban a y b", f.batches)
+	}
+	if err := buf.shutdown(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if len(f.batches) != 3 || f.batches[2][0] != "c" {
+		t.Errorf("shutdown no envió lo pendiente: %v", f.batches)
+	}
+}
+
+func TestWindowBufferReflushesFailedWindow(t *testing.T) {
+	cfg := testConfig(t, "http://localhost")
+	cfg.BatchWindow = time.Hour
+	f := &windowFlushes{errs: []error{errors.New("503")}}
+	buf := newMetricsWindowBuffer(cfg, zap.NewNop(), f.flush)
+
+	buf.push(context.Background(), gaugeMetrics("a"))
+	if err := buf.flushPending(context.Background()); err == nil {
+		t.Fatal("se esperaba el error del envío")
+	}
+	buf.push(context.Background(), gaugeMetrics("b"))
+	if err := buf.flushPending(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if len(f.batches) != 2 || f.batches[0][0] != "a" || f.batches[1][0] != "b" {
+		t.Errorf("envíos = %v, se esperaba reenviar a y después b", f.batches)
+	}
+}
+
+func TestWindowBufferDropsAfterAttempts(t *testing.T) {
+	cfg := testConfig(t, "http://localhost")
+	cfg.BatchWindow = time.Hour
+	f := &windowFlushes{}
+	for i := 0; i < windowFlushAttempts; i++ {
+		f.errs = append(f.errs, errors.New("503"))
+	}
+	buf := newMetricsWindowBuffer(cfg, zap.NewNop(), f.flush)
+	buf.push(context.Background(), gaugeMetrics("a"))
+	for i := 0; i < windowFlushAttempts+1; i++ {
+		buf.flushPending(context.Background())
+	}
+	if len(f.batches) != 0 || len(f.errs) != 0 {
+		t.Errorf("envíos = %v, errores sin consumir %d", f.batches, len(f.errs))
+	}
+	if len(buf.failed) != 0 {
+		t.Errorf("la ventana sigue pendiente tras %d intentos", windowFlushAttempts)
+	}
+
+	// Un error permanente no se reintenta
+	f.errs = []error{consumererror.NewPermanent(errors.New("400"))}
+	buf.push(context.Background(), gaugeMetrics("b"))
+	buf.flushPending(context.Background())
+	if len(buf.failed) != 0 {
+		t.Error("se conservó una ventana con error permanente")
+	}
+}
+
+func TestValidateBatchWindowMaxBytes(t *testing.T) {
+	cfg := testConfig(t, "http://localhost")
+	cfg.BatchWindow = time.Second
+	cfg.BatchWindowMaxBytes = 0
+	validateError(t, cfg, "batch_window_max_bytes")
+}
+
+func TestAdaptiveBatchSizeGrowsWithLatency(t *testing.T) {
+	size := (&pmetric.ProtoMarshaler{}).MetricsSize(gaugeMetrics("m"))
+	cfg := testConfig(t, "http://localhost")
+	cfg.BatchWindow = time.Hour
+	cfg.AdaptiveBatchTargetLatency = 10 * time.Millisecond
+	cfg.AdaptiveBatchMinBytes = size
+	cfg.AdaptiveBatchMaxBytes = 8 * size
+	cfg.BatchWindowMaxBytes = size // tamaño inicial
+
+	var latency time.Duration
+	buf := newMetricsWindowBuffer(cfg, zap.NewNop(), func(context.Context, pmetric.Metrics) error {
+		time.Sleep(latency) // latencia simulada del backend
+		return nil
+	})
+	var limits []int
+	for _, simulated := range []time.Duration{0, 20 * time.Millisecond, 20 * time.Millisecond, 20 * time.Millisecond, 20 * time.Millisecond, 0} {
+		latency = simulated
+		buf.push(context.Background(), gaugeMetrics("m"))
+		if err := buf.flushPending(context.Background()); err != nil {
+			t.Fatal(err)
+		}
+		limits = append(limits, buf.adaptive.limit()/size)
+	}
+	// Sube al doble mientras el envío supera el objetivo, hasta el máximo, y
+	// baja a la mitad cuando vuelve a ser rápido
+	want := []int{1, 2, 4, 8, 8, 4}
+	for i := range want {
+		if limits[i] != want[i] {
+			t.Fatalf("tamaño máximo (en lotes) = %v, se esperaba %v", limits, want)
+		}
+	}
+
+	// Con el tamaño ampliado caben cuatro lotes sin envío anticipado
+	flushed := 0
+	buf.flushFn = func(context.Context, pmetric.Metrics) error { flushed++; return nil }
+	for i := 0; i < 4; i++ {
+		buf.push(context.Background(), gaugeMetrics("m"))
+	}
+	if flushed != 0 {
+		t.Errorf("envíos anticipados = %d con un tamaño de 4 lotes", flushed)
+	}
+}